@@ -18,6 +18,7 @@ import (
 
 	circlPki "github.com/cloudflare/circl/pki"
 	circlSign "github.com/cloudflare/circl/sign"
+	"github.com/refraction-networking/utls/sm3"
 )
 
 // verifyHandshakeSignature verifies a signature against pre-hashed
@@ -57,6 +58,16 @@ func verifyHandshakeSignature(sigType uint8, pubkey crypto.PublicKey, hashFunc c
 		if err := rsa.VerifyPSS(pubKey, hashFunc, signed, sig, signOpts); err != nil {
 			return err
 		}
+	// [UTLS SECTION BEGINS]
+	case signatureSM2:
+		pubKey, ok := pubkey.(*sm3.SM2PublicKey)
+		if !ok {
+			return fmt.Errorf("expected an SM2 public key, got %T", pubkey)
+		}
+		if ok, err := sm3.VerifySM2(pubKey, sm2TLSUID, signed, sig); err != nil || !ok {
+			return errors.New("SM2 verification failure")
+		}
+	// [UTLS SECTION ENDS]
 	default:
 		// [UTLS SECTION BEGINS]
 		// Ported from cloudflare/go
@@ -121,6 +132,8 @@ func typeAndHashFromSignatureScheme(signatureAlgorithm SignatureScheme) (sigType
 		sigType = signatureECDSA
 	case Ed25519:
 		sigType = signatureEd25519
+	case SM2WithSM3: // [UTLS]
+		sigType = signatureSM2
 	default:
 		// [UTLS SECTION BEGINS]
 		// Ported from cloudflare/go
@@ -144,7 +157,7 @@ func typeAndHashFromSignatureScheme(signatureAlgorithm SignatureScheme) (sigType
 		hash = crypto.SHA384
 	case PKCS1WithSHA512, PSSWithSHA512, ECDSAWithP521AndSHA512:
 		hash = crypto.SHA512
-	case Ed25519:
+	case Ed25519, SM2WithSM3: // [UTLS] SM2 hashes ZA and the message itself
 		hash = directSigning
 	default:
 		// [UTLS SECTION BEGINS]
@@ -258,6 +271,12 @@ func signatureSchemesForCertificate(version uint16, cert *Certificate) []Signatu
 			return nil
 		}
 		sigAlgs = []SignatureScheme{SignatureScheme(tlsScheme.TLSIdentifier())}
+	case *sm3.SM2PublicKey:
+		// RFC 8998 defines sm2sig_sm3 for TLS 1.3 only.
+		if version != VersionTLS13 {
+			return nil
+		}
+		sigAlgs = []SignatureScheme{SM2WithSM3}
 	// [UTLS SECTION ENDS]
 	default:
 		return nil
@@ -330,6 +349,8 @@ func unsupportedCertificateError(cert *Certificate) error {
 	case *rsa.PublicKey:
 		return fmt.Errorf("tls: certificate RSA key size too small for supported signature algorithms")
 	case ed25519.PublicKey:
+	case *sm3.SM2PublicKey: // [UTLS]
+		return fmt.Errorf("tls: SM2 certificates are only supported in TLS 1.3")
 	default:
 		return fmt.Errorf("tls: unsupported certificate key (%T)", pub)
 	}
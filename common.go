@@ -190,6 +190,7 @@ const (
 	signatureECDSA
 	signatureEd25519
 	signatureEdDilithium3
+	signatureSM2 // [UTLS] sm2sig_sm3, see u_sm2.go
 )
 
 // directSigning is a standard Hash value that signals that no pre-hashing
@@ -813,6 +814,14 @@ type Config struct {
 	// signature schemes, see tls_cf.go.
 	PQSignatureSchemesEnabled bool // [UTLS] ported from cloudflare/go
 
+	// SM2RootCAs holds DER-encoded CA certificates trusted for GM (ShangMi)
+	// peer certificates, those whose leaf is signed with SM2-with-SM3.
+	// crypto/x509 cannot parse sm2p256v1 keys, so when SM2RootCAs is not
+	// nil such chains are verified against it instead of RootCAs or
+	// ClientCAs, and the sm2sig_sm3 signature scheme of RFC 8998 is
+	// offered for TLS 1.3. See VerifySM2CertificateChain.
+	SM2RootCAs [][]byte // [UTLS]
+
 	// DynamicRecordSizingDisabled disables adaptive sizing of TLS records.
 	// When true, the largest possible TLS record size is always used. When
 	// false, the size of TLS records may be adjusted in an attempt to
@@ -931,6 +940,7 @@ func (c *Config) Clone() *Config {
 		MaxVersion:                  c.MaxVersion,
 		CurvePreferences:            c.CurvePreferences,
 		PQSignatureSchemesEnabled:   c.PQSignatureSchemesEnabled, // [UTLS]
+		SM2RootCAs:                  c.SM2RootCAs,                // [UTLS]
 		DynamicRecordSizingDisabled: c.DynamicRecordSizingDisabled,
 		Renegotiation:               c.Renegotiation,
 		KeyLogWriter:                c.KeyLogWriter,
@@ -1428,7 +1438,7 @@ func (cri *CertificateRequestInfo) SupportsCertificate(c *Certificate) error {
 		// chain.Leaf was nil.
 		if j != 0 || x509Cert == nil {
 			var err error
-			if x509Cert, err = x509.ParseCertificate(cert); err != nil {
+			if x509Cert, err = parseSM2Certificate(cert); err != nil { // [UTLS] also parses SM2 keys
 				return fmt.Errorf("failed to parse certificate #%d in the chain: %w", j, err)
 			}
 		}
@@ -14,6 +14,7 @@ require (
 	golang.org/x/crypto v0.21.0
 	golang.org/x/net v0.23.0
 	golang.org/x/sys v0.18.0
+	golang.org/x/time v0.5.0
 )
 
 require golang.org/x/text v0.14.0 // indirect
@@ -23,6 +23,7 @@ import (
 	"time"
 
 	circlSign "github.com/cloudflare/circl/sign"
+	"github.com/refraction-networking/utls/sm3"
 )
 
 type clientHandshakeState struct {
@@ -1037,7 +1038,23 @@ func checkKeySize(n int) (max int, ok bool) {
 func (c *Conn) verifyServerCertificate(certificates [][]byte) error {
 	activeHandles := make([]*activeCert, len(certificates))
 	certs := make([]*x509.Certificate, len(certificates))
+	// [UTLS SECTION START]
+	// GM chains bypass the certificate cache, as crypto/x509 cannot parse
+	// their keys. See Config.SM2RootCAs.
+	sm2Chain := c.config.SM2RootCAs != nil && len(certificates) > 0 && isSM2Certificate(certificates[0])
+	// [UTLS SECTION END]
 	for i, asn1Data := range certificates {
+		// [UTLS SECTION START]
+		if sm2Chain {
+			cert, err := parseSM2Certificate(asn1Data)
+			if err != nil {
+				c.sendAlert(alertBadCertificate)
+				return errors.New("tls: failed to parse certificate from server: " + err.Error())
+			}
+			certs[i] = cert
+			continue
+		}
+		// [UTLS SECTION END]
 		cert, err := globalCertCache.newCert(asn1Data)
 		if err != nil {
 			c.sendAlert(alertBadCertificate)
@@ -1077,7 +1094,11 @@ func (c *Conn) verifyServerCertificate(certificates [][]byte) error {
 			opts.Intermediates.AddCert(cert)
 		}
 		var err error
-		c.verifiedChains, err = certs[0].Verify(opts)
+		if sm2Chain { // [UTLS]
+			c.verifiedChains, err = verifySM2Chain(certs, c.config.SM2RootCAs, opts.CurrentTime, opts.DNSName, x509.ExtKeyUsageServerAuth)
+		} else {
+			c.verifiedChains, err = certs[0].Verify(opts)
+		}
 		if err != nil {
 			c.sendAlert(alertBadCertificate)
 			return &CertificateVerificationError{UnverifiedCertificates: certs, Err: err}
@@ -1085,7 +1106,7 @@ func (c *Conn) verifyServerCertificate(certificates [][]byte) error {
 	}
 
 	switch certs[0].PublicKey.(type) {
-	case *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey, circlSign.PublicKey: // [UTLS] ported from cloudflare/go
+	case *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey, circlSign.PublicKey, *sm3.SM2PublicKey: // [UTLS] ported from cloudflare/go, SM2
 		break
 	default:
 		c.sendAlert(alertUnsupportedCertificate)
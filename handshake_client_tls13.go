@@ -927,6 +927,8 @@ func (hs *clientHandshakeStateTLS13) sendClientCertificate() error {
 	signOpts := crypto.SignerOpts(sigHash)
 	if sigType == signatureRSAPSS {
 		signOpts = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: sigHash}
+	} else if sigType == signatureSM2 { // [UTLS]
+		signOpts = sm2TLSSignerOpts
 	}
 	sig, err := cert.PrivateKey.(crypto.Signer).Sign(c.config.rand(), signed, signOpts)
 	if err != nil {
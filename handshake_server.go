@@ -19,6 +19,7 @@ import (
 	"time"
 
 	circlSign "github.com/cloudflare/circl/sign"
+	"github.com/refraction-networking/utls/sm3"
 )
 
 // serverHandshakeState contains details of a server handshake in progress.
@@ -879,7 +880,17 @@ func (c *Conn) processCertsFromClient(certificate Certificate) error {
 	certificates := certificate.Certificate
 	certs := make([]*x509.Certificate, len(certificates))
 	var err error
+	sm2Chain := c.config.SM2RootCAs != nil && len(certificates) > 0 && isSM2Certificate(certificates[0]) // [UTLS] see Config.SM2RootCAs
 	for i, asn1Data := range certificates {
+		// [UTLS SECTION START]
+		if sm2Chain {
+			if certs[i], err = parseSM2Certificate(asn1Data); err != nil {
+				c.sendAlert(alertBadCertificate)
+				return errors.New("tls: failed to parse client certificate: " + err.Error())
+			}
+			continue
+		}
+		// [UTLS SECTION END]
 		if certs[i], err = x509.ParseCertificate(asn1Data); err != nil {
 			c.sendAlert(alertBadCertificate)
 			return errors.New("tls: failed to parse client certificate: " + err.Error())
@@ -914,7 +925,12 @@ func (c *Conn) processCertsFromClient(certificate Certificate) error {
 			opts.Intermediates.AddCert(cert)
 		}
 
-		chains, err := certs[0].Verify(opts)
+		var chains [][]*x509.Certificate
+		if sm2Chain { // [UTLS]
+			chains, err = verifySM2Chain(certs, c.config.SM2RootCAs, opts.CurrentTime, "", x509.ExtKeyUsageClientAuth)
+		} else {
+			chains, err = certs[0].Verify(opts)
+		}
 		if err != nil {
 			var errCertificateInvalid x509.CertificateInvalidError
 			if errors.As(err, &x509.UnknownAuthorityError{}) {
@@ -936,7 +952,7 @@ func (c *Conn) processCertsFromClient(certificate Certificate) error {
 
 	if len(certs) > 0 {
 		switch certs[0].PublicKey.(type) {
-		case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey, circlSign.PublicKey: // [UTLS] ported from cloudflare/go
+		case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey, circlSign.PublicKey, *sm3.SM2PublicKey: // [UTLS] ported from cloudflare/go, SM2
 		default:
 			c.sendAlert(alertUnsupportedCertificate)
 			return fmt.Errorf("tls: client certificate contains an unsupported public key of type %T", certs[0].PublicKey)
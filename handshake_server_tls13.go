@@ -726,6 +726,8 @@ func (hs *serverHandshakeStateTLS13) sendServerCertificate() error {
 	signOpts := crypto.SignerOpts(sigHash)
 	if sigType == signatureRSAPSS {
 		signOpts = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: sigHash}
+	} else if sigType == signatureSM2 { // [UTLS]
+		signOpts = sm2TLSSignerOpts
 	}
 	sig, err := hs.cert.PrivateKey.(crypto.Signer).Sign(c.config.rand(), signed, signOpts)
 	if err != nil {
@@ -0,0 +1,27 @@
+package sm3
+
+import (
+	"encoding/base64"
+	"fmt"
+)
+
+// SumBase64URL returns the SM3 checksum of data as 43 characters of
+// unpadded base64url, a compact form for tokens, URLs and file names.
+func SumBase64URL(data []byte) string {
+	sum := Sum(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// ParseBase64URL decodes a digest produced by SumBase64URL. Padded input,
+// the standard base64 alphabet and non-canonical trailing bits are
+// rejected.
+func ParseBase64URL(s string) ([Size]byte, error) {
+	var sum [Size]byte
+	if len(s) != base64.RawURLEncoding.EncodedLen(Size) {
+		return sum, fmt.Errorf("%w: base64url digest of %d characters", ErrBadLength, len(s))
+	}
+	if _, err := base64.RawURLEncoding.Strict().Decode(sum[:], []byte(s)); err != nil {
+		return [Size]byte{}, fmt.Errorf("sm3: decoding base64url digest: %w", err)
+	}
+	return sum, nil
+}
@@ -0,0 +1,42 @@
+package sm3
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSumBase64URL(t *testing.T) {
+	for _, g := range golden {
+		s := SumBase64URL([]byte(g.in))
+		if len(s) != 43 {
+			t.Errorf("SumBase64URL(%q) has %d characters, want 43", g.in, len(s))
+		}
+		if strings.ContainsAny(s, "+/=") {
+			t.Errorf("SumBase64URL(%q) = %q is not unpadded base64url", g.in, s)
+		}
+		sum, err := ParseBase64URL(s)
+		if err != nil {
+			t.Fatalf("ParseBase64URL(%q): %v", s, err)
+		}
+		if want := Sum([]byte(g.in)); sum != want {
+			t.Errorf("ParseBase64URL(SumBase64URL(%q)) = %x, want %x", g.in, sum, want)
+		}
+	}
+	// The encoding of the "abc" vector.
+	if got, want := SumBase64URL([]byte("abc")), "Zsfw9GLu7dnR8tRr3BDk4kFnxIdc8veiKX2gK49LqOA"; got != want {
+		t.Errorf("SumBase64URL(abc) = %s, want %s", got, want)
+	}
+}
+
+func TestParseBase64URLRejects(t *testing.T) {
+	s := SumBase64URL([]byte("abc"))
+	for _, bad := range []string{"", s[:42], s + "=", s + "A", "+" + s[1:], s[:42] + "B"} {
+		if _, err := ParseBase64URL(bad); err == nil {
+			t.Errorf("ParseBase64URL(%q) succeeded", bad)
+		}
+	}
+	if _, err := ParseBase64URL(s[:40]); !errors.Is(err, ErrBadLength) {
+		t.Errorf("short input: err = %v, want %v", err, ErrBadLength)
+	}
+}
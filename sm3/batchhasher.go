@@ -0,0 +1,30 @@
+package sm3
+
+// A BatchHasher hashes many messages that share a prefix: it absorbs the
+// prefix once, and each Next restores that saved state instead of hashing
+// the prefix again. The saved state includes any partial block, so the
+// prefix need not be a multiple of BlockSize, though only its whole blocks
+// are saved work.
+//
+// Next(msg) is Sum(prefix || msg). When the prefix is secret this is the
+// construction of InsecurePrefixMAC, and the digests must not be used as
+// MACs; use NewHMACReusable for that. A BatchHasher is not safe for
+// concurrent use, but copies of it are independent.
+type BatchHasher struct {
+	prefix digest
+}
+
+// NewBatchHasher returns a BatchHasher for messages following prefix.
+func NewBatchHasher(prefix []byte) *BatchHasher {
+	b := new(BatchHasher)
+	b.prefix.Reset()
+	b.prefix.Write(prefix)
+	return b
+}
+
+// Next returns Sum(prefix || msg).
+func (b *BatchHasher) Next(msg []byte) [Size]byte {
+	d := b.prefix
+	d.Write(msg)
+	return d.checkSum()
+}
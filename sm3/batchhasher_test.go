@@ -0,0 +1,36 @@
+package sm3
+
+import "testing"
+
+func TestBatchHasher(t *testing.T) {
+	data := treeTestData(3 * BlockSize)
+	for _, n := range []int{0, 1, BlockSize - 1, BlockSize, BlockSize + 7, 2 * BlockSize} {
+		prefix := data[:n]
+		b := NewBatchHasher(prefix)
+		for i := 0; i < 200; i++ {
+			msg := data[n : n+i%(len(data)-n+1)]
+			if got, want := b.Next(msg), Sum(append(prefix[:n:n], msg...)); got != want {
+				t.Fatalf("%d-byte prefix, message %d (%d bytes): %x, want %x", n, i, len(msg), got, want)
+			}
+		}
+	}
+}
+
+func BenchmarkBatchHasher(b *testing.B) {
+	prefix := buf[:4*BlockSize]
+	msg := buf[:100]
+	b.Run("Next", func(b *testing.B) {
+		h := NewBatchHasher(prefix)
+		b.SetBytes(int64(len(msg)))
+		for i := 0; i < b.N; i++ {
+			h.Next(msg)
+		}
+	})
+	b.Run("Sum", func(b *testing.B) {
+		joined := append(prefix[:len(prefix):len(prefix)], msg...)
+		b.SetBytes(int64(len(msg)))
+		for i := 0; i < b.N; i++ {
+			Sum(joined)
+		}
+	})
+}
@@ -0,0 +1,161 @@
+package sm3
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+// DeriveIndices returns k indices in [0, m) for data, all from one Sum:
+// with a and b the first and second 64 bits of the digest, big-endian,
+// index i is (a + i*b) mod m. Kirsch and Mitzenmacher showed that this
+// double hashing gives a Bloom filter the false-positive rate of k
+// independent hashes. b is forced odd so the indices do not collapse when
+// m is a power of two.
+//
+// DeriveIndices panics if k < 1 or m == 0.
+func DeriveIndices(data []byte, k int, m uint64) []uint64 {
+	if k < 1 || m == 0 {
+		panic(fmt.Sprintf("sm3: DeriveIndices called with k=%d, m=%d", k, m))
+	}
+	return appendIndices(make([]uint64, 0, k), Sum(data), k, m)
+}
+
+func appendIndices(dst []uint64, sum [Size]byte, k int, m uint64) []uint64 {
+	a := binary.BigEndian.Uint64(sum[:8]) % m
+	b := (binary.BigEndian.Uint64(sum[8:16]) | 1) % m
+	for i := 0; i < k; i++ {
+		dst = append(dst, a)
+		// a+b may wrap around 2^64; the reduction handles that.
+		s, carry := bits.Add64(a, b, 0)
+		if carry != 0 || s >= m {
+			s -= m
+		}
+		a = s
+	}
+	return dst
+}
+
+// A BloomFilter is a set that can return false positives but never false
+// negatives: Test of data that was added always reports true, and Test of
+// data that was not added reports true with a probability set by the size
+// of the filter, its number of hashes and how full it is. The k bit
+// positions for each item come from DeriveIndices, so one SM3 digest per
+// item is computed.
+//
+// A BloomFilter is not safe for concurrent use.
+type BloomFilter struct {
+	m    uint64
+	k    int
+	bits []uint64
+}
+
+// NewBloomFilter returns an empty BloomFilter of m bits setting k bits
+// per item.
+//
+// NewBloomFilter panics if m == 0, m is too large for its bits to be
+// addressed in memory, or k < 1.
+func NewBloomFilter(m uint64, k int) *BloomFilter {
+	if m == 0 || bloomWords(m) > math.MaxInt/8 || k < 1 {
+		panic(fmt.Sprintf("sm3: NewBloomFilter called with m=%d, k=%d", m, k))
+	}
+	return &BloomFilter{m: m, k: k, bits: make([]uint64, bloomWords(m))}
+}
+
+// bloomWords returns the number of uint64 words holding m bits, ceil(m/64)
+// computed without overflowing for m near math.MaxUint64.
+func bloomWords(m uint64) uint64 {
+	return m/64 + (m%64+63)/64
+}
+
+// NewBloom returns an empty BloomFilter sized to hold expectedItems items
+// with a false-positive rate of about falsePositiveRate: m is
+// ceil(-n ln p / (ln 2)^2) bits and k is round(m/n ln 2) hashes, at least
+// one. Adding more items than expected raises the rate.
+//
+// NewBloom panics if expectedItems < 1 or falsePositiveRate is not
+// strictly between 0 and 1.
+func NewBloom(expectedItems int, falsePositiveRate float64) *BloomFilter {
+	if expectedItems < 1 || !(falsePositiveRate > 0 && falsePositiveRate < 1) {
+		panic(fmt.Sprintf("sm3: NewBloom called with %d items, rate %v", expectedItems, falsePositiveRate))
+	}
+	n := float64(expectedItems)
+	m := math.Ceil(-n * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2))
+	k := int(math.Round(m / n * math.Ln2))
+	return NewBloomFilter(uint64(m), max(k, 1))
+}
+
+// M returns the number of bits in the filter.
+func (f *BloomFilter) M() uint64 { return f.m }
+
+// K returns the number of bits set per item.
+func (f *BloomFilter) K() int { return f.k }
+
+// Add records data in the filter.
+func (f *BloomFilter) Add(data []byte) {
+	var idx [16]uint64
+	for _, i := range appendIndices(idx[:0], Sum(data), f.k, f.m) {
+		f.bits[i/64] |= 1 << (i % 64)
+	}
+}
+
+// Test reports whether data may have been added to the filter. A false
+// result is certain; a true one may be a false positive.
+func (f *BloomFilter) Test(data []byte) bool {
+	var idx [16]uint64
+	for _, i := range appendIndices(idx[:0], Sum(data), f.k, f.m) {
+		if f.bits[i/64]&(1<<(i%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// A serialized BloomFilter is a magic string, m as a uint64, k as a
+// uint32, and the bit array as big-endian uint64 words, lowest bits first.
+const bloomMagic = "sm3b\x01"
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (f *BloomFilter) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, len(bloomMagic)+8+4+8*len(f.bits))
+	b = append(b, bloomMagic...)
+	b = binary.BigEndian.AppendUint64(b, f.m)
+	b = binary.BigEndian.AppendUint32(b, uint32(f.k))
+	for _, w := range f.bits {
+		b = binary.BigEndian.AppendUint64(b, w)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. A state that is
+// not exactly what MarshalBinary produces, having the wrong magic or
+// length, a zero m or k, or bits set past m, is rejected with an error
+// wrapping ErrInvalidState and leaves f unchanged.
+func (f *BloomFilter) UnmarshalBinary(b []byte) error {
+	const header = len(bloomMagic) + 8 + 4
+	if len(b) < len(bloomMagic) || string(b[:len(bloomMagic)]) != bloomMagic {
+		return fmt.Errorf("%w: not an SM3 Bloom filter", ErrInvalidState)
+	}
+	if len(b) < header {
+		return fmt.Errorf("%w: %d-byte Bloom filter header, want %d", ErrInvalidState, len(b), header)
+	}
+	m := binary.BigEndian.Uint64(b[len(bloomMagic):])
+	k := binary.BigEndian.Uint32(b[len(bloomMagic)+8:])
+	if m == 0 || k == 0 || k > math.MaxInt32 {
+		return fmt.Errorf("%w: Bloom filter with m=%d, k=%d", ErrInvalidState, m, k)
+	}
+	words := bloomWords(m)
+	if uint64(len(b)-header)/8 != words || (len(b)-header)%8 != 0 {
+		return fmt.Errorf("%w: %d bytes of bits, want %d for m=%d", ErrInvalidState, len(b)-header, words*8, m)
+	}
+	next := make([]uint64, words)
+	for i := range next {
+		next[i] = binary.BigEndian.Uint64(b[header+8*i:])
+	}
+	if r := m % 64; r != 0 && next[words-1]>>r != 0 {
+		return fmt.Errorf("%w: Bloom filter bits set past m=%d", ErrInvalidState, m)
+	}
+	f.m, f.k, f.bits = m, int(k), next
+	return nil
+}
@@ -0,0 +1,132 @@
+package sm3
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestDeriveIndices(t *testing.T) {
+	sum := Sum([]byte("abc"))
+	a := new(big.Int).SetBytes(sum[:8])
+	b := new(big.Int).SetBytes(sum[8:16])
+	b.SetBit(b, 0, 1)
+	for _, m := range []uint64{1, 2, 64, 1000, 1 << 40, math.MaxUint64} {
+		got := DeriveIndices([]byte("abc"), 7, m)
+		if len(got) != 7 {
+			t.Fatalf("m=%d: %d indices, want 7", m, len(got))
+		}
+		bm := new(big.Int).SetUint64(m)
+		for i, idx := range got {
+			want := new(big.Int).Mul(b, big.NewInt(int64(i)))
+			want.Add(want, a).Mod(want, bm)
+			if idx != want.Uint64() {
+				t.Errorf("m=%d: index %d = %d, want %d", m, i, idx, want)
+			}
+		}
+	}
+}
+
+func TestBloomNoFalseNegatives(t *testing.T) {
+	f := NewBloom(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		f.Add([]byte(fmt.Sprint("item-", i)))
+	}
+	for i := 0; i < 1000; i++ {
+		if !f.Test([]byte(fmt.Sprint("item-", i))) {
+			t.Fatalf("item-%d added but Test reports false", i)
+		}
+	}
+}
+
+func TestBloomFalsePositiveRate(t *testing.T) {
+	const n, p, trials = 2000, 0.01, 50000
+	f := NewBloom(n, p)
+	if f.M() != 19171 || f.K() != 7 {
+		t.Errorf("NewBloom(%d, %v): m=%d, k=%d, want 19171, 7", n, p, f.M(), f.K())
+	}
+	for i := 0; i < n; i++ {
+		f.Add([]byte(fmt.Sprint("in-", i)))
+	}
+	fp := 0
+	for i := 0; i < trials; i++ {
+		if f.Test([]byte(fmt.Sprint("out-", i))) {
+			fp++
+		}
+	}
+	// The expected count is 500 with a standard deviation of about 22.
+	if rate := float64(fp) / trials; rate < p/2 || rate > 2*p {
+		t.Errorf("false-positive rate %.4f over %d trials, want about %v", rate, trials, p)
+	}
+}
+
+func TestBloomMarshal(t *testing.T) {
+	f := NewBloomFilter(1001, 5)
+	for i := 0; i < 100; i++ {
+		f.Add([]byte(fmt.Sprint(i)))
+	}
+	b, err := f.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var g BloomFilter
+	if err := g.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	if g.M() != f.M() || g.K() != f.K() {
+		t.Fatalf("round trip: m=%d, k=%d, want %d, %d", g.M(), g.K(), f.M(), f.K())
+	}
+	for i := 0; i < 1000; i++ {
+		if d := []byte(fmt.Sprint(i)); g.Test(d) != f.Test(d) {
+			t.Fatalf("round trip: Test(%q) differs", d)
+		}
+	}
+	if b2, _ := g.MarshalBinary(); string(b2) != string(b) {
+		t.Errorf("re-marshaled filter differs")
+	}
+
+	pastM := append([]byte(nil), b...)
+	pastM[len(pastM)-8] |= 0x80 // bit 1023, past m=1001
+	for name, bad := range map[string][]byte{
+		"empty":     nil,
+		"magic":     append([]byte("sm3\x01"), b[4:]...),
+		"truncated": b[:len(b)-1],
+		"long":      append(append([]byte(nil), b...), 0),
+		"header":    b[:len(bloomMagic)+3],
+		"zero k":    append(append([]byte(nil), b[:len(bloomMagic)+8]...), append([]byte{0, 0, 0, 0}, b[len(bloomMagic)+12:]...)...),
+		"past m":    pastM,
+		"huge m":    append([]byte(bloomMagic), 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0, 0, 0, 1),
+	} {
+		g := *f
+		if err := g.UnmarshalBinary(bad); !errors.Is(err, ErrInvalidState) {
+			t.Errorf("%s: got %v, want ErrInvalidState", name, err)
+		}
+		if g.m != f.m || g.k != f.k || &g.bits[0] != &f.bits[0] {
+			t.Errorf("%s: filter changed on error", name)
+		}
+	}
+}
+
+func TestBloomPanics(t *testing.T) {
+	for name, fn := range map[string]func(){
+		"NewBloom items":        func() { NewBloom(0, 0.01) },
+		"NewBloom rate 0":       func() { NewBloom(10, 0) },
+		"NewBloom rate 1":       func() { NewBloom(10, 1) },
+		"NewBloom NaN":          func() { NewBloom(10, math.NaN()) },
+		"NewBloomFilter m":      func() { NewBloomFilter(0, 1) },
+		"NewBloomFilter k":      func() { NewBloomFilter(8, 0) },
+		"NewBloomFilter huge m": func() { NewBloomFilter(math.MaxUint64, 1) },
+		"DeriveIndices":         func() { DeriveIndices(nil, 0, 8) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: no panic", name)
+				}
+			}()
+			fn()
+		}()
+	}
+}
@@ -0,0 +1,67 @@
+package sm3
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Bucket assigns key to one of buckets buckets, for stable A/B and
+// feature-flag bucketing: it returns the top 64 bits of SumString(key),
+// big-endian, modulo buckets. The same key always lands in the same bucket,
+// and keys spread evenly; the modulo bias is below buckets/2^64.
+//
+// Bucket panics if buckets < 1.
+func Bucket(key string, buckets int) int {
+	if buckets < 1 {
+		panic(fmt.Sprintf("sm3: Bucket called with %d buckets", buckets))
+	}
+	return int(top64(SumString(key)) % uint64(buckets))
+}
+
+// ShardKey is Bucket for binary ids: it returns the shard in [0, shards)
+// that id belongs to, the top 64 bits of Sum(id) modulo shards. Partitions
+// stay stable only while shards is unchanged.
+//
+// ShardKey panics if shards < 1.
+func ShardKey(id []byte, shards int) int {
+	if shards < 1 {
+		panic(fmt.Sprintf("sm3: ShardKey called with %d shards", shards))
+	}
+	return int(top64(Sum(id)) % uint64(shards))
+}
+
+// ShardKeyWeighted is ShardKey for shards of uneven capacity: shard i
+// receives a weights[i]/sum(weights) share of ids. The top 64 bits of
+// Sum(id) modulo the total weight pick a point that falls in one shard's
+// range of the cumulative weights. A shard of weight zero receives no ids.
+//
+// ShardKeyWeighted panics if weights is empty, has a negative entry, or
+// sums to zero or to more than math.MaxUint64.
+func ShardKeyWeighted(id []byte, weights []int) int {
+	var total uint64
+	for i, w := range weights {
+		if w < 0 {
+			panic(fmt.Sprintf("sm3: ShardKeyWeighted called with weight %d for shard %d", w, i))
+		}
+		if total+uint64(w) < total {
+			panic(fmt.Sprintf("sm3: ShardKeyWeighted called with weights summing past 2^64 at shard %d", i))
+		}
+		total += uint64(w)
+	}
+	if total == 0 {
+		panic("sm3: ShardKeyWeighted called with no positive weight")
+	}
+	point := top64(Sum(id)) % total
+	for i, w := range weights {
+		if point < uint64(w) {
+			return i
+		}
+		point -= uint64(w)
+	}
+	panic("unreachable")
+}
+
+// top64 returns the first 8 bytes of sum as a big-endian integer.
+func top64(sum [Size]byte) uint64 {
+	return binary.BigEndian.Uint64(sum[:8])
+}
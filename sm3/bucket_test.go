@@ -0,0 +1,143 @@
+package sm3
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestBucket(t *testing.T) {
+	sum := SumString("user-42")
+	if got, want := Bucket("user-42", 1000), int(binary.BigEndian.Uint64(sum[:8])%1000); got != want {
+		t.Errorf("Bucket = %d, want %d", got, want)
+	}
+	for i := 0; i < 10; i++ {
+		if Bucket("user-42", 7) != Bucket("user-42", 7) {
+			t.Fatal("Bucket is not deterministic")
+		}
+	}
+	if Bucket("anything", 1) != 0 {
+		t.Error("Bucket with one bucket is not 0")
+	}
+	for _, n := range []int{0, -1} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("no panic for %d buckets", n)
+				}
+			}()
+			Bucket("key", n)
+		}()
+	}
+}
+
+func TestBucketDistribution(t *testing.T) {
+	const buckets, keys = 10, 100000
+	var counts [buckets]int
+	for i := 0; i < keys; i++ {
+		counts[Bucket(fmt.Sprintf("key-%d", i), buckets)]++
+	}
+	// Chi-squared with 9 degrees of freedom; 27.88 is the 0.999 quantile.
+	expected := float64(keys) / buckets
+	var chi2 float64
+	for _, c := range counts {
+		d := float64(c) - expected
+		chi2 += d * d / expected
+	}
+	if chi2 > 27.88 {
+		t.Errorf("bucket counts %v are uneven: chi-squared %.2f", counts, chi2)
+	}
+}
+
+func TestShardKey(t *testing.T) {
+	id := []byte("customer/1234")
+	if got, want := ShardKey(id, 16), Bucket(string(id), 16); got != want {
+		t.Errorf("ShardKey = %d, want Bucket's %d", got, want)
+	}
+	if ShardKey(id, 16) != ShardKey(id, 16) {
+		t.Fatal("ShardKey is not deterministic")
+	}
+
+	const shards, ids = 8, 80000
+	var counts [shards]int
+	for i := 0; i < ids; i++ {
+		counts[ShardKey([]byte(fmt.Sprintf("id-%d", i)), shards)]++
+	}
+	// Chi-squared with 7 degrees of freedom; 24.32 is the 0.999 quantile.
+	expected := float64(ids) / shards
+	var chi2 float64
+	for _, c := range counts {
+		d := float64(c) - expected
+		chi2 += d * d / expected
+	}
+	if chi2 > 24.32 {
+		t.Errorf("shard counts %v are uneven: chi-squared %.2f", counts, chi2)
+	}
+}
+
+func TestShardKeyWeighted(t *testing.T) {
+	weights := []int{1, 0, 3, 6}
+	const ids = 100000
+	counts := make([]int, len(weights))
+	for i := 0; i < ids; i++ {
+		id := []byte(fmt.Sprintf("id-%d", i))
+		s := ShardKeyWeighted(id, weights)
+		if s != ShardKeyWeighted(id, weights) {
+			t.Fatal("ShardKeyWeighted is not deterministic")
+		}
+		counts[s]++
+	}
+	if counts[1] != 0 {
+		t.Errorf("zero-weight shard received %d ids", counts[1])
+	}
+	// Chi-squared over the three weighted shards, 2 degrees of freedom;
+	// 13.82 is the 0.999 quantile.
+	var chi2 float64
+	for i, w := range weights {
+		if w == 0 {
+			continue
+		}
+		expected := float64(ids) * float64(w) / 10
+		d := float64(counts[i]) - expected
+		chi2 += d * d / expected
+	}
+	if chi2 > 13.82 {
+		t.Errorf("weighted shard counts %v do not follow weights %v: chi-squared %.2f", counts, weights, chi2)
+	}
+
+	if got := ShardKeyWeighted([]byte("x"), []int{0, 5}); got != 1 {
+		t.Errorf("single positive weight: shard %d, want 1", got)
+	}
+
+	bads := [][]int{nil, {0, 0}, {2, -1}}
+	// Weights are ints, so two of them cannot pass math.MaxUint64; with
+	// 64-bit ints the sum overflows from the third weight near math.MaxInt
+	// on. With 32-bit ints a handful of weights cannot overflow it.
+	if strconv.IntSize == 64 {
+		if got := ShardKeyWeighted([]byte("x"), []int{math.MaxInt, math.MaxInt, 1}); got < 0 || got > 2 {
+			t.Errorf("weights summing to math.MaxUint64: shard %d", got)
+		}
+		bads = append(bads, []int{math.MaxInt, math.MaxInt, 2}, []int{math.MaxInt, math.MaxInt, math.MaxInt})
+	}
+
+	for _, bad := range bads {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("no panic for weights %v", bad)
+				}
+			}()
+			ShardKeyWeighted([]byte("x"), bad)
+		}()
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("no panic for 0 shards")
+			}
+		}()
+		ShardKey([]byte("x"), 0)
+	}()
+}
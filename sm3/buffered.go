@@ -0,0 +1,22 @@
+package sm3
+
+import (
+	"bufio"
+	"hash"
+)
+
+// Buffered wraps h in a bufio.Writer of BlockSize bytes, for callers that
+// write a byte or two at a time, such as field-by-field serializers. Each
+// Write to h has a fixed cost on top of the copy, which the buffer turns
+// into one call per block.
+//
+// The buffer must be flushed before reading the checksum:
+//
+//	h := sm3.New()
+//	w := sm3.Buffered(h)
+//	// ... many small writes to w ...
+//	w.Flush()
+//	sum := h.Sum(nil)
+func Buffered(h hash.Hash) *bufio.Writer {
+	return bufio.NewWriterSize(h, BlockSize)
+}
@@ -0,0 +1,53 @@
+package sm3
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBuffered(t *testing.T) {
+	msg := treeTestData(3*BlockSize + 5)
+	h := New()
+	w := Buffered(h)
+	for i, c := range msg {
+		if i%7 == 0 {
+			w.Write(msg[i : i+1])
+		} else {
+			w.WriteByte(c)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	want := Sum(msg)
+	if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+		t.Errorf("buffered Sum = %x, want %x", got, want)
+	}
+}
+
+func BenchmarkSingleByteWrites(b *testing.B) {
+	const n = 1 << 20
+	one := []byte{0x5a}
+	b.Run("Raw", func(b *testing.B) {
+		b.SetBytes(n)
+		for i := 0; i < b.N; i++ {
+			h := New()
+			for j := 0; j < n; j++ {
+				h.Write(one)
+			}
+			h.Sum(nil)
+		}
+	})
+	b.Run("Buffered", func(b *testing.B) {
+		b.SetBytes(n)
+		for i := 0; i < b.N; i++ {
+			h := New()
+			w := Buffered(h)
+			for j := 0; j < n; j++ {
+				w.WriteByte(one[0])
+			}
+			w.Flush()
+			h.Sum(nil)
+		}
+	})
+}
@@ -0,0 +1,38 @@
+package sm3
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+// CacheKey returns a stable key for memoizing a pure function called with
+// parts, as 64 hex digits. The parts are hashed as a record with
+// RecordHasher, so argument boundaries and types are part of the key:
+// ("ab", "") and ("a", "b") differ, as do the string "1" and the int 1.
+//
+// The supported types are string, []byte, bool, int, int64 and uint64;
+// int and int64 encode alike. Any other type, including nil, yields an
+// error wrapping ErrUnsupportedType rather than a key that might collide.
+func CacheKey(parts ...any) (string, error) {
+	r := NewRecordHasher()
+	for i, p := range parts {
+		switch v := p.(type) {
+		case string:
+			r.AddString(v)
+		case []byte:
+			r.AddBytes(v)
+		case bool:
+			r.AddBool(v)
+		case int:
+			r.AddInt64(int64(v))
+		case int64:
+			r.AddInt64(v)
+		case uint64:
+			r.AddUint64(v)
+		default:
+			return "", fmt.Errorf("%w: argument %d is %T", ErrUnsupportedType, i, p)
+		}
+	}
+	sum := r.Sum()
+	return hex.EncodeToString(sum[:]), nil
+}
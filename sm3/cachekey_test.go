@@ -0,0 +1,62 @@
+package sm3
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCacheKey(t *testing.T) {
+	tuples := [][]any{
+		{},
+		{"a"},
+		{"a", ""},
+		{"ab", ""},
+		{"a", "b"},
+		{"1"},
+		{1},
+		{uint64(1)},
+		{-1},
+		{[]byte("1")},
+		{true},
+		{false},
+		{"user", 42, true},
+		{"user", 42, false},
+		{42, "user", true},
+	}
+	seen := make(map[string]int)
+	for i, parts := range tuples {
+		key, err := CacheKey(parts...)
+		if err != nil {
+			t.Fatalf("CacheKey(%v): %v", parts, err)
+		}
+		if len(key) != 2*Size {
+			t.Errorf("CacheKey(%v) = %q, want %d hex digits", parts, key, 2*Size)
+		}
+		if again, _ := CacheKey(parts...); again != key {
+			t.Errorf("CacheKey(%v) is not stable", parts)
+		}
+		if j, ok := seen[key]; ok {
+			t.Errorf("CacheKey(%v) collides with CacheKey(%v)", parts, tuples[j])
+		}
+		seen[key] = i
+	}
+
+	if a, _ := CacheKey(7); a != mustCacheKey(t, int64(7)) {
+		t.Error("int and int64 of the same value give different keys")
+	}
+
+	for _, bad := range []any{nil, 1.5, int32(1), struct{}{}, []string{"a"}} {
+		if _, err := CacheKey("ok", bad); !errors.Is(err, ErrUnsupportedType) {
+			t.Errorf("CacheKey(%T): err = %v, want ErrUnsupportedType", bad, err)
+		}
+	}
+}
+
+func mustCacheKey(t *testing.T, parts ...any) string {
+	t.Helper()
+	key, err := CacheKey(parts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
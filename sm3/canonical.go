@@ -0,0 +1,52 @@
+package sm3
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+)
+
+// SumGob returns the SM3 checksum of the gob encoding of v, written by a
+// fresh gob.Encoder so that the type description is included every time.
+//
+// The digest identifies the value together with its Go type: renaming,
+// adding or reordering struct fields changes it. gob writes maps in
+// iteration order, so values containing maps do not hash deterministically;
+// use SumJSONCanonical for those.
+func SumGob(v any) ([Size]byte, error) {
+	d := NewReusable()
+	if err := gob.NewEncoder(d).Encode(v); err != nil {
+		return [Size]byte{}, fmt.Errorf("sm3: gob encoding: %w", err)
+	}
+	var sum [Size]byte
+	d.Sum(sum[:0])
+	return sum, nil
+}
+
+// SumJSONCanonical returns the SM3 checksum of a canonical JSON encoding of
+// v: the output of encoding/json re-encoded with the keys of every object,
+// including those from structs, in sorted order, no insignificant
+// whitespace and no HTML escaping. Numbers keep the text json.Marshal wrote
+// for them. Values that are equal as JSON objects, whatever their key order
+// or Go type, get the same digest.
+func SumJSONCanonical(v any) ([Size]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return [Size]byte{}, fmt.Errorf("sm3: JSON encoding: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var generic any
+	if err := dec.Decode(&generic); err != nil {
+		return [Size]byte{}, fmt.Errorf("sm3: JSON encoding: %w", err)
+	}
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(generic); err != nil {
+		return [Size]byte{}, fmt.Errorf("sm3: JSON encoding: %w", err)
+	}
+	// Encode ends its output with a newline; leave it out of the digest.
+	return Sum(bytes.TrimSuffix(out.Bytes(), []byte("\n"))), nil
+}
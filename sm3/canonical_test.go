@@ -0,0 +1,72 @@
+package sm3
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+type canonicalRecord struct {
+	Name  string
+	Tags  []string
+	Count int
+}
+
+func TestSumGob(t *testing.T) {
+	a := canonicalRecord{Name: "a", Tags: []string{"x", "y"}, Count: 3}
+	b := canonicalRecord{Name: "a", Tags: []string{"x", "y"}, Count: 3}
+	da, err := SumGob(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := SumGob(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if da != db {
+		t.Error("equal values have different gob digests")
+	}
+	b.Count = 4
+	if dc, _ := SumGob(b); dc == da {
+		t.Error("different values have the same gob digest")
+	}
+	if _, err := SumGob(func() {}); err == nil {
+		t.Error("SumGob accepted a func")
+	}
+}
+
+func TestSumJSONCanonical(t *testing.T) {
+	m1 := map[string]any{"b": 1, "a": []any{"x", map[string]any{"z": true, "y": nil}}, "c": "<&>"}
+	m2 := map[string]any{"c": "<&>", "a": []any{"x", map[string]any{"y": nil, "z": true}}, "b": 1}
+	d1, err := SumJSONCanonical(m1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Rebuilding the maps many times gives varied iteration orders.
+	for i := 0; i < 20; i++ {
+		if d2, err := SumJSONCanonical(m2); err != nil || d2 != d1 {
+			t.Fatalf("reordered map: digest %x, %v, want %x", d2, err, d1)
+		}
+	}
+	want := Sum([]byte(`{"a":["x",{"y":null,"z":true}],"b":1,"c":"<&>"}`))
+	if d1 != want {
+		t.Errorf("digest = %s, want that of the sorted compact encoding", hex.EncodeToString(d1[:]))
+	}
+
+	// A struct and a map with the same fields agree, whatever the field
+	// declaration order.
+	ds, err := SumJSONCanonical(canonicalRecord{Name: "n", Tags: []string{}, Count: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dm, err := SumJSONCanonical(map[string]any{"Count": 7, "Tags": []string{}, "Name": "n"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ds != dm {
+		t.Error("struct and equivalent map have different canonical digests")
+	}
+
+	if _, err := SumJSONCanonical(make(chan int)); err == nil {
+		t.Error("SumJSONCanonical accepted a channel")
+	}
+}
@@ -0,0 +1,66 @@
+package sm3
+
+import "fmt"
+
+// ChunkHasher hashes a stream while also reporting the SM3 digest of each
+// consecutive chunkSize-byte chunk of it, for protocols that checkpoint at
+// fixed intervals. Each chunk digest is Sum of that chunk's bytes alone;
+// Sum of the whole stream is maintained alongside. A ChunkHasher is not
+// safe for concurrent use.
+type ChunkHasher struct {
+	whole, chunk digest
+	size, fill   int // chunk size, bytes in the current chunk
+	index        int // index of the current chunk
+	emit         func(index int, digest [Size]byte)
+}
+
+// NewChunkHasher returns a ChunkHasher that calls emit with the index,
+// counting from 0, and digest of every chunk as soon as it is complete.
+// chunkSize must be at least 1.
+func NewChunkHasher(chunkSize int, emit func(index int, digest [Size]byte)) (*ChunkHasher, error) {
+	if chunkSize < 1 {
+		return nil, fmt.Errorf("%w: chunk size %d", ErrBadLength, chunkSize)
+	}
+	c := &ChunkHasher{size: chunkSize, emit: emit}
+	c.whole.Reset()
+	c.chunk.Reset()
+	return c, nil
+}
+
+// Write hashes p, calling emit for each chunk boundary it crosses. It
+// never fails.
+func (c *ChunkHasher) Write(p []byte) (int, error) {
+	n := len(p)
+	c.whole.Write(p)
+	for len(p) > 0 {
+		m := min(len(p), c.size-c.fill)
+		c.chunk.Write(p[:m])
+		c.fill += m
+		p = p[m:]
+		if c.fill == c.size {
+			c.flush()
+		}
+	}
+	return n, nil
+}
+
+// flush emits the current chunk and starts the next one.
+func (c *ChunkHasher) flush() {
+	c.emit(c.index, c.chunk.checkSum())
+	c.chunk.Reset()
+	c.fill = 0
+	c.index++
+}
+
+// Sum appends the SM3 digest of everything written so far to b. It does
+// not emit the pending partial chunk; Close does.
+func (c *ChunkHasher) Sum(b []byte) []byte { return c.whole.Sum(b) }
+
+// Close emits the final partial chunk, if any bytes have been written
+// since the last boundary. Writes after Close start a new chunk.
+func (c *ChunkHasher) Close() error {
+	if c.fill > 0 {
+		c.flush()
+	}
+	return nil
+}
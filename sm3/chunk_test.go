@@ -0,0 +1,50 @@
+package sm3
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestChunkHasher(t *testing.T) {
+	data := treeTestData(10*BlockSize + 7)
+	for _, size := range []int{1, 63, BlockSize, 100, len(data), len(data) + 1} {
+		var got [][Size]byte
+		c, err := NewChunkHasher(size, func(index int, digest [Size]byte) {
+			if index != len(got) {
+				t.Errorf("chunk size %d: emitted index %d, want %d", size, index, len(got))
+			}
+			got = append(got, digest)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Write in pieces that straddle chunk boundaries.
+		for i := 0; i < len(data); i += 37 {
+			c.Write(data[i:min(i+37, len(data))])
+		}
+		whole := Sum(data)
+		if sum := c.Sum(nil); !bytes.Equal(sum, whole[:]) {
+			t.Errorf("chunk size %d: Sum = %x, want %x", size, sum, whole)
+		}
+		c.Close()
+		c.Close()
+
+		var want [][Size]byte
+		for i := 0; i < len(data); i += size {
+			want = append(want, Sum(data[i:min(i+size, len(data))]))
+		}
+		if len(got) != len(want) {
+			t.Fatalf("chunk size %d: emitted %d chunks, want %d", size, len(got), len(want))
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("chunk size %d: chunk %d = %x, want %x", size, i, got[i], want[i])
+			}
+		}
+	}
+
+	if _, err := NewChunkHasher(0, nil); !errors.Is(err, ErrBadLength) {
+		t.Errorf("NewChunkHasher(0): err = %v, want %v", err, ErrBadLength)
+	}
+}
@@ -0,0 +1,48 @@
+package sm3
+
+import "hash/crc32"
+
+// castagnoli is the CRC-32C table used by Combo.
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// Combo computes the SM3 checksum and the CRC-32C (Castagnoli) of the same
+// data in one pass, so storage layers can reject most mismatches with the
+// cheap CRC before comparing digests. It implements hash.Hash, with Sum
+// appending the SM3 checksum.
+type Combo struct {
+	d   digest // not embedded, so no digest method can bypass the CRC
+	crc uint32
+}
+
+// NewCombo returns a new Combo.
+func NewCombo() *Combo {
+	c := new(Combo)
+	c.Reset()
+	return c
+}
+
+func (c *Combo) Reset() {
+	c.d.Reset()
+	c.crc = 0
+}
+
+func (c *Combo) Write(p []byte) (int, error) {
+	c.crc = crc32.Update(c.crc, castagnoli, p)
+	return c.d.Write(p)
+}
+
+// Sum appends the SM3 checksum of the data written so far to b.
+func (c *Combo) Sum(b []byte) []byte { return c.d.Sum(b) }
+
+func (c *Combo) Size() int { return Size }
+
+func (c *Combo) BlockSize() int { return BlockSize }
+
+// SumSM3 returns the SM3 checksum of the data written so far.
+func (c *Combo) SumSM3() [Size]byte {
+	d0 := c.d
+	return d0.checkSum()
+}
+
+// SumCRC32 returns the CRC-32C of the data written so far.
+func (c *Combo) SumCRC32() uint32 { return c.crc }
@@ -0,0 +1,34 @@
+package sm3
+
+import (
+	"bytes"
+	"hash/crc32"
+	"io"
+	"testing"
+)
+
+func TestCombo(t *testing.T) {
+	data := treeTestData(5*BlockSize + 11)
+	c := NewCombo()
+	if _, err := io.Copy(c, bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(c, "tail")
+	data = append(data, "tail"...)
+
+	if got, want := c.SumSM3(), Sum(data); got != want {
+		t.Errorf("SumSM3 = %x, want %x", got, want)
+	}
+	if got, want := c.SumCRC32(), crc32.Checksum(data, crc32.MakeTable(crc32.Castagnoli)); got != want {
+		t.Errorf("SumCRC32 = %08x, want %08x", got, want)
+	}
+	sum := c.SumSM3()
+	if got := c.Sum(nil); !bytes.Equal(got, sum[:]) {
+		t.Errorf("Sum = %x, want %x", got, sum)
+	}
+
+	c.Reset()
+	if c.SumSM3() != Sum(nil) || c.SumCRC32() != 0 {
+		t.Error("Reset did not clear both sums")
+	}
+}
@@ -0,0 +1,47 @@
+package sm3
+
+import "fmt"
+
+// IV returns the SM3 initial chaining value.
+func IV() [8]uint32 {
+	return [8]uint32{init0, init1, init2, init3, init4, init5, init6, init7}
+}
+
+// Compress runs the SM3 compression function CF once, returning the
+// chaining value that follows h after absorbing the 64-byte block b. It
+// gives MAC and tree-hash constructions direct access to CF; use New or Sum
+// to hash messages. Compress panics if len(b) != BlockSize.
+func Compress(h [8]uint32, b []byte) [8]uint32 {
+	if len(b) != BlockSize {
+		panic(fmt.Sprintf("sm3: Compress called with a %d-byte block", len(b)))
+	}
+	d := digest{h: h}
+	block(&d, b)
+	return d.h
+}
+
+// NewFromState returns a Digest that resumes hashing from chaining value h
+// after n bytes have been absorbed, as captured by State or built with
+// Compress. n must be a multiple of BlockSize, since the chaining value
+// only describes whole blocks; otherwise NewFromState returns an error
+// wrapping ErrInvalidState. NewFromState(IV(), 0) is equivalent to
+// NewReusable.
+func NewFromState(h [8]uint32, n uint64) (*Digest, error) {
+	if n%BlockSize != 0 {
+		return nil, fmt.Errorf("%w: length %d is not a whole number of blocks", ErrInvalidState, n)
+	}
+	d := new(Digest)
+	d.h = h
+	d.len = n
+	return d, nil
+}
+
+// State returns the chaining value of d and the number of bytes absorbed
+// into it. It fails with an error wrapping ErrInvalidState unless the
+// bytes written so far end on a block boundary.
+func (d *Digest) State() (h [8]uint32, n uint64, err error) {
+	if d.nx != 0 {
+		return h, 0, fmt.Errorf("%w: %d bytes buffered past a block boundary", ErrInvalidState, d.nx)
+	}
+	return d.h, d.len, nil
+}
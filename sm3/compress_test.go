@@ -0,0 +1,180 @@
+package sm3
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+// padMessage returns data followed by the SM3 padding and length.
+func padMessage(data []byte) []byte {
+	padded := append([]byte(nil), data...)
+	padded = append(padded, 0x80)
+	for len(padded)%BlockSize != 56 {
+		padded = append(padded, 0)
+	}
+	return binary.BigEndian.AppendUint64(padded, uint64(len(data))<<3)
+}
+
+func TestCompressReproducesSum(t *testing.T) {
+	for _, n := range []int{0, 3, 55, 56, 64, 200} {
+		data := make([]byte, n)
+		for i := range data {
+			data[i] = byte(i + n)
+		}
+		h := IV()
+		padded := padMessage(data)
+		for len(padded) > 0 {
+			h = Compress(h, padded[:BlockSize])
+			padded = padded[BlockSize:]
+		}
+		var got [Size]byte
+		for i, v := range h {
+			binary.BigEndian.PutUint32(got[i*4:], v)
+		}
+		if want := Sum(data); got != want {
+			t.Errorf("len %d: iterated Compress = %x, want %x", n, got, want)
+		}
+	}
+}
+
+func TestCompressRejectsShortBlock(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Compress accepted a 63-byte block")
+		}
+	}()
+	Compress(IV(), make([]byte, BlockSize-1))
+}
+
+func TestLoadWords(t *testing.T) {
+	// Random blocks at every alignment, in case the fast loader assumes
+	// aligned input.
+	rng := rand.New(rand.NewSource(1))
+	data := make([]byte, BlockSize+8)
+	for i := 0; i < 1000; i++ {
+		rng.Read(data)
+		p := (*[BlockSize]byte)(data[i%8:])
+		var got, want [16]uint32
+		loadWords(&got, p)
+		loadWordsGeneric(&want, p)
+		if got != want {
+			t.Fatalf("offset %d: loadWords(%x) = %x, want %x", i%8, p[:BlockSize], got, want)
+		}
+	}
+}
+
+// TestBigEndianWords checks inputs whose digests change if any word of the
+// message, the length field or the output were handled little-endian.
+func TestBigEndianWords(t *testing.T) {
+	for _, g := range []sm3Test{golden[1], golden[2]} {
+		want, _ := hex.DecodeString(g.out)
+		if got := Sum([]byte(g.in)); !bytes.Equal(got[:], want) {
+			t.Fatalf("Sum(%q) = %x, want %x", g.in, got, want)
+		}
+
+		// Loading the padded message as little-endian words gives a
+		// different chaining value, so the vector above would catch it.
+		padded := padMessage([]byte(g.in))
+		h := IV()
+		for len(padded) > 0 {
+			var swapped [BlockSize]byte
+			for i := 0; i < BlockSize; i += 4 {
+				binary.LittleEndian.PutUint32(swapped[i:], binary.BigEndian.Uint32(padded[i:]))
+			}
+			h = Compress(h, swapped[:])
+			padded = padded[BlockSize:]
+		}
+		var le [Size]byte
+		for i, v := range h {
+			binary.BigEndian.PutUint32(le[i*4:], v)
+		}
+		if bytes.Equal(le[:], want) {
+			t.Errorf("%q: digest unchanged under little-endian word loads", g.in)
+		}
+
+		// As does a little-endian length field.
+		padded = padMessage([]byte(g.in))
+		binary.LittleEndian.PutUint64(padded[len(padded)-8:], uint64(len(g.in))<<3)
+		h = IV()
+		for len(padded) > 0 {
+			h = Compress(h, padded[:BlockSize])
+			padded = padded[BlockSize:]
+		}
+		for i, v := range h {
+			binary.BigEndian.PutUint32(le[i*4:], v)
+		}
+		if bytes.Equal(le[:], want) {
+			t.Errorf("%q: digest unchanged under a little-endian length", g.in)
+		}
+
+		// And writing the output words little-endian.
+		for i := 0; i < Size; i += 4 {
+			binary.LittleEndian.PutUint32(le[i:], binary.BigEndian.Uint32(want[i:]))
+		}
+		if bytes.Equal(le[:], want) {
+			t.Errorf("%q: digest unchanged under little-endian output", g.in)
+		}
+	}
+}
+
+func TestNewFromStateIV(t *testing.T) {
+	for _, g := range golden {
+		d, err := NewFromState(IV(), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fresh := New()
+		d.Write([]byte(g.in))
+		fresh.Write([]byte(g.in))
+		if got, want := d.Sum(nil), fresh.Sum(nil); !bytes.Equal(got, want) {
+			t.Errorf("NewFromState(IV(), 0) over %q = %x, want %x", g.in, got, want)
+		}
+	}
+}
+
+func TestNewFromStateResume(t *testing.T) {
+	data := make([]byte, 5*BlockSize+20)
+	for i := range data {
+		data[i] = byte(3 * i)
+	}
+	want := Sum(data)
+
+	d := NewReusable()
+	d.Write(data[:3*BlockSize])
+	h, n, err := d.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3*BlockSize {
+		t.Errorf("State length = %d, want %d", n, 3*BlockSize)
+	}
+	resumed, err := NewFromState(h, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resumed.Write(data[n:])
+	if got := resumed.Sum(nil); !bytes.Equal(got, want[:]) {
+		t.Errorf("resumed digest = %x, want %x", got, want)
+	}
+
+	// The snapshot agrees with iterating Compress from the IV.
+	c := IV()
+	for i := 0; i < 3; i++ {
+		c = Compress(c, data[i*BlockSize:(i+1)*BlockSize])
+	}
+	if c != h {
+		t.Errorf("State chaining value = %x, want %x", h, c)
+	}
+
+	d.Write(data[n : n+1])
+	if _, _, err := d.State(); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("State mid-block: err = %v, want %v", err, ErrInvalidState)
+	}
+	if _, err := NewFromState(h, n+1); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("NewFromState(h, %d): err = %v, want %v", n+1, err, ErrInvalidState)
+	}
+}
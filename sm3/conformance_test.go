@@ -0,0 +1,96 @@
+package sm3
+
+import (
+	"bytes"
+	"hash"
+	"testing"
+)
+
+// hashConstructors lists every hash.Hash constructor the package provides.
+// Each one is run through testHashConformance.
+var hashConstructors = []struct {
+	name string
+	new  func() hash.Hash
+}{
+	{"New", New},
+	{"NewHMAC", func() hash.Hash { return NewHMAC([]byte("conformance key")) }},
+	{"NewLimited", func() hash.Hash { h, _ := NewLimited(1 << 20); return h }},
+	{"NewFramedHasher", func() hash.Hash { return NewFramedHasher() }},
+	{"NewPrefixed", NewPrefixed([]byte("conformance prefix"))},
+	{"NewReusable", func() hash.Hash { return NewReusable() }},
+	{"NewHMACReusable", func() hash.Hash { return NewHMACReusable([]byte("conformance key")) }},
+	{"NewCombo", func() hash.Hash { return NewCombo() }},
+}
+
+func TestConformance(t *testing.T) {
+	for _, c := range hashConstructors {
+		t.Run(c.name, func(t *testing.T) {
+			testHashConformance(t, c.new)
+		})
+	}
+}
+
+// testHashConformance exercises the hash.Hash contract against newHash.
+func testHashConformance(t *testing.T, newHash func() hash.Hash) {
+	t.Helper()
+	msg := make([]byte, 3*BlockSize+17)
+	for i := range msg {
+		msg[i] = byte(i * 7)
+	}
+
+	h := newHash()
+	size, blockSize := h.Size(), h.BlockSize()
+	empty := h.Sum(nil)
+	if len(empty) != size {
+		t.Errorf("len(Sum(nil)) = %d, want Size() = %d", len(empty), size)
+	}
+
+	// Sum appends to its argument.
+	prefix := []byte("prefix")
+	if got := h.Sum(append([]byte(nil), prefix...)); !bytes.Equal(got[:len(prefix)], prefix) || !bytes.Equal(got[len(prefix):], empty) {
+		t.Errorf("Sum(prefix) = %x, want prefix followed by %x", got, empty)
+	}
+
+	// Writing in arbitrary chunks equals writing all at once.
+	h.Write(msg)
+	whole := h.Sum(nil)
+	for _, chunk := range []int{1, 3, blockSize - 1, blockSize, blockSize + 1} {
+		c := newHash()
+		for i := 0; i < len(msg); i += chunk {
+			n, err := c.Write(msg[i:min(i+chunk, len(msg))])
+			if err != nil || n != min(chunk, len(msg)-i) {
+				t.Fatalf("Write returned (%d, %v)", n, err)
+			}
+		}
+		if got := c.Sum(nil); !bytes.Equal(got, whole) {
+			t.Errorf("chunk size %d: Sum = %x, want %x", chunk, got, whole)
+		}
+	}
+
+	// Sum does not change the state, so repeated calls agree and Write
+	// after Sum carries on from the same point.
+	c := newHash()
+	c.Write(msg[:100])
+	first := c.Sum(nil)
+	if second := c.Sum(nil); !bytes.Equal(first, second) {
+		t.Errorf("second Sum = %x, want %x", second, first)
+	}
+	c.Write(msg[100:])
+	if got := c.Sum(nil); !bytes.Equal(got, whole) {
+		t.Errorf("Write after Sum: Sum = %x, want %x", got, whole)
+	}
+
+	// Reset returns to the initial state.
+	c.Reset()
+	if got := c.Sum(nil); !bytes.Equal(got, empty) {
+		t.Errorf("Sum after Reset = %x, want %x", got, empty)
+	}
+	c.Write(msg)
+	if got := c.Sum(nil); !bytes.Equal(got, whole) {
+		t.Errorf("Write after Reset: Sum = %x, want %x", got, whole)
+	}
+
+	if c.Size() != size || c.BlockSize() != blockSize {
+		t.Errorf("Size/BlockSize changed from %d/%d to %d/%d", size, blockSize, c.Size(), c.BlockSize())
+	}
+}
@@ -0,0 +1,38 @@
+package sm3
+
+// DedupSet records which payloads have been seen by their SM3 digests, so
+// deduplicating large payloads holds 32 bytes per entry rather than the
+// payloads themselves.
+//
+// Two payloads count as the same if their digests are equal. That relies
+// on SM3 being collision resistant: a collision would make distinct
+// content look like a duplicate, but none is known and finding one is
+// believed to take about 2^128 work. A DedupSet is not safe for concurrent
+// use.
+type DedupSet struct {
+	seen map[[Size]byte]struct{}
+}
+
+// NewDedupSet returns an empty DedupSet.
+func NewDedupSet() *DedupSet {
+	return &DedupSet{seen: make(map[[Size]byte]struct{})}
+}
+
+// Add records data and reports whether it was new to the set.
+func (s *DedupSet) Add(data []byte) (added bool) {
+	sum := Sum(data)
+	if _, ok := s.seen[sum]; ok {
+		return false
+	}
+	s.seen[sum] = struct{}{}
+	return true
+}
+
+// Contains reports whether data has been added to the set.
+func (s *DedupSet) Contains(data []byte) bool {
+	_, ok := s.seen[Sum(data)]
+	return ok
+}
+
+// Len returns the number of distinct payloads in the set.
+func (s *DedupSet) Len() int { return len(s.seen) }
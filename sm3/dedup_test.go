@@ -0,0 +1,29 @@
+package sm3
+
+import "testing"
+
+func TestDedupSet(t *testing.T) {
+	s := NewDedupSet()
+	a, b := []byte("payload a"), []byte("payload b")
+	if s.Contains(a) {
+		t.Error("empty set contains a")
+	}
+	if !s.Add(a) {
+		t.Error("first Add(a) = false")
+	}
+	if s.Add([]byte("payload a")) {
+		t.Error("second Add of the same content = true")
+	}
+	if !s.Contains(a) || s.Contains(b) {
+		t.Errorf("Contains(a), Contains(b) = %v, %v; want true, false", s.Contains(a), s.Contains(b))
+	}
+	if !s.Add(b) || !s.Contains(b) {
+		t.Error("distinct content was not added independently")
+	}
+	if !s.Add(nil) || s.Add([]byte{}) {
+		t.Error("nil and empty payloads are not the same single entry")
+	}
+	if s.Len() != 3 {
+		t.Errorf("Len = %d, want 3", s.Len())
+	}
+}
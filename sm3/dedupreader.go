@@ -0,0 +1,104 @@
+package sm3
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DedupRecordSize is the record length NewDedupReader uses when it is not
+// line-based, the 512-byte record of tar archives and disk sectors.
+const DedupRecordSize = 512
+
+// dedupReader passes through the first occurrence of each record of an
+// underlying reader, keeping a DedupSet of the records seen.
+type dedupReader struct {
+	br   *bufio.Reader
+	size int // record length, or 0 for lines
+	seen *DedupSet
+	rec  []byte // the record being read or passed through
+	out  []byte // unread part of rec
+	err  error
+}
+
+// NewDedupReader returns a reader that yields the data of r with duplicate
+// records dropped: each record is passed through the first time it occurs
+// and skipped after that. If lineBased is true, the records are lines
+// ending in '\n'; otherwise they are blocks of DedupRecordSize bytes.
+//
+// Records are recognized by their SM3 digests, held in a DedupSet, so
+// deduplicating a large log retains 32 bytes per distinct record rather
+// than the records, plus a buffer as long as the longest record. The
+// caveat of DedupSet applies: a digest collision would drop a distinct
+// record.
+//
+// A last line without a trailing newline is a record too, passed through
+// as it is, and counts as a duplicate of the same line with a newline; a
+// short last block likewise counts as a record. The reader is not safe
+// for concurrent use.
+func NewDedupReader(r io.Reader, lineBased bool) io.Reader {
+	if lineBased {
+		return newDedupReader(r, 0)
+	}
+	return newDedupReader(r, DedupRecordSize)
+}
+
+// NewDedupRecordReader is NewDedupReader for fixed records of size bytes.
+// It returns an error wrapping ErrBadLength if size is less than 1.
+func NewDedupRecordReader(r io.Reader, size int) (io.Reader, error) {
+	if size < 1 {
+		return nil, fmt.Errorf("%w: record size %d", ErrBadLength, size)
+	}
+	return newDedupReader(r, size), nil
+}
+
+func newDedupReader(r io.Reader, size int) *dedupReader {
+	return &dedupReader{br: bufio.NewReader(r), size: size, seen: NewDedupSet()}
+}
+
+func (d *dedupReader) Read(p []byte) (int, error) {
+	for len(d.out) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+		d.next()
+	}
+	n := copy(p, d.out)
+	d.out = d.out[n:]
+	return n, nil
+}
+
+// next reads one record into d.rec and, if it has not been seen before,
+// sets d.out to pass it through.
+func (d *dedupReader) next() {
+	d.rec = d.rec[:0]
+	if d.size == 0 {
+		for {
+			line, err := d.br.ReadSlice('\n')
+			d.rec = append(d.rec, line...)
+			if !errors.Is(err, bufio.ErrBufferFull) {
+				d.err = err
+				break
+			}
+		}
+	} else {
+		if cap(d.rec) < d.size {
+			d.rec = make([]byte, d.size)
+		}
+		n, err := io.ReadFull(d.br, d.rec[:d.size])
+		d.rec = d.rec[:n]
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			err = io.EOF
+		}
+		d.err = err
+	}
+	key := d.rec
+	if d.size == 0 {
+		key = bytes.TrimSuffix(key, []byte("\n"))
+	}
+	if len(d.rec) > 0 && d.seen.Add(key) {
+		d.out = d.rec
+	}
+}
@@ -0,0 +1,91 @@
+package sm3
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestDedupReaderLines(t *testing.T) {
+	for _, tt := range []struct{ in, want string }{
+		{"a\nb\na\nc\nb\nb\nd\n", "a\nb\nc\nd\n"},
+		{"a\nb\na", "a\nb\n"},  // last line a duplicate without its newline
+		{"a\nb\nc", "a\nb\nc"}, // unique last line passed through as is
+		{"\n\nx\n\n", "\nx\n"}, // empty lines are records too
+		{"", ""},
+	} {
+		for name, r := range map[string]io.Reader{
+			"whole":   strings.NewReader(tt.in),
+			"onebyte": iotest.OneByteReader(strings.NewReader(tt.in)),
+		} {
+			got, err := io.ReadAll(NewDedupReader(r, true))
+			if err != nil || string(got) != tt.want {
+				t.Errorf("%s: %q: got %q, %v; want %q", name, tt.in, got, err, tt.want)
+			}
+		}
+	}
+}
+
+// TestDedupReaderMemory checks that the reader keeps one digest per
+// distinct line, not the lines: lines longer than the bufio buffer are
+// deduplicated correctly, and what stays behind is the DedupSet of 32-byte
+// keys and a single record buffer.
+func TestDedupReaderMemory(t *testing.T) {
+	long := strings.Repeat("x", 10000) + "\n"
+	var in, want bytes.Buffer
+	for i := 0; i < 50; i++ {
+		line := long[:100*i] + "\n"
+		in.WriteString(line)
+		in.WriteString(line)
+		want.WriteString(line)
+	}
+	in.WriteString(long)
+	want.WriteString(long)
+
+	d := newDedupReader(&in, 0)
+	got, err := io.ReadAll(d)
+	if err != nil || !bytes.Equal(got, want.Bytes()) {
+		t.Fatalf("got %d bytes, %v; want %d bytes", len(got), err, want.Len())
+	}
+	if n := d.seen.Len(); n != 51 {
+		t.Errorf("%d digests retained, want 51", n)
+	}
+	var _ map[[Size]byte]struct{} = d.seen.seen // keys are digests
+	if cap(d.rec) > 2*len(long) {
+		t.Errorf("record buffer holds %d bytes, want at most one line", cap(d.rec))
+	}
+}
+
+func TestDedupReaderRecords(t *testing.T) {
+	rec := func(c byte, n int) string { return strings.Repeat(string(c), n) }
+	in := rec('a', 512) + rec('b', 512) + rec('a', 512) + rec('c', 100)
+	got, err := io.ReadAll(NewDedupReader(strings.NewReader(in), false))
+	if want := rec('a', 512) + rec('b', 512) + rec('c', 100); err != nil || string(got) != want {
+		t.Errorf("got %d bytes, %v; want %d bytes", len(got), err, len(want))
+	}
+
+	r, err := NewDedupRecordReader(strings.NewReader("ab\nab\nab\ncd"), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Records are taken byte for byte: "ab\n" repeats, while "cd" is the
+	// short last record.
+	if got, err := io.ReadAll(r); err != nil || string(got) != "ab\ncd" {
+		t.Errorf("3-byte records: got %q, %v", got, err)
+	}
+	if _, err := NewDedupRecordReader(strings.NewReader(""), 0); !errors.Is(err, ErrBadLength) {
+		t.Errorf("size 0: err = %v, want ErrBadLength", err)
+	}
+}
+
+func TestDedupReaderError(t *testing.T) {
+	boom := errors.New("boom")
+	r := io.MultiReader(strings.NewReader("a\na\nb"), iotest.ErrReader(boom))
+	got, err := io.ReadAll(NewDedupReader(r, true))
+	if string(got) != "a\nb" || !errors.Is(err, boom) {
+		t.Errorf("got %q, %v; want %q, boom", got, err, "a\nb")
+	}
+}
@@ -0,0 +1,28 @@
+package sm3
+
+import "crypto/subtle"
+
+// Diff returns the index of the first byte at which a and b differ, or -1
+// if they are equal. It is meant for test and debugging output, where
+// "digests differ at byte 17" says more than "digests differ".
+//
+// Diff returns as soon as it finds a difference, so its running time
+// reveals how long a prefix the two digests share. It must not be used to
+// check a MAC or any other secret-dependent value; use Equal for that.
+func Diff(a, b Sum256) int {
+	for i := range a {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return -1
+}
+
+// Equal reports whether a and b are the same digest, in time independent
+// of their contents. Comparing with == stops at the first differing byte,
+// like Diff, which can leak how much of a secret tag an attacker has
+// guessed; Equal is the comparison to use when a digest authenticates
+// something.
+func Equal(a, b Sum256) bool {
+	return subtle.ConstantTimeCompare(a[:], b[:]) == 1
+}
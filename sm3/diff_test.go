@@ -0,0 +1,41 @@
+package sm3
+
+import "testing"
+
+func TestDiff(t *testing.T) {
+	a := Sum([]byte("abc"))
+	first, last := a, a
+	first[0] ^= 1
+	last[Size-1] ^= 0x80
+	for _, tt := range []struct {
+		name string
+		b    [Size]byte
+		want int
+	}{
+		{"equal", a, -1},
+		{"first byte", first, 0},
+		{"last byte", last, Size - 1},
+		{"other digest", Sum([]byte("abd")), 0},
+	} {
+		if got := Diff(a, tt.b); got != tt.want {
+			t.Errorf("%s: Diff = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEqual(t *testing.T) {
+	a := Sum([]byte("abc"))
+	if !Equal(a, Sum([]byte("abc"))) {
+		t.Error("Equal reports equal digests as different")
+	}
+	for _, i := range []int{0, Size / 2, Size - 1} {
+		b := a
+		b[i] ^= 0x01
+		if Equal(a, b) {
+			t.Errorf("Equal reports digests differing at byte %d as equal", i)
+		}
+	}
+	if Equal(a, [Size]byte{}) {
+		t.Error("Equal(a, zero) = true")
+	}
+}
@@ -0,0 +1,123 @@
+package sm3
+
+import "encoding/binary"
+
+// DigestMap is a hash table keyed by SM3 digests, stored with open
+// addressing and linear probing in one flat slice. Digests are already
+// uniformly distributed, so the first 8 bytes of a key serve as its hash,
+// and there are no per-entry allocations or buckets as in a
+// map[[Size]byte]V.
+//
+// The zero value is an empty map ready to use. A DigestMap is not safe for
+// concurrent use.
+type DigestMap[V any] struct {
+	slots []digestSlot[V] // len is zero or a power of two
+	n     int
+}
+
+type digestSlot[V any] struct {
+	key  [Size]byte
+	used bool
+	val  V
+}
+
+// NewDigestMap returns an empty DigestMap with room for hint entries before
+// it grows.
+func NewDigestMap[V any](hint int) *DigestMap[V] {
+	m := new(DigestMap[V])
+	if hint > 0 {
+		m.resize(digestMapCapacity(hint))
+	}
+	return m
+}
+
+// digestMapCapacity returns the smallest power of two table that holds n
+// entries within the maximum load factor of 3/4.
+func digestMapCapacity(n int) int {
+	c := 8
+	for c*3/4 < n {
+		c <<= 1
+	}
+	return c
+}
+
+func (m *DigestMap[V]) home(key *[Size]byte) int {
+	return int(binary.BigEndian.Uint64(key[:8]) & uint64(len(m.slots)-1))
+}
+
+// find returns the slot holding key, or the empty slot where it would go
+// and false. The table must not be empty.
+func (m *DigestMap[V]) find(key *[Size]byte) (int, bool) {
+	mask := len(m.slots) - 1
+	for i := m.home(key); ; i = (i + 1) & mask {
+		s := &m.slots[i]
+		if !s.used {
+			return i, false
+		}
+		if s.key == *key {
+			return i, true
+		}
+	}
+}
+
+// Get returns the value stored under key and whether it was present.
+func (m *DigestMap[V]) Get(key [Size]byte) (V, bool) {
+	if m.n == 0 {
+		var zero V
+		return zero, false
+	}
+	i, ok := m.find(&key)
+	return m.slots[i].val, ok
+}
+
+// Put stores val under key, replacing any previous value.
+func (m *DigestMap[V]) Put(key [Size]byte, val V) {
+	if len(m.slots) == 0 || (m.n+1) > len(m.slots)*3/4 {
+		m.resize(digestMapCapacity(m.n + 1))
+	}
+	i, ok := m.find(&key)
+	if !ok {
+		m.slots[i].key = key
+		m.slots[i].used = true
+		m.n++
+	}
+	m.slots[i].val = val
+}
+
+// Delete removes key, if present. Later entries of the probe run are
+// shifted back into the gap, so deletions leave no tombstones.
+func (m *DigestMap[V]) Delete(key [Size]byte) {
+	if m.n == 0 {
+		return
+	}
+	i, ok := m.find(&key)
+	if !ok {
+		return
+	}
+	mask := len(m.slots) - 1
+	for j := (i + 1) & mask; m.slots[j].used; j = (j + 1) & mask {
+		// The entry at j may move to the gap at i unless its home lies
+		// cyclically in (i, j], where moving it would put it before home.
+		if (j-m.home(&m.slots[j].key))&mask >= (j-i)&mask {
+			m.slots[i] = m.slots[j]
+			i = j
+		}
+	}
+	m.slots[i] = digestSlot[V]{}
+	m.n--
+}
+
+// Len returns the number of entries in m.
+func (m *DigestMap[V]) Len() int { return m.n }
+
+// resize rehashes every entry into a table of capacity slots.
+func (m *DigestMap[V]) resize(capacity int) {
+	old := m.slots
+	m.slots = make([]digestSlot[V], capacity)
+	for k := range old {
+		if s := &old[k]; s.used {
+			i, _ := m.find(&s.key)
+			m.slots[i] = *s
+		}
+	}
+}
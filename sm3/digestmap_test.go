@@ -0,0 +1,153 @@
+package sm3
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"testing"
+)
+
+func TestDigestMap(t *testing.T) {
+	var m DigestMap[int]
+	if _, ok := m.Get(Sum(nil)); ok || m.Len() != 0 {
+		t.Fatal("zero DigestMap is not empty")
+	}
+	m.Delete(Sum(nil))
+
+	a, b := SumString("a"), SumString("b")
+	m.Put(a, 1)
+	m.Put(b, 2)
+	m.Put(a, 3)
+	if v, ok := m.Get(a); !ok || v != 3 {
+		t.Errorf("Get(a) = %d, %v, want 3, true", v, ok)
+	}
+	if v, ok := m.Get(b); !ok || v != 2 {
+		t.Errorf("Get(b) = %d, %v, want 2, true", v, ok)
+	}
+	if m.Len() != 2 {
+		t.Errorf("Len = %d, want 2", m.Len())
+	}
+	m.Delete(a)
+	if _, ok := m.Get(a); ok || m.Len() != 1 {
+		t.Errorf("after Delete(a): present %v, Len %d", ok, m.Len())
+	}
+}
+
+// collidingKey returns a key whose first 8 bytes, and so its home slot,
+// are the same for every i.
+func collidingKey(i int) [Size]byte {
+	var k [Size]byte
+	binary.BigEndian.PutUint64(k[:8], 0x0123456789abcdef)
+	binary.BigEndian.PutUint64(k[24:], uint64(i))
+	return k
+}
+
+func TestDigestMapCollisions(t *testing.T) {
+	m := NewDigestMap[int](4)
+	const n = 5
+	for i := 0; i < n; i++ {
+		m.Put(collidingKey(i), i)
+	}
+	// Deleting from the middle of the probe run must leave the rest
+	// reachable.
+	m.Delete(collidingKey(1))
+	m.Delete(collidingKey(3))
+	for i := 0; i < n; i++ {
+		v, ok := m.Get(collidingKey(i))
+		if want := i != 1 && i != 3; ok != want || (ok && v != i) {
+			t.Errorf("Get(key %d) = %d, %v, want present %v", i, v, ok, want)
+		}
+	}
+	if m.Len() != n-2 {
+		t.Errorf("Len = %d, want %d", m.Len(), n-2)
+	}
+}
+
+func TestDigestMapRandom(t *testing.T) {
+	// Random operations on a small key space, so probe runs wrap around
+	// the table and deletions shift entries back, checked against a map.
+	rng := rand.New(rand.NewSource(1))
+	var m DigestMap[int]
+	ref := make(map[[Size]byte]int)
+	const keys = 300
+	for op := 0; op < 20000; op++ {
+		var k [Size]byte
+		binary.BigEndian.PutUint64(k[:8], uint64(rng.Intn(keys))*0x9e3779b97f4a7c15)
+		if rng.Intn(4) == 0 {
+			// Force shared home slots in any table of up to 2^16 entries.
+			k[6], k[7] = 0, 0
+		}
+		switch rng.Intn(3) {
+		case 0, 1:
+			m.Put(k, op)
+			ref[k] = op
+		case 2:
+			m.Delete(k)
+			delete(ref, k)
+		}
+		if m.Len() != len(ref) {
+			t.Fatalf("op %d: Len = %d, want %d", op, m.Len(), len(ref))
+		}
+	}
+	for k, want := range ref {
+		if v, ok := m.Get(k); !ok || v != want {
+			t.Fatalf("Get(%x) = %d, %v, want %d, true", k[:8], v, ok, want)
+		}
+	}
+}
+
+func TestDigestMapResize(t *testing.T) {
+	m := NewDigestMap[int](0)
+	const n = 10000
+	for i := 0; i < n; i++ {
+		m.Put(Sum(binary.BigEndian.AppendUint32(nil, uint32(i))), i)
+	}
+	if m.Len() != n {
+		t.Fatalf("Len = %d, want %d", m.Len(), n)
+	}
+	if c := len(m.slots); c < n*4/3 || c&(c-1) != 0 {
+		t.Errorf("table of %d slots for %d entries", c, n)
+	}
+	for i := 0; i < n; i++ {
+		if v, ok := m.Get(Sum(binary.BigEndian.AppendUint32(nil, uint32(i)))); !ok || v != i {
+			t.Fatalf("Get(%d) = %d, %v", i, v, ok)
+		}
+	}
+}
+
+func benchmarkDigestKeys() [][Size]byte {
+	keys := make([][Size]byte, 1<<16)
+	for i := range keys {
+		keys[i] = Sum(binary.BigEndian.AppendUint32(nil, uint32(i)))
+	}
+	return keys
+}
+
+func BenchmarkDigestMap(b *testing.B) {
+	keys := benchmarkDigestKeys()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var m DigestMap[int]
+		for j, k := range keys {
+			m.Put(k, j)
+		}
+		for _, k := range keys {
+			m.Get(k)
+		}
+	}
+}
+
+func BenchmarkDigestMapBuiltin(b *testing.B) {
+	keys := benchmarkDigestKeys()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m := make(map[[Size]byte]int)
+		for j, k := range keys {
+			m[k] = j
+		}
+		for _, k := range keys {
+			_ = m[k]
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package sm3
+
+import "errors"
+
+// Sentinel errors returned, possibly wrapped, by the functions in this
+// package. Use errors.Is to test for them.
+var (
+	// ErrBadLength reports an input whose length is outside the range the
+	// operation accepts.
+	ErrBadLength = errors.New("sm3: bad input length")
+
+	// ErrOutputTooLong reports a request for more derived output than the
+	// construction can produce.
+	ErrOutputTooLong = errors.New("sm3: requested output too long")
+
+	// ErrInvalidState reports a serialized hash state that cannot be
+	// restored.
+	ErrInvalidState = errors.New("sm3: invalid hash state")
+
+	// ErrTruncated is returned by SumReaderLimit when the stream holds more
+	// data than the limit allows.
+	ErrTruncated = errors.New("sm3: input longer than limit")
+
+	// ErrInputTooLarge is returned by the Write method of a hash created
+	// with NewLimited once its input limit would be exceeded.
+	ErrInputTooLarge = errors.New("sm3: input exceeds limit")
+
+	// ErrAuth reports a message authentication failure: the tag does not
+	// match the data it is supposed to cover.
+	ErrAuth = errors.New("sm3: message authentication failed")
+
+	// ErrMalformedPasswordHash is returned by VerifyPassword for an encoded
+	// verifier that is not in the form HashPassword produces.
+	ErrMalformedPasswordHash = errors.New("sm3: malformed password hash")
+
+	// ErrMalformedPrivateKey reports an SM2 private key encoding that is not
+	// valid PKCS#8 or SEC1 DER, or holds a scalar outside [1, n-2].
+	ErrMalformedPrivateKey = errors.New("sm3: malformed SM2 private key")
+
+	// ErrMalformedToken is returned by VerifyJWT for a token that is not a
+	// compact JWS with an HS-SM3 header.
+	ErrMalformedToken = errors.New("sm3: malformed JWT")
+
+	// ErrMalformedSignature reports an SM2 signature that is not a valid DER
+	// encoding of two integers.
+	ErrMalformedSignature = errors.New("sm3: malformed SM2 signature")
+
+	// ErrMalformedCiphertext reports an SM2 ciphertext that does not have
+	// the structure of either the ASN.1 or the C1||C3||C2 encoding.
+	ErrMalformedCiphertext = errors.New("sm3: malformed SM2 ciphertext")
+
+	// ErrKDFFailure is returned by EncryptSM2 when every ephemeral key it
+	// tried gave an all-zero KDF mask, and by DecryptSM2 for a ciphertext
+	// whose mask is all zero.
+	ErrKDFFailure = errors.New("sm3: SM2 KDF output is all zero")
+
+	// ErrNoValidScalar reports a rand source from which a bounded number of
+	// rejection-sampling attempts drew no scalar in [1, n-2], as a constant
+	// or broken reader does.
+	ErrNoValidScalar = errors.New("sm3: rand source produced no valid scalar")
+
+	// ErrInvalidSignature reports an SM2 signature that is well-formed but
+	// does not verify.
+	ErrInvalidSignature = errors.New("sm3: invalid SM2 signature")
+
+	// ErrMalformedCertificate reports a certificate or SubjectPublicKeyInfo
+	// that is not valid DER of the expected structure.
+	ErrMalformedCertificate = errors.New("sm3: malformed certificate")
+
+	// ErrNotAuthorizedToSign reports a certificate chain in which an issuer
+	// is not a CA certificate allowed to sign the certificates below it.
+	ErrNotAuthorizedToSign = errors.New("sm3: certificate is not authorized to sign other certificates")
+
+	// ErrUnsupportedAlgorithm reports a certificate signed with, or a public
+	// key for, an algorithm other than SM2.
+	ErrUnsupportedAlgorithm = errors.New("sm3: unsupported certificate algorithm")
+
+	// ErrUnsupportedType is returned by CacheKey for an argument of a type
+	// it has no canonical encoding for.
+	ErrUnsupportedType = errors.New("sm3: unsupported argument type")
+
+	// ErrInvalidPublicKey reports an SM2 public key that is not a valid
+	// point on sm2p256v1.
+	ErrInvalidPublicKey = errors.New("sm3: invalid SM2 public key")
+)
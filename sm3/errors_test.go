@@ -0,0 +1,45 @@
+package sm3
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestSentinelErrors(t *testing.T) {
+	priv, err := GenerateSM2Key(rand.New(rand.NewSource(5)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	offCurve := &SM2PublicKey{Curve: P256SM2(), X: big.NewInt(1), Y: big.NewInt(2)}
+
+	for _, tt := range []struct {
+		name string
+		err  func() error
+		want error
+	}{
+		{"ZA long ID", func() error {
+			_, err := ZA(&priv.SM2PublicKey, make([]byte, 1<<13))
+			return err
+		}, ErrBadLength},
+		{"key exchange off-curve peer", func() error {
+			_, _, _, err := InitiatorKeyExchange(priv, priv, nil, offCurve, &priv.SM2PublicKey, nil, 16)
+			return err
+		}, ErrInvalidPublicKey},
+		{"GenerateSM2Key short rand", func() error {
+			_, err := GenerateSM2Key(bytes.NewReader(make([]byte, 10)))
+			return err
+		}, io.ErrUnexpectedEOF},
+		{"SumReaderLimit", func() error {
+			_, _, err := SumReaderLimit(bytes.NewReader(make([]byte, 10)), 5)
+			return err
+		}, ErrTruncated},
+	} {
+		if err := tt.err(); !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, want errors.Is %v", tt.name, err, tt.want)
+		}
+	}
+}
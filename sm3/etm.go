@@ -0,0 +1,82 @@
+package sm3
+
+import (
+	"crypto/cipher"
+	"crypto/hmac"
+	"encoding/binary"
+)
+
+// EncryptThenMAC is an authenticated encryption construction that encrypts
+// with a stream cipher and then authenticates with HMAC-SM3. It is meant
+// for GM experiments where no AEAD mode of the block cipher is at hand.
+type EncryptThenMAC struct {
+	newStream func(nonce []byte) cipher.Stream
+	macKey    []byte
+}
+
+// NewEncryptThenMAC returns an EncryptThenMAC that encrypts with the stream
+// newStream returns for each nonce, for example a CTR mode of SM4, and
+// authenticates with HMAC-SM3 under macKey. The encryption and MAC keys
+// must be independent.
+func NewEncryptThenMAC(newStream func(nonce []byte) cipher.Stream, macKey []byte) *EncryptThenMAC {
+	return &EncryptThenMAC{newStream: newStream, macKey: append([]byte(nil), macKey...)}
+}
+
+// Overhead returns the number of bytes Seal adds to the plaintext.
+func (e *EncryptThenMAC) Overhead() int { return Size }
+
+// etmTag computes HMAC-SM3 under macKey over the nonce, additional data and
+// ciphertext. The nonce and additional data are length-prefixed so that
+// bytes cannot be moved across the boundaries between them.
+func etmTag(macKey, nonce, ciphertext, ad []byte) []byte {
+	var l [8]byte
+	mac := hmac.New(New, macKey)
+	binary.BigEndian.PutUint64(l[:], uint64(len(nonce)))
+	mac.Write(l[:])
+	mac.Write(nonce)
+	binary.BigEndian.PutUint64(l[:], uint64(len(ad)))
+	mac.Write(l[:])
+	mac.Write(ad)
+	mac.Write(ciphertext)
+	return mac.Sum(nil)
+}
+
+// Seal encrypts plaintext under nonce and returns the ciphertext followed
+// by a Size-byte HMAC-SM3 tag over the nonce, ad and ciphertext. A nonce
+// must never be reused with the same keys.
+func (e *EncryptThenMAC) Seal(nonce, plaintext, ad []byte) []byte {
+	out := make([]byte, len(plaintext), len(plaintext)+Size)
+	e.newStream(nonce).XORKeyStream(out, plaintext)
+	return append(out, etmTag(e.macKey, nonce, out, ad)...)
+}
+
+// Open verifies the tag of a message produced by Seal and, only if it is
+// valid, decrypts it. A tag mismatch returns ErrAuth and no plaintext; the
+// ciphertext is not decrypted in that case.
+func (e *EncryptThenMAC) Open(nonce, sealed, ad []byte) ([]byte, error) {
+	if len(sealed) < Size {
+		return nil, ErrAuth
+	}
+	ciphertext, tag := sealed[:len(sealed)-Size], sealed[len(sealed)-Size:]
+	if !hmac.Equal(etmTag(e.macKey, nonce, ciphertext, ad), tag) {
+		return nil, ErrAuth
+	}
+	out := make([]byte, len(ciphertext))
+	e.newStream(nonce).XORKeyStream(out, ciphertext)
+	return out, nil
+}
+
+// OpenAuthenticated checks tag, an HMAC-SM3 under key over nonce, aad and
+// ciphertext, and only if it matches calls decrypt on the ciphertext. A
+// mismatch returns ErrAuth without decrypt ever seeing the ciphertext, so
+// callers cannot decrypt by mistake before authenticating.
+//
+// The tag is computed as by EncryptThenMAC, with the nonce and aad
+// length-prefixed, so OpenAuthenticated accepts the ciphertext and tag
+// halves of a Seal output.
+func OpenAuthenticated(key, nonce, aad, ciphertext, tag []byte, decrypt func(nonce, ciphertext []byte) ([]byte, error)) ([]byte, error) {
+	if !hmac.Equal(etmTag(key, nonce, ciphertext, aad), tag) {
+		return nil, ErrAuth
+	}
+	return decrypt(nonce, ciphertext)
+}
@@ -0,0 +1,112 @@
+package sm3
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+)
+
+func newTestEtM(t *testing.T) *EncryptThenMAC {
+	block, err := aes.NewCipher(bytes.Repeat([]byte{0x11}, 16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctr := func(nonce []byte) cipher.Stream { return cipher.NewCTR(block, nonce) }
+	return NewEncryptThenMAC(ctr, bytes.Repeat([]byte{0x22}, 32))
+}
+
+func TestEncryptThenMACRoundTrip(t *testing.T) {
+	etm := newTestEtM(t)
+	nonce := bytes.Repeat([]byte{0x33}, aes.BlockSize)
+	for _, pt := range [][]byte{nil, []byte("x"), bytes.Repeat([]byte("plaintext "), 50)} {
+		ad := []byte("header")
+		sealed := etm.Seal(nonce, pt, ad)
+		if len(sealed) != len(pt)+etm.Overhead() {
+			t.Fatalf("len(sealed) = %d, want %d", len(sealed), len(pt)+etm.Overhead())
+		}
+		if len(pt) > 0 && bytes.Contains(sealed, pt) {
+			t.Error("ciphertext contains the plaintext")
+		}
+		got, err := etm.Open(nonce, sealed, ad)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, pt) {
+			t.Errorf("Open = %q, want %q", got, pt)
+		}
+	}
+}
+
+func TestEncryptThenMACTamper(t *testing.T) {
+	etm := newTestEtM(t)
+	nonce := bytes.Repeat([]byte{0x33}, aes.BlockSize)
+	ad := []byte("header")
+	sealed := etm.Seal(nonce, []byte("transfer 100 to bob"), ad)
+
+	flipped := bytes.Clone(sealed)
+	flipped[3] ^= 0x01
+	otherNonce := bytes.Clone(nonce)
+	otherNonce[0] ^= 0x01
+
+	for _, tt := range []struct {
+		name          string
+		nonce, in, ad []byte
+	}{
+		{"ciphertext byte", nonce, flipped, ad},
+		{"tag byte", nonce, append(bytes.Clone(sealed[:len(sealed)-1]), sealed[len(sealed)-1]^1), ad},
+		{"additional data", nonce, sealed, []byte("headers")},
+		{"nonce", otherNonce, sealed, ad},
+		{"truncated", nonce, sealed[:Size-1], ad},
+	} {
+		pt, err := etm.Open(tt.nonce, tt.in, tt.ad)
+		if err != ErrAuth || pt != nil {
+			t.Errorf("%s: Open = %q, %v; want nil, ErrAuth", tt.name, pt, err)
+		}
+	}
+}
+
+func TestOpenAuthenticated(t *testing.T) {
+	etm := newTestEtM(t)
+	macKey := bytes.Repeat([]byte{0x22}, 32)
+	nonce := bytes.Repeat([]byte{0x33}, aes.BlockSize)
+	aad := []byte("header")
+	pt := []byte("open after the MAC check")
+	sealed := etm.Seal(nonce, pt, aad)
+	ciphertext, tag := sealed[:len(pt)], sealed[len(pt):]
+
+	calls := 0
+	decrypt := func(nonce, ciphertext []byte) ([]byte, error) {
+		calls++
+		// Decrypting through Open re-checks the tag and exercises the
+		// same stream.
+		return etm.Open(nonce, append(append([]byte(nil), ciphertext...), tag...), aad)
+	}
+
+	got, err := OpenAuthenticated(macKey, nonce, aad, ciphertext, tag, decrypt)
+	if err != nil || !bytes.Equal(got, pt) {
+		t.Fatalf("OpenAuthenticated = %q, %v, want %q", got, err, pt)
+	}
+
+	badTag := append([]byte(nil), tag...)
+	badTag[0] ^= 1
+	for name, open := range map[string]func() ([]byte, error){
+		"wrong tag": func() ([]byte, error) { return OpenAuthenticated(macKey, nonce, aad, ciphertext, badTag, decrypt) },
+		"short tag": func() ([]byte, error) { return OpenAuthenticated(macKey, nonce, aad, ciphertext, tag[:16], decrypt) },
+		"wrong key": func() ([]byte, error) {
+			return OpenAuthenticated([]byte("other key"), nonce, aad, ciphertext, tag, decrypt)
+		},
+		"wrong aad": func() ([]byte, error) {
+			return OpenAuthenticated(macKey, nonce, []byte("headex"), ciphertext, tag, decrypt)
+		},
+		"wrong nonce": func() ([]byte, error) { return OpenAuthenticated(macKey, aad, aad, ciphertext, tag, decrypt) },
+	} {
+		calls = 0
+		if got, err := open(); err != ErrAuth || got != nil {
+			t.Errorf("%s: OpenAuthenticated = %q, %v, want nil, ErrAuth", name, got, err)
+		}
+		if calls != 0 {
+			t.Errorf("%s: decrypt was called before the tag was checked", name)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package sm3_test
+
+import (
+	"fmt"
+
+	"github.com/refraction-networking/utls/sm3"
+)
+
+func ExampleSum() {
+	fmt.Printf("%x\n", sm3.Sum([]byte("abc")))
+	// Output: 66c7f0f462eeedd9d1f2d46bdc10e4e24167c4875cf2f7a2297da02b8f4ba8e0
+}
+
+func ExampleNewHMAC() {
+	// Keys longer than BlockSize are hashed down to Size bytes first.
+	key := make([]byte, 65)
+	for i := range key {
+		key[i] = byte(i + 1)
+	}
+	mac := sm3.NewHMAC(key)
+	mac.Write([]byte("Test With Truncation and Long Key"))
+	fmt.Printf("%x\n", mac.Sum(nil))
+	// Output: d6cb81972bfb7ed033ad583acb5b3b6cd5cbaa2757ba437b5950e6b5e3dbc86f
+}
@@ -0,0 +1,49 @@
+package sm3
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// DefaultReadChunk is the read size used by SumReader and SumFile.
+//
+// Hashing runs at a couple of hundred MB/s, far below what a read call
+// costs per byte, so the chunk size barely matters for throughput: on a
+// warm page cache BenchmarkSumFileSized measures 4 KiB to 1 MiB chunks
+// within a few percent of each other. Larger chunks only cost memory;
+// much smaller ones (below about 1 KiB) start to show the per-call cost.
+const DefaultReadChunk = 32 << 10
+
+// SumReader returns the SM3 checksum of everything read from r until EOF.
+func SumReader(r io.Reader) ([Size]byte, error) {
+	return SumReaderSized(r, DefaultReadChunk)
+}
+
+// SumReaderSized is like SumReader but reads r in chunks of at most chunk
+// bytes, which must be at least 1.
+func SumReaderSized(r io.Reader, chunk int) ([Size]byte, error) {
+	if chunk < 1 {
+		return [Size]byte{}, fmt.Errorf("%w: read chunk of %d bytes", ErrBadLength, chunk)
+	}
+	return SumReaderBuf(r, make([]byte, chunk))
+}
+
+// SumFile returns the SM3 checksum of the contents of the named file.
+func SumFile(path string) ([Size]byte, error) {
+	return SumFileSized(path, DefaultReadChunk)
+}
+
+// SumFileSized is like SumFile but reads the file in chunks of at most
+// chunk bytes, which must be at least 1.
+func SumFileSized(path string, chunk int) ([Size]byte, error) {
+	if chunk < 1 {
+		return [Size]byte{}, fmt.Errorf("%w: read chunk of %d bytes", ErrBadLength, chunk)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return [Size]byte{}, err
+	}
+	defer f.Close()
+	return SumReaderSized(f, chunk)
+}
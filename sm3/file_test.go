@@ -0,0 +1,121 @@
+package sm3
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTempFile(t *testing.T, size int) string {
+	t.Helper()
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i ^ i>>8)
+	}
+	path := filepath.Join(t.TempDir(), "data")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSumFile(t *testing.T) {
+	for _, size := range []int{0, 1, 1000} {
+		path := writeTempFile(t, size)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := SumFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := Sum(data); got != want {
+			t.Errorf("%d bytes: SumFile = %x, want %x", size, got, want)
+		}
+	}
+	if _, err := SumFile(filepath.Join(t.TempDir(), "missing")); !os.IsNotExist(err) {
+		t.Errorf("missing file: err = %v", err)
+	}
+}
+
+func TestSumMmap(t *testing.T) {
+	sizes := []int{0, 1, BlockSize, 1<<20 + 7}
+	if !testing.Short() {
+		sizes = append(sizes, 64<<20+3)
+	}
+	for _, size := range sizes {
+		path := writeTempFile(t, size)
+		want, err := SumFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := SumMmap(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("%d bytes: SumMmap = %x, want %x", size, got, want)
+		}
+	}
+	if _, err := SumMmap(filepath.Join(t.TempDir(), "missing")); !os.IsNotExist(err) {
+		t.Errorf("missing file: err = %v", err)
+	}
+}
+
+func TestSumSized(t *testing.T) {
+	path := writeTempFile(t, 300000)
+	want, err := SumFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, chunk := range []int{1 << 10, BlockSize + 1, 4 << 10, 1 << 20} {
+		got, err := SumFileSized(path, chunk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("chunk %d: SumFileSized = %x, want %x", chunk, got, want)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, chunk := range []int{1, 7, DefaultReadChunk} {
+		got, err := SumReaderSized(bytes.NewReader(data[:5000]), chunk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := Sum(data[:5000]); got != want {
+			t.Errorf("chunk %d: SumReaderSized = %x, want %x", chunk, got, want)
+		}
+	}
+	if _, err := SumReaderSized(bytes.NewReader(data), 0); !errors.Is(err, ErrBadLength) {
+		t.Errorf("chunk 0: err = %v, want %v", err, ErrBadLength)
+	}
+	if _, err := SumFileSized(path, -1); !errors.Is(err, ErrBadLength) {
+		t.Errorf("chunk -1: err = %v, want %v", err, ErrBadLength)
+	}
+}
+
+func BenchmarkSumFileSized(b *testing.B) {
+	data := make([]byte, 16<<20)
+	path := filepath.Join(b.TempDir(), "data")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		b.Fatal(err)
+	}
+	for _, chunk := range []int{4 << 10, 32 << 10, 256 << 10, 1 << 20} {
+		b.Run(fmt.Sprintf("%dK", chunk>>10), func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				if _, err := SumFileSized(path, chunk); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
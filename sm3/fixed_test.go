@@ -0,0 +1,61 @@
+package sm3
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewFixedLength(t *testing.T) {
+	data := make([]byte, 300)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	for _, n := range []int{0, 1, 48, 55, 56, 63, 64, 65, 119, 120, 128, 300} {
+		sum, err := NewFixedLength(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := sum(data[:n]), Sum(data[:n]); got != want {
+			t.Errorf("n = %d: %x, want %x", n, got, want)
+		}
+		// The template is not modified by a call.
+		if got, want := sum(data[len(data)-n:]), Sum(data[len(data)-n:]); got != want {
+			t.Errorf("n = %d, second input: %x, want %x", n, got, want)
+		}
+	}
+}
+
+func TestNewFixedLengthRejectsWrongLength(t *testing.T) {
+	sum, err := NewFixedLength(48)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []int{0, 47, 49} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("no panic for a %d-byte input", n)
+				}
+			}()
+			sum(make([]byte, n))
+		}()
+	}
+	if _, err := NewFixedLength(-1); !errors.Is(err, ErrBadLength) {
+		t.Errorf("NewFixedLength(-1): err = %v", err)
+	}
+}
+
+func BenchmarkFixedLength48(b *testing.B) {
+	sum, _ := NewFixedLength(48)
+	b.SetBytes(48)
+	for i := 0; i < b.N; i++ {
+		sum(buf[:48])
+	}
+}
+
+func BenchmarkSum48(b *testing.B) {
+	b.SetBytes(48)
+	for i := 0; i < b.N; i++ {
+		Sum(buf[:48])
+	}
+}
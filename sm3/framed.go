@@ -0,0 +1,22 @@
+package sm3
+
+// FramedHasher is a running SM3 hash that can report the digest of
+// everything written so far at any frame boundary, without ending the
+// cumulative transcript. It implements hash.Hash.
+type FramedHasher struct {
+	digest
+}
+
+// NewFramedHasher returns a new FramedHasher.
+func NewFramedHasher() *FramedHasher {
+	f := new(FramedHasher)
+	f.Reset()
+	return f
+}
+
+// Mark returns the SM3 digest of all bytes written since the last Reset.
+// Hashing continues from the same state, so later marks cover this prefix.
+func (f *FramedHasher) Mark() [Size]byte {
+	d0 := f.digest
+	return d0.checkSum()
+}
@@ -0,0 +1,30 @@
+package sm3
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFramedHasherMark(t *testing.T) {
+	stream := bytes.Repeat([]byte("frame payload "), 30)
+	offsets := []int{0, 5, 64, 64, 100, 200, len(stream)}
+
+	f := NewFramedHasher()
+	written := 0
+	for _, off := range offsets {
+		f.Write(stream[written:off])
+		written = off
+		if got, want := f.Mark(), Sum(stream[:off]); got != want {
+			t.Errorf("Mark at %d = %x, want %x", off, got, want)
+		}
+	}
+	want := Sum(stream)
+	if got := f.Sum(nil); !bytes.Equal(got, want[:]) {
+		t.Errorf("Sum = %x, want %x", got, want)
+	}
+
+	f.Reset()
+	if got := f.Mark(); got != Sum(nil) {
+		t.Errorf("Mark after Reset = %x", got)
+	}
+}
@@ -0,0 +1,79 @@
+package sm3
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"testing"
+)
+
+// checkDigestInvariants reports a digest that no correct SM3 could produce
+// in practice: all zeros, or the initial chaining value itself, which would
+// mean the state update was skipped or cancelled out (for example by a
+// feed-forward that XORs a word with itself).
+func checkDigestInvariants(sum [Size]byte) error {
+	if sum == ([Size]byte{}) {
+		return fmt.Errorf("all-zero digest")
+	}
+	var iv [Size]byte
+	for i, v := range IV() {
+		binary.BigEndian.PutUint32(iv[i*4:], v)
+	}
+	if sum == iv {
+		return fmt.Errorf("digest equals the IV")
+	}
+	return nil
+}
+
+func FuzzSum(f *testing.F) {
+	for _, g := range golden {
+		f.Add([]byte(g.in), uint16(len(g.in)/2))
+	}
+	f.Add(make([]byte, 1000), uint16(BlockSize))
+	f.Fuzz(func(t *testing.T, data []byte, split uint16) {
+		sum := Sum(data)
+		if err := checkDigestInvariants(sum); err != nil {
+			t.Fatalf("Sum(%x): %v", data, err)
+		}
+		if safe, err := SafeSum(data); err != nil || safe != sum {
+			t.Fatalf("SafeSum(%x) = %x, %v, want %x, nil", data, safe, err, sum)
+		}
+		cut := int(split) % (len(data) + 1)
+		h := New()
+		h.Write(data[:cut])
+		h.Write(data[cut:])
+		if got := h.Sum(nil); !bytes.Equal(got, sum[:]) {
+			t.Fatalf("split at %d: %x, want %x", cut, got, sum)
+		}
+	})
+}
+
+// TestDigestInvariantsCatchBrokenBlock checks that the invariant would
+// catch a compression function whose feed-forward cancels the state.
+func TestDigestInvariantsCatchBrokenBlock(t *testing.T) {
+	broken := func(h [8]uint32, blk []byte) [8]uint32 {
+		h = Compress(h, blk)
+		for i := range h {
+			h[i] ^= h[i] // the bug: XOR with itself instead of the old state
+		}
+		return h
+	}
+	for _, in := range []string{"", "abc", string(buf[:200])} {
+		padded := padMessage([]byte(in))
+		h := IV()
+		for len(padded) > 0 {
+			h = broken(h, padded[:BlockSize])
+			padded = padded[BlockSize:]
+		}
+		var sum [Size]byte
+		for i, v := range h {
+			binary.BigEndian.PutUint32(sum[i*4:], v)
+		}
+		if checkDigestInvariants(sum) == nil {
+			t.Errorf("%d-byte input: broken compression not detected", len(in))
+		}
+		if err := checkDigestInvariants(Sum([]byte(in))); err != nil {
+			t.Errorf("%d-byte input: correct digest flagged: %v", len(in), err)
+		}
+	}
+}
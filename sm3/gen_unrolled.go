@@ -0,0 +1,92 @@
+//go:build ignore
+
+// Generate sm3block_unrolled.go, the SM3 compression function with all 64
+// rounds written out. Run with go generate.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+)
+
+func main() {
+	var b bytes.Buffer
+	b.WriteString(`// Code generated by gen_unrolled.go; DO NOT EDIT.
+
+package sm3
+
+import "math/bits"
+
+// blockUnrolled is block with the 64 rounds written out. Instead of
+// shifting the eight working variables after each round, the rounds rotate
+// which variable plays which role, so no values move between rounds.
+func blockUnrolled(dig *digest, p []byte) {
+	var w [68]uint32
+	h0, h1, h2, h3, h4, h5, h6, h7 := dig.h[0], dig.h[1], dig.h[2], dig.h[3], dig.h[4], dig.h[5], dig.h[6], dig.h[7]
+	for len(p) >= BlockSize {
+		loadWords((*[16]uint32)(w[:16]), (*[BlockSize]byte)(p))
+		for i := 16; i < 68; i++ {
+			w[i] = p1(w[i-16]^w[i-9]^bits.RotateLeft32(w[i-3], 15)) ^ bits.RotateLeft32(w[i-13], 7) ^ w[i-6]
+		}
+
+		a, b, c, d, e, f, g, h := h0, h1, h2, h3, h4, h5, h6, h7
+		var a12, ss1, tt1, tt2 uint32
+`)
+	v := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	for j := 0; j < 64; j++ {
+		a, bb, c, d, e, f, g, h := v[0], v[1], v[2], v[3], v[4], v[5], v[6], v[7]
+		ff := fmt.Sprintf("(%s ^ %s ^ %s)", a, bb, c)
+		gg := fmt.Sprintf("(%s ^ %s ^ %s)", e, f, g)
+		if j >= 16 {
+			ff = fmt.Sprintf("((%[1]s & %[2]s) | (%[1]s & %[3]s) | (%[2]s & %[3]s))", a, bb, c)
+			gg = fmt.Sprintf("((%[1]s & %[2]s) | (^%[1]s & %[3]s))", e, f, g)
+		}
+		fmt.Fprintf(&b, "\n\t\t// Round %d.\n", j)
+		fmt.Fprintf(&b, "\t\ta12 = bits.RotateLeft32(%s, 12)\n", a)
+		fmt.Fprintf(&b, "\t\tss1 = bits.RotateLeft32(a12+%s+%#08x, 7)\n", e, tj(j))
+		fmt.Fprintf(&b, "\t\ttt1 = %s + %s + (ss1 ^ a12) + (w[%d] ^ w[%d])\n", ff, d, j, j+4)
+		fmt.Fprintf(&b, "\t\ttt2 = %s + %s + ss1 + w[%d]\n", gg, h, j)
+		fmt.Fprintf(&b, "\t\t%s = bits.RotateLeft32(%s, 9)\n", bb, bb)
+		fmt.Fprintf(&b, "\t\t%s = bits.RotateLeft32(%s, 19)\n", f, f)
+		fmt.Fprintf(&b, "\t\t%s = tt1\n", d)
+		fmt.Fprintf(&b, "\t\t%s = p0(tt2)\n", h)
+		// The new A and E were stored in D's and H's variables.
+		v = []string{d, a, bb, c, h, e, f, g}
+	}
+	b.WriteString(`
+		h0 ^= a
+		h1 ^= b
+		h2 ^= c
+		h3 ^= d
+		h4 ^= e
+		h5 ^= f
+		h6 ^= g
+		h7 ^= h
+
+		p = p[BlockSize:]
+	}
+	dig.h[0], dig.h[1], dig.h[2], dig.h[3], dig.h[4], dig.h[5], dig.h[6], dig.h[7] = h0, h1, h2, h3, h4, h5, h6, h7
+}
+`)
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("sm3block_unrolled.go", src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// tj returns T_j rotated left by j mod 32, as in the tj table.
+func tj(j int) uint32 {
+	t := uint32(0x79cc4519)
+	if j >= 16 {
+		t = 0x7a879d8a
+	}
+	n := j % 32
+	return t<<n | t>>(32-n)
+}
@@ -0,0 +1,26 @@
+package sm3
+
+import (
+	"compress/gzip"
+	"io"
+)
+
+// SumGzip decompresses the gzip stream read from r and returns the SM3
+// checksum of the decompressed data, in a single streaming pass. It checks
+// the content rather than its compressed form, so it can be compared with
+// the digest of the original file. Errors from r and from gzip, including
+// a corrupt stream or a CRC or size mismatch in the trailer, are returned.
+// Concatenated gzip members are hashed as one stream, as gunzip does.
+func SumGzip(r io.Reader) (plainDigest [Size]byte, err error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return plainDigest, err
+	}
+	defer zr.Close()
+	var d digest
+	d.Reset()
+	if _, err := io.Copy(&d, zr); err != nil {
+		return plainDigest, err
+	}
+	return d.checkSum(), nil
+}
@@ -0,0 +1,54 @@
+package sm3
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"testing"
+)
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	zw := gzip.NewWriter(&b)
+	if _, err := zw.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func TestSumGzip(t *testing.T) {
+	for _, n := range []int{0, 100, 70000} {
+		data := bytes.Repeat([]byte("compressible "), n/13+1)[:n]
+		got, err := SumGzip(bytes.NewReader(gzipBytes(t, data)))
+		if err != nil || got != Sum(data) {
+			t.Errorf("%d bytes: SumGzip = %x, %v; want %x", n, got, err, Sum(data))
+		}
+	}
+
+	a, b := []byte("first member, "), []byte("second member")
+	two := append(gzipBytes(t, a), gzipBytes(t, b)...)
+	if got, err := SumGzip(bytes.NewReader(two)); err != nil || got != Sum(append(a, b...)) {
+		t.Errorf("two members: SumGzip = %x, %v", got, err)
+	}
+}
+
+func TestSumGzipCorrupt(t *testing.T) {
+	z := gzipBytes(t, bytes.Repeat([]byte("payload "), 100))
+
+	crc := bytes.Clone(z)
+	crc[len(crc)-8] ^= 1 // CRC-32 in the trailer
+	if _, err := SumGzip(bytes.NewReader(crc)); !errors.Is(err, gzip.ErrChecksum) {
+		t.Errorf("bad CRC: err = %v, want %v", err, gzip.ErrChecksum)
+	}
+	if _, err := SumGzip(bytes.NewReader(z[:len(z)-4])); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("truncated stream: err = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+	if _, err := SumGzip(bytes.NewReader([]byte("not gzip at all"))); !errors.Is(err, gzip.ErrHeader) {
+		t.Errorf("not gzip: err = %v, want %v", err, gzip.ErrHeader)
+	}
+}
@@ -0,0 +1,66 @@
+package sm3
+
+import (
+	"net/textproto"
+	"strings"
+)
+
+// CanonicalHeaderDigest returns the SM3 checksum of the canonical form of
+// the header fields named in fields, the basis of an HTTP message
+// signature. An http.Header can be passed as headers directly.
+//
+// The canonical form has one line per entry of fields, in the order given,
+// each ending in "\n":
+//
+//   - a field that is present becomes its lowercased name, ": ", and its
+//     values joined by ", " in the order they appear, each with leading and
+//     trailing whitespace trimmed;
+//   - a field that is absent becomes its lowercased name alone, with no
+//     colon, so it is distinct from a field whose value is empty.
+//
+// Names are matched case-insensitively. Any CR or LF left inside a value
+// is replaced by a space, so a value cannot forge another line. A name
+// listed twice appears twice.
+func CanonicalHeaderDigest(headers map[string][]string, fields []string) [Size]byte {
+	return Sum([]byte(canonicalHeaders(headers, fields)))
+}
+
+// canonicalHeaders returns the canonical form hashed by
+// CanonicalHeaderDigest.
+func canonicalHeaders(headers map[string][]string, fields []string) string {
+	var b strings.Builder
+	for _, name := range fields {
+		b.WriteString(strings.ToLower(name))
+		if values, ok := headerValues(headers, name); ok {
+			b.WriteString(": ")
+			for i, v := range values {
+				if i > 0 {
+					b.WriteString(", ")
+				}
+				b.WriteString(strings.Map(func(r rune) rune {
+					if r == '\r' || r == '\n' {
+						return ' '
+					}
+					return r
+				}, textproto.TrimString(v)))
+			}
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// headerValues looks name up in headers under its canonical MIME form, as
+// http.Header stores it, and failing that by a case-insensitive scan, for
+// maps built by hand.
+func headerValues(headers map[string][]string, name string) ([]string, bool) {
+	if v, ok := headers[textproto.CanonicalMIMEHeaderKey(name)]; ok {
+		return v, true
+	}
+	for k, v := range headers {
+		if strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+	return nil, false
+}
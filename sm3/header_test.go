@@ -0,0 +1,50 @@
+package sm3
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestCanonicalHeaderDigest(t *testing.T) {
+	h := http.Header{}
+	h.Set("Host", "example.com")
+	h.Set("Date", " Tue, 07 Jun 2014 20:51:35 GMT ")
+	h.Add("Cache-Control", "max-age=60")
+	h.Add("Cache-Control", "must-revalidate")
+	h.Set("X-Empty", "")
+
+	fields := []string{"host", "Date", "CACHE-CONTROL", "x-empty", "x-missing"}
+	const want = "host: example.com\n" +
+		"date: Tue, 07 Jun 2014 20:51:35 GMT\n" +
+		"cache-control: max-age=60, must-revalidate\n" +
+		"x-empty: \n" +
+		"x-missing\n"
+	if got := canonicalHeaders(h, fields); got != want {
+		t.Errorf("canonical form = %q, want %q", got, want)
+	}
+	if got := CanonicalHeaderDigest(h, fields); got != Sum([]byte(want)) {
+		t.Errorf("CanonicalHeaderDigest = %x, want %x", got, Sum([]byte(want)))
+	}
+
+	// Field order matters; name case does not, in fields or in the map.
+	if CanonicalHeaderDigest(h, []string{"date", "host"}) == CanonicalHeaderDigest(h, []string{"host", "date"}) {
+		t.Error("reordering fields did not change the digest")
+	}
+	raw := map[string][]string{"host": {"example.com"}, "DATE": {"Tue, 07 Jun 2014 20:51:35 GMT"}}
+	if CanonicalHeaderDigest(raw, []string{"Host", "date"}) != CanonicalHeaderDigest(h, []string{"HOST", "Date"}) {
+		t.Error("header name case changed the digest")
+	}
+
+	// Value order within a multi-valued field matters.
+	swapped := h.Clone()
+	swapped["Cache-Control"] = []string{"must-revalidate", "max-age=60"}
+	if CanonicalHeaderDigest(swapped, fields) == CanonicalHeaderDigest(h, fields) {
+		t.Error("reordering values did not change the digest")
+	}
+
+	// A newline inside a value cannot forge another field's line.
+	forged := map[string][]string{"Host": {"example.com\ndate: then"}}
+	if got := canonicalHeaders(forged, []string{"host"}); got != "host: example.com date: then\n" {
+		t.Errorf("value with a newline: canonical form = %q", got)
+	}
+}
@@ -0,0 +1,113 @@
+package sm3
+
+import (
+	"crypto/hmac"
+	"fmt"
+	"hash"
+)
+
+// MaxHKDFLength is the most output HKDF-SM3 can produce from one
+// pseudorandom key, 255 blocks of Size bytes (RFC 5869 section 2.3).
+const MaxHKDFLength = 255 * Size
+
+// HKDFExtract returns the RFC 5869 pseudorandom key HMAC-SM3(salt, secret).
+// A nil salt is treated as Size zero bytes.
+func HKDFExtract(secret, salt []byte) []byte {
+	return HKDFExtractWith(New, secret, salt)
+}
+
+// HKDFExpand returns length bytes of HKDF-SM3 output keyed by the
+// pseudorandom key prk and bound to info. It returns an error wrapping
+// ErrOutputTooLong if length exceeds MaxHKDFLength.
+func HKDFExpand(prk, info []byte, length int) ([]byte, error) {
+	return HKDFExpandWith(New, prk, info, length)
+}
+
+// HKDF runs HKDFExtract followed by HKDFExpand.
+func HKDF(secret, salt, info []byte, length int) ([]byte, error) {
+	return HKDFExpand(HKDFExtract(secret, salt), info, length)
+}
+
+// HKDFExtractWith is HKDFExtract with HMAC over the hash built by newHash
+// in place of HMAC-SM3. A nil salt is treated as that hash's Size zero
+// bytes.
+func HKDFExtractWith(newHash func() hash.Hash, secret, salt []byte) []byte {
+	if salt == nil {
+		salt = make([]byte, newHash().Size())
+	}
+	mac := hmac.New(newHash, salt)
+	mac.Write(secret)
+	return mac.Sum(nil)
+}
+
+// HKDFExpandWith is HKDFExpand with HMAC over the hash built by newHash in
+// place of HMAC-SM3. Its output is limited to 255 digests of that hash.
+func HKDFExpandWith(newHash func() hash.Hash, prk, info []byte, length int) ([]byte, error) {
+	mac := hmac.New(newHash, prk)
+	size := mac.Size()
+	if limit := 255 * size; length < 0 || length > limit {
+		return nil, fmt.Errorf("%w: HKDF length %d, maximum %d", ErrOutputTooLong, length, limit)
+	}
+	out := make([]byte, 0, length+size)
+	var prev []byte
+	for counter := byte(1); len(out) < length; counter++ {
+		mac.Reset()
+		mac.Write(prev)
+		mac.Write(info)
+		mac.Write([]byte{counter})
+		out = mac.Sum(out)
+		prev = out[len(out)-size:]
+	}
+	return out[:length], nil
+}
+
+// HKDFWith runs HKDFExtractWith followed by HKDFExpandWith.
+func HKDFWith(newHash func() hash.Hash, secret, salt, info []byte, length int) ([]byte, error) {
+	return HKDFExpandWith(newHash, HKDFExtractWith(newHash, secret, salt), info, length)
+}
+
+// DeriveKey turns a shared secret into keyLen bytes of symmetric key
+// material with HKDF-SM3, using salt for extraction and info to bind the
+// key to its purpose.
+//
+// DeriveKey does not panic: if keyLen is not in [1, MaxHKDFLength] it
+// returns nil. Use DeriveKeyErr to get an error instead.
+func DeriveKey(secret, salt, info []byte, keyLen int) []byte {
+	key, err := DeriveKeyErr(secret, salt, info, keyLen)
+	if err != nil {
+		return nil
+	}
+	return key
+}
+
+// DeriveKeyErr is like DeriveKey but reports an out-of-range keyLen as an
+// error wrapping ErrBadLength or ErrOutputTooLong.
+func DeriveKeyErr(secret, salt, info []byte, keyLen int) ([]byte, error) {
+	if keyLen < 1 {
+		return nil, fmt.Errorf("%w: key length %d", ErrBadLength, keyLen)
+	}
+	return HKDF(secret, salt, info, keyLen)
+}
+
+// DeriveKeys runs HKDF-Extract once on secret and salt and then one
+// HKDF-Expand per entry of labels, using the label as info and its value
+// as the output length, as in deriving an encryption key, a MAC key and an
+// IV together. Each output equals HKDF(secret, salt, []byte(label), n).
+//
+// It returns an error wrapping ErrBadLength or ErrOutputTooLong if any
+// length is outside [1, MaxHKDFLength], and no keys.
+func DeriveKeys(secret, salt []byte, labels map[string]int) (map[string][]byte, error) {
+	prk := HKDFExtract(secret, salt)
+	keys := make(map[string][]byte, len(labels))
+	for label, n := range labels {
+		if n < 1 {
+			return nil, fmt.Errorf("%w: key length %d for label %q", ErrBadLength, n, label)
+		}
+		key, err := HKDFExpand(prk, []byte(label), n)
+		if err != nil {
+			return nil, fmt.Errorf("label %q: %w", label, err)
+		}
+		keys[label] = key
+	}
+	return keys, nil
+}
@@ -0,0 +1,153 @@
+package sm3
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"testing"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+func TestHKDFFirstBlock(t *testing.T) {
+	secret, salt, info := []byte("input key material"), []byte("salt"), []byte("info")
+
+	mac := hmac.New(New, salt)
+	mac.Write(secret)
+	prk := mac.Sum(nil)
+	if got := HKDFExtract(secret, salt); !bytes.Equal(got, prk) {
+		t.Errorf("HKDFExtract = %x, want %x", got, prk)
+	}
+
+	mac = hmac.New(New, prk)
+	mac.Write(info)
+	mac.Write([]byte{1})
+	t1 := mac.Sum(nil)
+	mac.Reset()
+	mac.Write(t1)
+	mac.Write(info)
+	mac.Write([]byte{2})
+	t2 := mac.Sum(nil)
+
+	okm, err := HKDF(secret, salt, info, 2*Size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(okm, append(t1, t2...)) {
+		t.Errorf("HKDF = %x, want T(1) || T(2) = %x%x", okm, t1, t2)
+	}
+
+	if got, want := HKDFExtract(secret, nil), HKDFExtract(secret, make([]byte, Size)); !bytes.Equal(got, want) {
+		t.Error("nil salt is not treated as zeros")
+	}
+}
+
+func TestDeriveKey(t *testing.T) {
+	secret, salt, info := []byte("shared secret"), []byte("salt"), []byte("enc key")
+	long := DeriveKey(secret, salt, info, 3*Size+7)
+	for _, n := range []int{16, 32, 3*Size + 7} {
+		key := DeriveKey(secret, salt, info, n)
+		if len(key) != n {
+			t.Fatalf("len(DeriveKey(%d)) = %d", n, len(key))
+		}
+		if !bytes.Equal(key, long[:n]) {
+			t.Errorf("DeriveKey(%d) is not a prefix of the longer output", n)
+		}
+	}
+	if bytes.Equal(DeriveKey(secret, salt, []byte("mac key"), 32), long[:32]) {
+		t.Error("different info produced the same key")
+	}
+	if bytes.Equal(DeriveKey(secret, []byte("pepper"), info, 32), long[:32]) {
+		t.Error("different salt produced the same key")
+	}
+}
+
+func TestDeriveKeyLimits(t *testing.T) {
+	secret := []byte("shared secret")
+	if key := DeriveKey(secret, nil, nil, MaxHKDFLength); len(key) != MaxHKDFLength {
+		t.Errorf("len(DeriveKey(MaxHKDFLength)) = %d", len(key))
+	}
+	if key := DeriveKey(secret, nil, nil, MaxHKDFLength+1); key != nil {
+		t.Error("DeriveKey past the maximum returned a key")
+	}
+	if _, err := DeriveKeyErr(secret, nil, nil, MaxHKDFLength+1); !errors.Is(err, ErrOutputTooLong) {
+		t.Errorf("DeriveKeyErr past the maximum: err = %v", err)
+	}
+	if _, err := DeriveKeyErr(secret, nil, nil, 0); !errors.Is(err, ErrBadLength) {
+		t.Errorf("DeriveKeyErr(0): err = %v", err)
+	}
+}
+
+func TestDeriveKeys(t *testing.T) {
+	secret, salt := []byte("shared secret"), []byte("salt")
+	labels := map[string]int{"enc key": 16, "mac key": 32, "iv": 12, "long": 2*Size + 1}
+	keys, err := DeriveKeys(secret, salt, labels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != len(labels) {
+		t.Fatalf("got %d keys, want %d", len(keys), len(labels))
+	}
+	for label, n := range labels {
+		want, err := HKDF(secret, salt, []byte(label), n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(keys[label], want) {
+			t.Errorf("key %q = %x, want HKDF output %x", label, keys[label], want)
+		}
+	}
+
+	if _, err := DeriveKeys(secret, salt, map[string]int{"ok": 16, "empty": 0}); !errors.Is(err, ErrBadLength) {
+		t.Errorf("zero length: err = %v, want ErrBadLength", err)
+	}
+	if _, err := DeriveKeys(secret, salt, map[string]int{"huge": MaxHKDFLength + 1}); !errors.Is(err, ErrOutputTooLong) {
+		t.Errorf("oversized length: err = %v, want ErrOutputTooLong", err)
+	}
+	if keys, err := DeriveKeys(secret, salt, nil); err != nil || len(keys) != 0 {
+		t.Errorf("no labels = %v, %v", keys, err)
+	}
+}
+
+func TestHKDFWith(t *testing.T) {
+	// RFC 5869 Appendix A.1, HKDF-SHA-256, run through the pluggable PRF.
+	ikm := bytes.Repeat([]byte{0x0b}, 22)
+	salt, _ := hex.DecodeString("000102030405060708090a0b0c")
+	info, _ := hex.DecodeString("f0f1f2f3f4f5f6f7f8f9")
+	prk := HKDFExtractWith(sha256.New, ikm, salt)
+	if want := "077709362c2e32df0ddc3f0dc47bba6390b6c73bb50f9c3122ec844ad7c2b3e5"; hex.EncodeToString(prk) != want {
+		t.Errorf("PRK = %x, want %s", prk, want)
+	}
+	okm, err := HKDFWith(sha256.New, ikm, salt, info, 42)
+	if want := "3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf34007208d5b887185865"; err != nil || hex.EncodeToString(okm) != want {
+		t.Errorf("OKM = %x, %v; want %s", okm, err, want)
+	}
+	if _, err := HKDFExpandWith(sha256.New, prk, nil, 255*sha256.Size+1); !errors.Is(err, ErrOutputTooLong) {
+		t.Errorf("HKDFExpandWith past 255 SHA-256 blocks: err = %v", err)
+	}
+
+	secret := []byte("shared secret")
+	got, _ := HKDFWith(New, secret, nil, info, 80)
+	if want, _ := HKDF(secret, nil, info, 80); !bytes.Equal(got, want) {
+		t.Error("HKDFWith(New) differs from HKDF")
+	}
+}
+
+func TestHKDFMatchesXCrypto(t *testing.T) {
+	secret, salt, info := []byte("input key material"), []byte("salt"), []byte("info")
+	for _, n := range []int{1, Size, 3*Size + 5, MaxHKDFLength} {
+		want := make([]byte, n)
+		if _, err := io.ReadFull(hkdf.New(Hash(), secret, salt, info), want); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := HKDF(secret, salt, info, n); err != nil || !bytes.Equal(got, want) {
+			t.Errorf("%d bytes: HKDF differs from x/crypto/hkdf with Hash()", n)
+		}
+	}
+	if got, want := HKDFExtract(secret, nil), hkdf.Extract(Hash(), secret, nil); !bytes.Equal(got, want) {
+		t.Errorf("HKDFExtract(nil salt) = %x, x/crypto/hkdf gives %x", got, want)
+	}
+}
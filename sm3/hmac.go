@@ -0,0 +1,36 @@
+package sm3
+
+import (
+	"crypto/hmac"
+	"hash"
+)
+
+// HMAC computes HMAC-SM3 message authentication codes. It implements
+// hash.Hash and adds Verify, which should be used to check received tags.
+type HMAC struct {
+	mac hash.Hash
+}
+
+// NewHMAC returns an HMAC-SM3 keyed with key.
+func NewHMAC(key []byte) *HMAC {
+	return &HMAC{mac: hmac.New(New, key)}
+}
+
+func (m *HMAC) Write(p []byte) (int, error) { return m.mac.Write(p) }
+
+// Sum appends the current tag to b. It does not change the underlying state.
+func (m *HMAC) Sum(b []byte) []byte { return m.mac.Sum(b) }
+
+func (m *HMAC) Reset() { m.mac.Reset() }
+
+func (m *HMAC) Size() int { return Size }
+
+func (m *HMAC) BlockSize() int { return BlockSize }
+
+// Verify reports whether tag is the HMAC of the data written so far. The
+// comparison takes time independent of the contents of tag, and a tag of the
+// wrong length is rejected rather than causing a panic.
+func (m *HMAC) Verify(tag []byte) bool {
+	var sum [Size]byte
+	return hmac.Equal(m.mac.Sum(sum[:0]), tag)
+}
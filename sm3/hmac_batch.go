@@ -0,0 +1,37 @@
+package sm3
+
+import (
+	"runtime"
+	"sync"
+)
+
+// batchHMACPerWorker is the fewest messages BatchHMAC hands to a goroutine;
+// below that, starting one costs more than the tags it computes.
+const batchHMACPerWorker = 64
+
+// BatchHMAC returns the HMAC-SM3 tag of each of msgs under key, in order.
+// The key is padded and absorbed once, as in NewHMACReusable, instead of
+// costing two extra compressions per message, and large batches are spread
+// over up to GOMAXPROCS goroutines.
+func BatchHMAC(key []byte, msgs [][]byte) [][Size]byte {
+	tags := make([][Size]byte, len(msgs))
+	keyed := NewHMACReusable(key)
+	workers := max(1, min(runtime.GOMAXPROCS(0), len(msgs)/batchHMACPerWorker))
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		first, last := w*len(msgs)/workers, (w+1)*len(msgs)/workers
+		wg.Add(1)
+		go func(first, last int) {
+			defer wg.Done()
+			m := *keyed
+			for i := first; i < last; i++ {
+				m.Reset()
+				m.Write(msgs[i])
+				tags[i] = m.tag()
+			}
+		}(first, last)
+	}
+	wg.Wait()
+	return tags
+}
@@ -0,0 +1,58 @@
+package sm3
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"testing"
+)
+
+func TestBatchHMAC(t *testing.T) {
+	keys := [][]byte{nil, []byte("key"), bytes.Repeat([]byte{0xa5}, BlockSize+1)}
+	for _, n := range []int{0, 1, 3, 10 * batchHMACPerWorker} {
+		msgs := make([][]byte, n)
+		for i := range msgs {
+			msgs[i] = treeTestData(i % (2*BlockSize + 3))
+		}
+		for _, key := range keys {
+			tags := BatchHMAC(key, msgs)
+			if len(tags) != n {
+				t.Fatalf("%d messages: got %d tags", n, len(tags))
+			}
+			for i, msg := range msgs {
+				mac := hmac.New(New, key)
+				mac.Write(msg)
+				if want := mac.Sum(nil); !bytes.Equal(tags[i][:], want) {
+					t.Fatalf("%d messages, key %x: tag %d = %x, want %x", n, key, i, tags[i], want)
+				}
+			}
+		}
+	}
+}
+
+func benchmarkBatchMessages() [][]byte {
+	msgs := make([][]byte, 4096)
+	for i := range msgs {
+		msgs[i] = buf[:64]
+	}
+	return msgs
+}
+
+func BenchmarkBatchHMAC(b *testing.B) {
+	key, msgs := []byte("benchmark key"), benchmarkBatchMessages()
+	b.SetBytes(int64(len(msgs) * 64))
+	for i := 0; i < b.N; i++ {
+		BatchHMAC(key, msgs)
+	}
+}
+
+func BenchmarkBatchHMACNaive(b *testing.B) {
+	key, msgs := []byte("benchmark key"), benchmarkBatchMessages()
+	b.SetBytes(int64(len(msgs) * 64))
+	for i := 0; i < b.N; i++ {
+		for _, msg := range msgs {
+			m := NewHMAC(key)
+			m.Write(msg)
+			m.Sum(nil)
+		}
+	}
+}
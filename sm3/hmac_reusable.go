@@ -0,0 +1,64 @@
+package sm3
+
+import "crypto/hmac"
+
+// ReusableHMAC computes HMAC-SM3 like HMAC, but keeps the SM3 states
+// reached after absorbing the padded key, so Reset restores them instead of
+// rehashing the key. Reusing one ReusableHMAC for many messages under the
+// same key saves two compressions per message. It implements hash.Hash.
+type ReusableHMAC struct {
+	inner, outer digest // states after key^ipad and key^opad
+	cur          digest
+}
+
+// NewHMACReusable returns a ReusableHMAC keyed with key.
+func NewHMACReusable(key []byte) *ReusableHMAC {
+	if len(key) > BlockSize {
+		k := Sum(key)
+		key = k[:]
+	}
+	var ipad, opad [BlockSize]byte
+	copy(ipad[:], key)
+	copy(opad[:], key)
+	for i := range ipad {
+		ipad[i] ^= 0x36
+		opad[i] ^= 0x5c
+	}
+	m := new(ReusableHMAC)
+	m.inner.Reset()
+	m.inner.Write(ipad[:])
+	m.outer.Reset()
+	m.outer.Write(opad[:])
+	m.cur = m.inner
+	return m
+}
+
+func (m *ReusableHMAC) Write(p []byte) (int, error) { return m.cur.Write(p) }
+
+// Sum appends the current tag to b. It does not change the underlying state.
+func (m *ReusableHMAC) Sum(b []byte) []byte {
+	tag := m.tag()
+	return append(b, tag[:]...)
+}
+
+func (m *ReusableHMAC) tag() [Size]byte {
+	in := m.cur
+	sum := in.checkSum()
+	out := m.outer
+	out.Write(sum[:])
+	return out.checkSum()
+}
+
+// Reset restores the state after the key, ready for a new message.
+func (m *ReusableHMAC) Reset() { m.cur = m.inner }
+
+func (m *ReusableHMAC) Size() int { return Size }
+
+func (m *ReusableHMAC) BlockSize() int { return BlockSize }
+
+// Verify reports whether tag is the HMAC of the data written so far, with
+// the same constant-time comparison as HMAC.Verify.
+func (m *ReusableHMAC) Verify(tag []byte) bool {
+	sum := m.tag()
+	return hmac.Equal(sum[:], tag)
+}
@@ -0,0 +1,54 @@
+package sm3
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"testing"
+)
+
+func TestHMACReusableMatchesHMAC(t *testing.T) {
+	for _, keyLen := range []int{0, 4, BlockSize - 1, BlockSize, BlockSize + 1, 200} {
+		key := bytes.Repeat([]byte{byte(keyLen)}, keyLen)
+		r := NewHMACReusable(key)
+		for _, msg := range []string{"", "what do ya want for nothing?", string(buf[:300])} {
+			want := NewHMAC(key)
+			want.Write([]byte(msg))
+			r.Reset()
+			r.Write([]byte(msg))
+			tag := r.Sum(nil)
+			if wantTag := want.Sum(nil); !bytes.Equal(tag, wantTag) {
+				t.Errorf("key %d bytes, message %d bytes: tag = %x, want %x", keyLen, len(msg), tag, wantTag)
+			}
+			if !r.Verify(tag) {
+				t.Errorf("key %d bytes: Verify rejected its own tag", keyLen)
+			}
+			tag[0] ^= 1
+			if r.Verify(tag) {
+				t.Errorf("key %d bytes: Verify accepted a forged tag", keyLen)
+			}
+		}
+	}
+}
+
+func benchmarkHMACMessages(b *testing.B, m interface {
+	Reset()
+	Write([]byte) (int, error)
+	Sum([]byte) []byte
+}) {
+	sum := make([]byte, 0, Size)
+	b.SetBytes(64)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		m.Reset()
+		m.Write(buf[:64])
+		m.Sum(sum[:0])
+	}
+}
+
+func BenchmarkHMACStdlib(b *testing.B) {
+	benchmarkHMACMessages(b, hmac.New(New, []byte("benchmark key")))
+}
+
+func BenchmarkHMACReusable(b *testing.B) {
+	benchmarkHMACMessages(b, NewHMACReusable([]byte("benchmark key")))
+}
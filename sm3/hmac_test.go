@@ -0,0 +1,84 @@
+package sm3
+
+import (
+	"encoding/hex"
+	"hash"
+	"testing"
+)
+
+func TestHMACVerify(t *testing.T) {
+	key := []byte("hmac key")
+	msg := []byte("attack at dawn")
+
+	m := NewHMAC(key)
+	m.Write(msg)
+	tag := m.Sum(nil)
+
+	v := NewHMAC(key)
+	v.Write(msg)
+	if !v.Verify(tag) {
+		t.Error("valid tag rejected")
+	}
+	// Verify does not consume the state.
+	if !v.Verify(tag) {
+		t.Error("valid tag rejected on second Verify")
+	}
+
+	forged := append([]byte(nil), tag...)
+	forged[len(forged)-1] ^= 1
+	if v.Verify(forged) {
+		t.Error("forged tag accepted")
+	}
+	if v.Verify(tag[:Size-1]) || v.Verify(append(tag, 0)) || v.Verify(nil) {
+		t.Error("wrong-length tag accepted")
+	}
+
+	other := NewHMAC([]byte("other key"))
+	other.Write(msg)
+	if other.Verify(tag) {
+		t.Error("tag accepted under a different key")
+	}
+
+	v.Reset()
+	v.Write([]byte("attack at dusk"))
+	if v.Verify(tag) {
+		t.Error("tag accepted for a different message")
+	}
+}
+
+func TestHMACKnownAnswer(t *testing.T) {
+	// HMAC-SM3 with the RFC 4231 test case 2 inputs.
+	m := NewHMAC([]byte("Jefe"))
+	m.Write([]byte("what do ya want for nothing?"))
+	const want = "2e87f1d16862e6d964b50a5200bf2b10b764faa9680a296a2405f24bec39f882"
+	if got := hex.EncodeToString(m.Sum(nil)); got != want {
+		t.Errorf("HMAC-SM3 = %s, want %s", got, want)
+	}
+}
+
+func TestHMACKeyLengths(t *testing.T) {
+	// Keys 0x01 0x02 ... of lengths around the 64-byte block size: shorter
+	// keys are zero-padded, a 64-byte key is used as is, and longer keys
+	// are first hashed to 32 bytes. Reference tags from OpenSSL.
+	msg := []byte("Test With Truncation and Long Key")
+	for _, tt := range []struct {
+		keyLen int
+		want   string
+	}{
+		{63, "3ba62cd7b5096093708bc6d1c5efd38fead21a829f2fb8d01a0f6a3f79db37db"},
+		{64, "7bcd8447173987da66e2d83d0f7062a118820ed19ef23c030f6358fdd4947f8c"},
+		{65, "d6cb81972bfb7ed033ad583acb5b3b6cd5cbaa2757ba437b5950e6b5e3dbc86f"},
+		{100, "ef9a44163a0701395192c5cf9349edc470311a8d6ed507defa6679d00277656b"},
+	} {
+		key := make([]byte, tt.keyLen)
+		for i := range key {
+			key[i] = byte(i + 1)
+		}
+		for name, m := range map[string]hash.Hash{"NewHMAC": NewHMAC(key), "NewHMACReusable": NewHMACReusable(key)} {
+			m.Write(msg)
+			if got := hex.EncodeToString(m.Sum(nil)); got != tt.want {
+				t.Errorf("%s with a %d-byte key = %s, want %s", name, tt.keyLen, got, tt.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package sm3
+
+import "encoding/binary"
+
+// IdempotencyKey returns a stable key identifying an HTTP request with the
+// given method, path and body, for deduplicating retries. It is SumHex of
+// the three fields, each preceded by its length as a big-endian uint64, so
+// that no two distinct triples share an encoding: ("GET", "/a", "b") and
+// ("GET", "/ab", "") get different keys.
+func IdempotencyKey(method, path string, body []byte) string {
+	buf := make([]byte, 0, 24+len(method)+len(path)+len(body))
+	buf = binary.BigEndian.AppendUint64(buf, uint64(len(method)))
+	buf = append(buf, method...)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(len(path)))
+	buf = append(buf, path...)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(len(body)))
+	buf = append(buf, body...)
+	return SumHex(buf)
+}
@@ -0,0 +1,34 @@
+package sm3
+
+import "testing"
+
+func TestIdempotencyKey(t *testing.T) {
+	k := IdempotencyKey("POST", "/orders", []byte(`{"id":1}`))
+	if len(k) != 2*Size {
+		t.Errorf("key %q has %d characters", k, len(k))
+	}
+	if again := IdempotencyKey("POST", "/orders", []byte(`{"id":1}`)); again != k {
+		t.Errorf("key changed between calls: %s, %s", k, again)
+	}
+
+	seen := make(map[string]string)
+	for _, tt := range []struct {
+		method, path, body string
+	}{
+		{"GET", "/a", "b"},
+		{"GET", "/ab", ""},
+		{"GET/", "a", "b"},
+		{"GE", "T/a", "b"},
+		{"", "GET/a", "b"},
+		{"GET", "", "/ab"},
+		{"GET", "/a", ""},
+		{"GET", "/a", "\x00"},
+	} {
+		key := IdempotencyKey(tt.method, tt.path, []byte(tt.body))
+		name := tt.method + "|" + tt.path + "|" + tt.body
+		if prev, ok := seen[key]; ok {
+			t.Errorf("(%s) and (%s) share key %s", prev, name, key)
+		}
+		seen[key] = name
+	}
+}
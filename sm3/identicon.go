@@ -0,0 +1,15 @@
+package sm3
+
+// IdenticonSeed returns Sum(data), for deriving a stable identicon or
+// avatar from a user identifier. It is the same digest as Sum; the name
+// marks where the visuals come from so that changing it is deliberate,
+// since every user's picture would change with it.
+func IdenticonSeed(data []byte) [Size]byte { return Sum(data) }
+
+// ColorFromDigest returns the color of an identicon seeded with d: red,
+// green and blue are d[0], d[1] and d[2]. The byte positions are part of
+// the API and will not change. Patterns drawn from the same seed should
+// use bytes from d[3] on, so that shape and color are independent.
+func ColorFromDigest(d [Size]byte) (r, g, b uint8) {
+	return d[0], d[1], d[2]
+}
@@ -0,0 +1,30 @@
+package sm3
+
+import "testing"
+
+func TestColorFromDigest(t *testing.T) {
+	// Sum("abc") starts 66c7f0; the color is those three bytes, in order.
+	seed := IdenticonSeed([]byte("abc"))
+	if seed != Sum([]byte("abc")) {
+		t.Fatalf("IdenticonSeed(%q) = %x, want Sum", "abc", seed)
+	}
+	if r, g, b := ColorFromDigest(seed); r != 0x66 || g != 0xc7 || b != 0xf0 {
+		t.Errorf("ColorFromDigest: %02x%02x%02x, want 66c7f0", r, g, b)
+	}
+
+	r1, g1, b1 := ColorFromDigest(IdenticonSeed([]byte("user@example.com")))
+	r2, g2, b2 := ColorFromDigest(IdenticonSeed([]byte("user@example.com")))
+	if r1 != r2 || g1 != g2 || b1 != b2 {
+		t.Errorf("same input, colors %02x%02x%02x and %02x%02x%02x", r1, g1, b1, r2, g2, b2)
+	}
+
+	// Only the first three bytes matter.
+	var d [Size]byte
+	d[0], d[1], d[2] = 1, 2, 3
+	for i := 3; i < Size; i++ {
+		d[i] = 0xff
+	}
+	if r, g, b := ColorFromDigest(d); r != 1 || g != 2 || b != 3 {
+		t.Errorf("ColorFromDigest used bytes past d[2]: %d %d %d", r, g, b)
+	}
+}
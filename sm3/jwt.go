@@ -0,0 +1,91 @@
+package sm3
+
+import (
+	"crypto/hmac"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// JWTAlgorithm is the "alg" header value of JWTs signed by SignJWT.
+const JWTAlgorithm = "HS-SM3"
+
+// SignJWT returns a compact JWS of claims, authenticated with HMAC-SM3
+// under key over the base64url header and payload as in RFC 7515. The
+// header's "alg" is set to JWTAlgorithm and "typ" defaults to "JWT"; a
+// header naming any other algorithm is an error. header may be nil and is
+// not modified.
+func SignJWT(key []byte, header, claims map[string]any) (string, error) {
+	h := make(map[string]any, len(header)+2)
+	for k, v := range header {
+		h[k] = v
+	}
+	if alg, ok := h["alg"]; ok && alg != JWTAlgorithm {
+		return "", fmt.Errorf("sm3: JWT header algorithm %v, want %s", alg, JWTAlgorithm)
+	}
+	h["alg"] = JWTAlgorithm
+	if _, ok := h["typ"]; !ok {
+		h["typ"] = "JWT"
+	}
+	hj, err := json.Marshal(h)
+	if err != nil {
+		return "", fmt.Errorf("sm3: encoding JWT header: %w", err)
+	}
+	cj, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("sm3: encoding JWT claims: %w", err)
+	}
+	signed := base64.RawURLEncoding.EncodeToString(hj) + "." + base64.RawURLEncoding.EncodeToString(cj)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(jwtMAC(key, signed)), nil
+}
+
+// VerifyJWT checks the HMAC-SM3 signature of a token made by SignJWT and
+// returns its claims. The signature is compared in constant time, and is
+// checked before the payload is decoded. Tokens whose header names any
+// algorithm other than JWTAlgorithm, including "none", are rejected.
+//
+// A bad signature returns ErrAuth, and a token that cannot be parsed an
+// error wrapping ErrMalformedToken. Registered claims such as "exp" and
+// "nbf" are returned as is and not checked.
+func VerifyJWT(key []byte, token string) (claims map[string]any, err error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: %d segments", ErrMalformedToken, len(parts))
+	}
+	hj, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrMalformedToken, err)
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := json.Unmarshal(hj, &header); err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrMalformedToken, err)
+	}
+	if header.Alg != JWTAlgorithm {
+		return nil, fmt.Errorf("%w: algorithm %q", ErrMalformedToken, header.Alg)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature: %v", ErrMalformedToken, err)
+	}
+	if !hmac.Equal(jwtMAC(key, parts[0]+"."+parts[1]), sig) {
+		return nil, ErrAuth
+	}
+	cj, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: payload: %v", ErrMalformedToken, err)
+	}
+	if err := json.Unmarshal(cj, &claims); err != nil {
+		return nil, fmt.Errorf("%w: payload: %v", ErrMalformedToken, err)
+	}
+	return claims, nil
+}
+
+// jwtMAC returns the HMAC-SM3 of the JWS signing input.
+func jwtMAC(key []byte, signingInput string) []byte {
+	m := NewHMAC(key)
+	m.Write([]byte(signingInput))
+	return m.Sum(nil)
+}
@@ -0,0 +1,65 @@
+package sm3
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestJWTRoundTrip(t *testing.T) {
+	key := []byte("jwt signing key")
+	header := map[string]any{"kid": "k1"}
+	token, err := SignJWT(key, header, map[string]any{"sub": "alice", "n": 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(header) != 1 {
+		t.Errorf("SignJWT modified the header map: %v", header)
+	}
+	claims, err := VerifyJWT(key, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims["sub"] != "alice" || claims["n"] != float64(42) {
+		t.Errorf("claims = %v", claims)
+	}
+
+	hj, _ := base64.RawURLEncoding.DecodeString(strings.Split(token, ".")[0])
+	if !strings.Contains(string(hj), `"alg":"HS-SM3"`) || !strings.Contains(string(hj), `"kid":"k1"`) {
+		t.Errorf("header = %s", hj)
+	}
+}
+
+func TestJWTRejects(t *testing.T) {
+	key := []byte("jwt signing key")
+	token, err := SignJWT(key, nil, map[string]any{"admin": false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(token, ".")
+
+	tampered := parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"admin":true}`)) + "." + parts[2]
+	if _, err := VerifyJWT(key, tampered); err != ErrAuth {
+		t.Errorf("tampered payload: err = %v, want %v", err, ErrAuth)
+	}
+	if _, err := VerifyJWT([]byte("wrong key"), token); err != ErrAuth {
+		t.Errorf("wrong key: err = %v, want %v", err, ErrAuth)
+	}
+
+	none := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`))
+	for name, tok := range map[string]string{
+		"two segments":  parts[0] + "." + parts[1],
+		"alg none":      none + "." + parts[1] + ".",
+		"bad header":    "!!." + parts[1] + "." + parts[2],
+		"bad signature": parts[0] + "." + parts[1] + ".!!",
+	} {
+		if _, err := VerifyJWT(key, tok); !errors.Is(err, ErrMalformedToken) {
+			t.Errorf("%s: err = %v, want %v", name, err, ErrMalformedToken)
+		}
+	}
+
+	if _, err := SignJWT(key, map[string]any{"alg": "HS256"}, nil); err == nil {
+		t.Error("SignJWT accepted a header with another algorithm")
+	}
+}
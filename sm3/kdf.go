@@ -0,0 +1,85 @@
+package sm3
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash"
+	"io"
+)
+
+// KDF is the key derivation function of GB/T 32918.4 (section 5.4.3) used by
+// SM2 key exchange and encryption. It returns keyLen bytes made of
+// SM3(z || ct) for a 32-bit big-endian counter ct starting at 1, with the
+// last block truncated.
+func KDF(z []byte, keyLen int) []byte {
+	return KDFFrom(z, 1, keyLen)
+}
+
+// KDFFrom is KDF with the counter starting at start instead of 1, for
+// interoperating with counter-mode SM3 KDFs in other specifications, some
+// of which count from 0.
+func KDFFrom(z []byte, start uint32, keyLen int) []byte {
+	return KDFWith(New, z, start, keyLen)
+}
+
+// KDFWith is KDFFrom with the hash built by newHash in place of SM3, for
+// instance a hash from NewPrefixed. The output is made of whole newHash
+// digests, the last one truncated.
+func KDFWith(newHash func() hash.Hash, z []byte, start uint32, keyLen int) []byte {
+	if keyLen <= 0 {
+		return nil
+	}
+	h := newHash()
+	out := make([]byte, 0, keyLen+h.Size())
+	var ct [4]byte
+	for counter := start; len(out) < keyLen; counter++ {
+		binary.BigEndian.PutUint32(ct[:], counter)
+		h.Reset()
+		h.Write(z)
+		h.Write(ct[:])
+		out = h.Sum(out)
+	}
+	return out[:keyLen]
+}
+
+// NewKDFReader returns a reader over the KDF output stream for z: the
+// concatenation of SM3(z || ct) for ct = 1, 2, ..., generated one block at
+// a time as it is read. Reading n bytes in total yields KDF(z, n), so many
+// subkeys can be drawn from one secret without fixing the total length up
+// front. z is copied. Once the 32-bit counter is exhausted Read returns
+// ErrOutputTooLong.
+func NewKDFReader(z []byte) io.Reader {
+	return &kdfReader{z: bytes.Clone(z), counter: 1, off: Size}
+}
+
+type kdfReader struct {
+	z       []byte
+	counter uint32
+	done    bool // counter has wrapped
+	buf     [Size]byte
+	off     int // unread output starts at buf[off:]
+	d       digest
+}
+
+func (r *kdfReader) Read(p []byte) (n int, err error) {
+	for n < len(p) {
+		if r.off == Size {
+			if r.done {
+				return n, ErrOutputTooLong
+			}
+			var ct [4]byte
+			binary.BigEndian.PutUint32(ct[:], r.counter)
+			r.d.Reset()
+			r.d.Write(r.z)
+			r.d.Write(ct[:])
+			r.buf = r.d.checkSum()
+			r.off = 0
+			r.counter++
+			r.done = r.counter == 0
+		}
+		m := copy(p[n:], r.buf[r.off:])
+		r.off += m
+		n += m
+	}
+	return n, nil
+}
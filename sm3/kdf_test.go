@@ -0,0 +1,130 @@
+package sm3
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"testing"
+)
+
+func TestKDF(t *testing.T) {
+	// Intermediate value t = KDF(x2 || y2, klen) from the SM2 encryption
+	// example of GM/T 0003.4 Annex A.
+	z, _ := hex.DecodeString("64D20D27D0632957F8028C1E024F6B02EDF23102A566C932AE8BD613A8E865FE" +
+		"58D225ECA784AE300A81A2D48281A828E1CEDF11C4219099840265375077BF78")
+	want, _ := hex.DecodeString("006e30dae231b071dfad8aa379e90264491603")
+	if got := KDF(z, len(want)); !bytes.Equal(got, want) {
+		t.Errorf("KDF = %x, want %x", got, want)
+	}
+}
+
+func TestKDFPrefix(t *testing.T) {
+	z := []byte("shared secret")
+	long := KDF(z, 3*Size+5)
+	if len(long) != 3*Size+5 {
+		t.Fatalf("len = %d", len(long))
+	}
+	for _, n := range []int{1, Size - 1, Size, Size + 1, 2 * Size} {
+		if got := KDF(z, n); !bytes.Equal(got, long[:n]) {
+			t.Errorf("KDF(z, %d) is not a prefix of the longer output", n)
+		}
+	}
+	first := Sum(append(z, 0, 0, 0, 1))
+	if !bytes.Equal(long[:Size], first[:]) {
+		t.Error("first block is not SM3(z || 00000001)")
+	}
+	if KDF(z, 0) != nil {
+		t.Error("KDF(z, 0) != nil")
+	}
+}
+
+func TestKDFFrom(t *testing.T) {
+	z := []byte("shared secret")
+	block := func(ct byte) []byte {
+		s := Sum(append(append([]byte(nil), z...), 0, 0, 0, ct))
+		return s[:]
+	}
+
+	if got := KDFFrom(z, 1, 2*Size); !bytes.Equal(got, KDF(z, 2*Size)) {
+		t.Error("KDFFrom(z, 1, n) differs from KDF")
+	}
+	from0 := KDFFrom(z, 0, 2*Size)
+	if want := append(block(0), block(1)...); !bytes.Equal(from0, want) {
+		t.Errorf("KDFFrom(z, 0) = %x, want %x", from0, want)
+	}
+	from1 := KDFFrom(z, 1, Size)
+	if !bytes.Equal(from1, block(1)) {
+		t.Errorf("KDFFrom(z, 1) = %x, want %x", from1, block(1))
+	}
+	if bytes.Equal(from0[:Size], from1) {
+		t.Error("start 0 and start 1 have the same first block")
+	}
+}
+
+func TestKDFReader(t *testing.T) {
+	z := []byte("shared secret")
+	const n = 5*Size + 7
+	want := KDF(z, n)
+	for _, chunk := range []int{1, 5, Size - 1, Size, Size + 1, n} {
+		r := NewKDFReader(z)
+		var got []byte
+		for len(got) < n {
+			p := make([]byte, min(chunk, n-len(got)))
+			m, err := r.Read(p)
+			if err != nil || m != len(p) {
+				t.Fatalf("chunk %d: Read = (%d, %v)", chunk, m, err)
+			}
+			got = append(got, p...)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("chunk %d: reader output differs from KDF(z, %d)", chunk, n)
+		}
+	}
+
+	r := NewKDFReader(z).(*kdfReader)
+	r.counter, r.off = 0xffffffff, Size
+	p := make([]byte, Size+1)
+	if m, err := r.Read(p); m != Size || !errors.Is(err, ErrOutputTooLong) {
+		t.Errorf("Read past the last counter = (%d, %v), want (%d, ErrOutputTooLong)", m, err, Size)
+	}
+}
+
+// echoHash is a fake hash whose digest is the first eight bytes written
+// since Reset, zero-padded, so KDF output can be worked out by hand.
+type echoHash struct{ buf []byte }
+
+func (h *echoHash) Write(p []byte) (int, error) { h.buf = append(h.buf, p...); return len(p), nil }
+func (h *echoHash) Sum(b []byte) []byte {
+	var out [8]byte
+	copy(out[:], h.buf)
+	return append(b, out[:]...)
+}
+func (h *echoHash) Reset()         { h.buf = h.buf[:0] }
+func (h *echoHash) Size() int      { return 8 }
+func (h *echoHash) BlockSize() int { return 8 }
+
+func TestKDFWith(t *testing.T) {
+	newEcho := func() hash.Hash { return new(echoHash) }
+	// Each block is z || ct padded to 8 bytes; the third is truncated.
+	want := []byte{
+		'z', 'z', 0, 0, 0, 7, 0, 0,
+		'z', 'z', 0, 0, 0, 8, 0, 0,
+		'z', 'z', 0, 0,
+	}
+	if got := KDFWith(newEcho, []byte("zz"), 7, len(want)); !bytes.Equal(got, want) {
+		t.Errorf("KDFWith(echo) = %x, want %x", got, want)
+	}
+	if got := KDFWith(newEcho, []byte("zz"), 7, 0); got != nil {
+		t.Errorf("KDFWith(echo, 0) = %x, want nil", got)
+	}
+
+	z := []byte("shared secret")
+	if !bytes.Equal(KDFWith(New, z, 1, 100), KDF(z, 100)) {
+		t.Error("KDFWith(New) differs from KDF")
+	}
+	prefixed := NewPrefixed([]byte("domain"))
+	if bytes.Equal(KDFWith(prefixed, z, 1, 32), KDF(z, 32)) {
+		t.Error("KDFWith(NewPrefixed) matches plain KDF")
+	}
+}
@@ -0,0 +1,223 @@
+package sm3
+
+import (
+	"crypto/hmac"
+	"fmt"
+	"hash"
+
+	"golang.org/x/crypto/cryptobyte"
+)
+
+// The TLS 1.3 key schedule of RFC 8446, section 7, instantiated with SM3
+// as the transcript hash and HMAC-SM3 as the HKDF PRF, as for the
+// TLS_SM4_GCM_SM3 and TLS_SM4_CCM_SM3 suites of RFC 8998.
+
+const (
+	// TrafficKeyLen and TrafficIVLen are the sizes of the SM4 key and the
+	// AEAD nonce for the RFC 8998 cipher suites, as returned by TrafficKey.
+	TrafficKeyLen = 16
+	TrafficIVLen  = 12
+)
+
+// ExpandLabel implements HKDF-Expand-Label of RFC 8446, section 7.1, with
+// HKDF-SM3: length bytes of HKDFExpand(secret, HkdfLabel), where HkdfLabel
+// encodes length, "tls13 " + label and context. It returns an error
+// wrapping ErrBadLength if the label or context does not fit in its
+// one-byte length prefix, or ErrOutputTooLong if length exceeds
+// MaxHKDFLength.
+func ExpandLabel(secret []byte, label string, context []byte, length int) ([]byte, error) {
+	if len("tls13 ")+len(label) > 255 || len(context) > 255 {
+		return nil, fmt.Errorf("%w: HKDF label of %d bytes or context of %d bytes exceeds 255", ErrBadLength, len("tls13 ")+len(label), len(context))
+	}
+	if length < 0 || length > MaxHKDFLength {
+		return nil, fmt.Errorf("%w: HKDF-Expand-Label length %d, maximum %d", ErrOutputTooLong, length, MaxHKDFLength)
+	}
+	var b cryptobyte.Builder
+	b.AddUint16(uint16(length))
+	b.AddUint8LengthPrefixed(func(b *cryptobyte.Builder) {
+		b.AddBytes([]byte("tls13 "))
+		b.AddBytes([]byte(label))
+	})
+	b.AddUint8LengthPrefixed(func(b *cryptobyte.Builder) {
+		b.AddBytes(context)
+	})
+	return HKDFExpand(secret, b.BytesOrPanic(), length)
+}
+
+// expandLabel is ExpandLabel for the fixed labels and lengths of the key
+// schedule, which cannot fail.
+func expandLabel(secret []byte, label string, context []byte, length int) []byte {
+	out, err := ExpandLabel(secret, label, context, length)
+	if err != nil {
+		panic("sm3: " + err.Error())
+	}
+	return out
+}
+
+// DeriveSecret implements Derive-Secret of RFC 8446, section 7.1: Size
+// bytes of ExpandLabel(secret, label, SM3(messages)), where the hash of the
+// handshake messages is transcript.Sum. A nil transcript stands for no
+// messages. transcript is not modified and may keep absorbing messages.
+func DeriveSecret(secret []byte, label string, transcript hash.Hash) []byte {
+	var th [Size]byte
+	if transcript == nil {
+		th = EmptyDigest
+	} else {
+		transcript.Sum(th[:0])
+	}
+	return expandLabel(secret, label, th[:], Size)
+}
+
+// KeySchedule holds the secrets of one TLS 1.3 connection's key schedule
+// with SM3. It starts at the early secret; DeriveHandshake mixes in the
+// (EC)DHE shared secret to reach the handshake secret, and DeriveMaster
+// then reaches the master secret. The traffic secrets of each stage are
+// derived from the stage's secret and a transcript hash.Hash from New
+// holding the handshake messages so far.
+//
+// Methods panic if called before the stage they belong to has been
+// reached, or if a stage is derived twice. A KeySchedule is not safe for
+// concurrent use.
+type KeySchedule struct {
+	early, handshake, master []byte
+}
+
+// NewKeySchedule returns a KeySchedule at the early secret,
+// HKDF-Extract(0, psk). A nil psk, for a handshake without a pre-shared
+// key, is treated as Size zero bytes.
+func NewKeySchedule(psk []byte) *KeySchedule {
+	if psk == nil {
+		psk = make([]byte, Size)
+	}
+	return &KeySchedule{early: HKDFExtract(psk, nil)}
+}
+
+// EarlySecret returns the early secret.
+func (ks *KeySchedule) EarlySecret() []byte { return ks.early }
+
+// ResumptionBinderKey returns the PSK binder key for a resumption PSK,
+// Derive-Secret(early, "res binder", "").
+func (ks *KeySchedule) ResumptionBinderKey() []byte {
+	return DeriveSecret(ks.early, "res binder", nil)
+}
+
+// ExternalBinderKey returns the PSK binder key for an external PSK,
+// Derive-Secret(early, "ext binder", "").
+func (ks *KeySchedule) ExternalBinderKey() []byte {
+	return DeriveSecret(ks.early, "ext binder", nil)
+}
+
+// ClientEarlyTrafficSecret returns client_early_traffic_secret, over a
+// transcript holding the ClientHello.
+func (ks *KeySchedule) ClientEarlyTrafficSecret(transcript hash.Hash) []byte {
+	return DeriveSecret(ks.early, "c e traffic", transcript)
+}
+
+// DeriveHandshake advances ks to the handshake secret,
+// HKDF-Extract(Derive-Secret(early, "derived", ""), sharedSecret), where
+// sharedSecret is the (EC)DHE output. A nil sharedSecret, for PSK-only key
+// exchange, is treated as Size zero bytes.
+func (ks *KeySchedule) DeriveHandshake(sharedSecret []byte) {
+	if ks.handshake != nil {
+		panic("sm3: KeySchedule.DeriveHandshake called twice")
+	}
+	if sharedSecret == nil {
+		sharedSecret = make([]byte, Size)
+	}
+	ks.handshake = HKDFExtract(sharedSecret, DeriveSecret(ks.early, "derived", nil))
+}
+
+// HandshakeSecret returns the handshake secret.
+func (ks *KeySchedule) HandshakeSecret() []byte {
+	ks.need(ks.handshake, "HandshakeSecret", "DeriveHandshake")
+	return ks.handshake
+}
+
+// ClientHandshakeTrafficSecret returns client_handshake_traffic_secret,
+// over a transcript holding ClientHello through ServerHello.
+func (ks *KeySchedule) ClientHandshakeTrafficSecret(transcript hash.Hash) []byte {
+	ks.need(ks.handshake, "ClientHandshakeTrafficSecret", "DeriveHandshake")
+	return DeriveSecret(ks.handshake, "c hs traffic", transcript)
+}
+
+// ServerHandshakeTrafficSecret returns server_handshake_traffic_secret,
+// over a transcript holding ClientHello through ServerHello.
+func (ks *KeySchedule) ServerHandshakeTrafficSecret(transcript hash.Hash) []byte {
+	ks.need(ks.handshake, "ServerHandshakeTrafficSecret", "DeriveHandshake")
+	return DeriveSecret(ks.handshake, "s hs traffic", transcript)
+}
+
+// DeriveMaster advances ks to the master secret,
+// HKDF-Extract(Derive-Secret(handshake, "derived", ""), 0).
+func (ks *KeySchedule) DeriveMaster() {
+	ks.need(ks.handshake, "DeriveMaster", "DeriveHandshake")
+	if ks.master != nil {
+		panic("sm3: KeySchedule.DeriveMaster called twice")
+	}
+	ks.master = HKDFExtract(make([]byte, Size), DeriveSecret(ks.handshake, "derived", nil))
+}
+
+// MasterSecret returns the master secret.
+func (ks *KeySchedule) MasterSecret() []byte {
+	ks.need(ks.master, "MasterSecret", "DeriveMaster")
+	return ks.master
+}
+
+// ClientApplicationTrafficSecret returns client_application_traffic_secret_0,
+// over a transcript holding ClientHello through the server Finished.
+func (ks *KeySchedule) ClientApplicationTrafficSecret(transcript hash.Hash) []byte {
+	ks.need(ks.master, "ClientApplicationTrafficSecret", "DeriveMaster")
+	return DeriveSecret(ks.master, "c ap traffic", transcript)
+}
+
+// ServerApplicationTrafficSecret returns server_application_traffic_secret_0,
+// over a transcript holding ClientHello through the server Finished.
+func (ks *KeySchedule) ServerApplicationTrafficSecret(transcript hash.Hash) []byte {
+	ks.need(ks.master, "ServerApplicationTrafficSecret", "DeriveMaster")
+	return DeriveSecret(ks.master, "s ap traffic", transcript)
+}
+
+// ExporterMasterSecret returns exporter_master_secret, over a transcript
+// holding ClientHello through the server Finished.
+func (ks *KeySchedule) ExporterMasterSecret(transcript hash.Hash) []byte {
+	ks.need(ks.master, "ExporterMasterSecret", "DeriveMaster")
+	return DeriveSecret(ks.master, "exp master", transcript)
+}
+
+// ResumptionMasterSecret returns resumption_master_secret, over a
+// transcript holding ClientHello through the client Finished.
+func (ks *KeySchedule) ResumptionMasterSecret(transcript hash.Hash) []byte {
+	ks.need(ks.master, "ResumptionMasterSecret", "DeriveMaster")
+	return DeriveSecret(ks.master, "res master", transcript)
+}
+
+func (ks *KeySchedule) need(secret []byte, method, step string) {
+	if secret == nil {
+		panic("sm3: KeySchedule." + method + " called before " + step)
+	}
+}
+
+// TrafficKey returns the record protection key and IV for a traffic
+// secret, per RFC 8446, section 7.3: ExpandLabel(secret, "key", "",
+// TrafficKeyLen) and ExpandLabel(secret, "iv", "", TrafficIVLen).
+func TrafficKey(trafficSecret []byte) (key, iv []byte) {
+	key = expandLabel(trafficSecret, "key", nil, TrafficKeyLen)
+	iv = expandLabel(trafficSecret, "iv", nil, TrafficIVLen)
+	return
+}
+
+// NextTrafficSecret returns the traffic secret following trafficSecret
+// after a KeyUpdate, per RFC 8446, section 7.2.
+func NextTrafficSecret(trafficSecret []byte) []byte {
+	return expandLabel(trafficSecret, "traffic upd", nil, Size)
+}
+
+// FinishedVerifyData returns the verify_data of a Finished message, or a
+// PSK binder, per RFC 8446, section 4.4.4: HMAC-SM3 keyed by
+// ExpandLabel(baseKey, "finished", "", Size) over the transcript hash.
+// baseKey is the sender's handshake traffic secret, or the binder key.
+func FinishedVerifyData(baseKey []byte, transcript hash.Hash) []byte {
+	mac := hmac.New(New, expandLabel(baseKey, "finished", nil, Size))
+	mac.Write(transcript.Sum(nil))
+	return mac.Sum(nil)
+}
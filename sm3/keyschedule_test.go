@@ -0,0 +1,101 @@
+package sm3
+
+import (
+	"encoding/hex"
+	"errors"
+	"hash"
+	"strings"
+	"testing"
+)
+
+// TestKeySchedule runs the whole schedule on a fixed (EC)DHE output and
+// transcript. The expected values were computed independently in Python
+// from RFC 8446 with hashlib's SM3 and hmac, so they check the labels,
+// the "derived" steps and which transcript each secret is taken over.
+func TestKeySchedule(t *testing.T) {
+	ecdhe := make([]byte, 32)
+	for i := range ecdhe {
+		ecdhe[i] = byte(i)
+	}
+	transcript := New()
+	at := func(msg string) hash.Hash {
+		transcript.Write([]byte(msg))
+		return transcript
+	}
+	check := func(name string, got []byte, want string) {
+		t.Helper()
+		if hex.EncodeToString(got) != want {
+			t.Errorf("%s = %x, want %s", name, got, want)
+		}
+	}
+
+	ks := NewKeySchedule(nil)
+	check("early secret", ks.EarlySecret(), "a4f50a29c327e9acc4ddd4dbe32b75a6a1d77e4bbe823e3d71fdcc1a5fa52757")
+	check("ext binder", ks.ExternalBinderKey(), "600a47709cabf708f8b164f0fe4f338d02ce7eaa7871705b772c1d7a18fb8483")
+	check("c e traffic", ks.ClientEarlyTrafficSecret(at("ClientHello")), "ab9002aafa58087932fc47be29b23ed8b9227aca95eb498cbd853acd6b8469a2")
+
+	ks.DeriveHandshake(ecdhe)
+	check("handshake secret", ks.HandshakeSecret(), "5c39e62488f8ad1bd6e247b7ecc861eb6886a19c0d286152ae0e499d03885ba9")
+	at("ServerHello...EncryptedExtensions")
+	check("c hs traffic", ks.ClientHandshakeTrafficSecret(transcript), "99bd429fd1d3c47d12d6434b62f30b1d8667abd6b0e56f05c030e89c060e9685")
+	shts := ks.ServerHandshakeTrafficSecret(transcript)
+	check("s hs traffic", shts, "9fad490a0bb243ffa5a697ac26a058609976a10db491d56de260791ec44bfbaa")
+	key, iv := TrafficKey(shts)
+	check("server handshake key", key, "622d524dbfa11bab327fd0258248a318")
+	check("server handshake iv", iv, "8eddfa91098d6f9e47cd45d6")
+	check("finished", FinishedVerifyData(shts, transcript), "187b126afecf096badfa1ab45846c7ed4de1ff815d2886aa5bb20c7102a20aff")
+	check("traffic upd", NextTrafficSecret(shts), "5ef9018643eb18c5ead507236b88a68f03db15d632064a7be95b26328d687a11")
+
+	ks.DeriveMaster()
+	check("master secret", ks.MasterSecret(), "33fc1bbd669b737f647a59962a3e8680ed1b84cb1e7d9f1706699f5d5c9909ce")
+	at("...ServerFinished")
+	check("c ap traffic", ks.ClientApplicationTrafficSecret(transcript), "4d19f08f1f31ac0bf1315db18a19987a8cfcbd5a8f2cf7e874e715e70ba40efd")
+	check("s ap traffic", ks.ServerApplicationTrafficSecret(transcript), "2682a753622b943427f4a6bb2700a801c188011e054ec6cd7b7e62735a9c4451")
+	check("exp master", ks.ExporterMasterSecret(transcript), "da276696c149948d2b146093420e712ce5cff4d50f8c8b95b9a8f52ca257852e")
+	check("res master", ks.ResumptionMasterSecret(at("ClientFinished")), "294ec23088de8f7dd98d1d5a4329c3ea7686276f247baea4aefc5a1be06b32db")
+
+	psk := NewKeySchedule([]byte("resumption psk"))
+	check("PSK early secret", psk.EarlySecret(), "a0f8d7cd93e4c21456919da0dba6cdc75b12407c82f49c01d7838bd74497b7a9")
+	check("res binder", psk.ResumptionBinderKey(), "79ea07cc24d575f9b2ff4233ae5e53a07db00c123518453f3ddcf2e1e8fd8d80")
+}
+
+func TestKeyScheduleOrder(t *testing.T) {
+	for name, fn := range map[string]func(ks *KeySchedule){
+		"HandshakeSecret":                func(ks *KeySchedule) { ks.HandshakeSecret() },
+		"ClientHandshakeTrafficSecret":   func(ks *KeySchedule) { ks.ClientHandshakeTrafficSecret(nil) },
+		"DeriveMaster":                   func(ks *KeySchedule) { ks.DeriveMaster() },
+		"MasterSecret":                   func(ks *KeySchedule) { ks.DeriveHandshake(nil); ks.MasterSecret() },
+		"ServerApplicationTrafficSecret": func(ks *KeySchedule) { ks.DeriveHandshake(nil); ks.ServerApplicationTrafficSecret(nil) },
+		"DeriveHandshake twice":          func(ks *KeySchedule) { ks.DeriveHandshake(nil); ks.DeriveHandshake(nil) },
+		"DeriveMaster twice":             func(ks *KeySchedule) { ks.DeriveHandshake(nil); ks.DeriveMaster(); ks.DeriveMaster() },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: no panic", name)
+				}
+			}()
+			fn(NewKeySchedule(nil))
+		}()
+	}
+}
+
+func TestExpandLabelLimits(t *testing.T) {
+	secret := make([]byte, Size)
+	if _, err := ExpandLabel(secret, strings.Repeat("x", 249), nil, Size); err != nil {
+		t.Errorf("249-byte label: %v", err)
+	}
+	if _, err := ExpandLabel(secret, strings.Repeat("x", 250), nil, Size); !errors.Is(err, ErrBadLength) {
+		t.Errorf("250-byte label: err = %v, want ErrBadLength", err)
+	}
+	if _, err := ExpandLabel(secret, "key", make([]byte, 256), Size); !errors.Is(err, ErrBadLength) {
+		t.Errorf("256-byte context: err = %v, want ErrBadLength", err)
+	}
+	if _, err := ExpandLabel(secret, "key", nil, MaxHKDFLength+1); !errors.Is(err, ErrOutputTooLong) {
+		t.Errorf("length %d: err = %v, want ErrOutputTooLong", MaxHKDFLength+1, err)
+	}
+	// A nil transcript is the hash of no messages.
+	if a, b := DeriveSecret(secret, "derived", nil), DeriveSecret(secret, "derived", New()); string(a) != string(b) {
+		t.Error("DeriveSecret with a nil transcript differs from an empty one")
+	}
+}
@@ -0,0 +1,74 @@
+package sm3
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"os"
+	"testing"
+)
+
+// TestLengthFieldBeyond32Bits seeds the byte count past 2^31 and 2^32, the
+// limits of int and uint32 on 32-bit targets, and checks the padding length
+// and the 64-bit length field it writes.
+func TestLengthFieldBeyond32Bits(t *testing.T) {
+	for _, n := range []uint64{1 << 31, 1<<31 + 10, 1<<31 + 60, 1<<32 - 1, 1 << 32, 1<<32 + 57, 1 << 33, 1<<33 + 63, 5 << 30} {
+		if pad := len(appendPadding(nil, n<<3)); pad < 9 || pad > BlockSize+8 || (n+uint64(pad))%BlockSize != 0 {
+			t.Errorf("len %d: %d bytes of padding", n, pad)
+		}
+
+		var d digest
+		d.Reset()
+		d.len = n
+		d.nx = int(n % BlockSize)
+		got := d.checkSum()
+
+		// Build the final block(s) by hand: the buffered zero bytes, 0x80,
+		// zero padding and the bit length.
+		want := d
+		want.Reset()
+		var tail [2 * BlockSize]byte
+		nx := int(n % BlockSize)
+		tail[nx] = 0x80
+		end := BlockSize
+		if nx >= 56 {
+			end = 2 * BlockSize
+		}
+		binary.BigEndian.PutUint64(tail[end-8:], n<<3)
+		block(&want, tail[:end])
+		var wantSum [Size]byte
+		for i, s := range want.h {
+			binary.BigEndian.PutUint32(wantSum[i*4:], s)
+		}
+		if got != wantSum {
+			t.Errorf("len %d: checkSum = %x, want %x", n, got, wantSum)
+		}
+	}
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+// TestLargeStream hashes 5 GiB of zeros through many Write calls. The
+// expected digest was computed with an independent SM3 implementation.
+// It takes tens of seconds, so it runs only with SM3_LARGE_TESTS=1;
+// TestLengthFieldBeyond32Bits covers the same length field without
+// streaming the data.
+func TestLargeStream(t *testing.T) {
+	if os.Getenv("SM3_LARGE_TESTS") != "1" {
+		t.Skip("skipping 5 GiB hash; set SM3_LARGE_TESTS=1 to run it")
+	}
+	const want = "aae718f40d8d6b798e77bf732ff638d906ff62ae53eaed47b9e1ae1f692e030e"
+	h := New()
+	n, err := io.CopyBuffer(h, io.LimitReader(zeroReader{}, 5<<30), make([]byte, 1<<20))
+	if err != nil || n != 5<<30 {
+		t.Fatalf("copied %d bytes, err %v", n, err)
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != want {
+		t.Errorf("SM3(5 GiB of zeros) = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,48 @@
+package sm3
+
+import (
+	"fmt"
+	"hash"
+)
+
+// limited is an SM3 hash that refuses input beyond max bytes.
+type limited struct {
+	digest
+	max, n int64
+}
+
+// NewLimited returns a new hash.Hash computing the SM3 checksum of at most
+// max bytes. A Write that would take the total past max absorbs nothing and
+// returns an error wrapping ErrInputTooLarge; Reset clears the count.
+//
+// Unlike most hash.Hash implementations its Write can fail, so callers
+// must check the error.
+func NewLimited(max int64) (hash.Hash, error) {
+	if max < 0 {
+		return nil, fmt.Errorf("%w: negative limit %d", ErrBadLength, max)
+	}
+	l := &limited{max: max}
+	l.Reset()
+	return l, nil
+}
+
+func (l *limited) Reset() {
+	l.digest.Reset()
+	l.n = 0
+}
+
+func (l *limited) Write(p []byte) (int, error) {
+	if int64(len(p)) > l.max-l.n {
+		return 0, fmt.Errorf("%w: %d bytes would exceed %d", ErrInputTooLarge, l.n+int64(len(p)), l.max)
+	}
+	l.n += int64(len(p))
+	return l.digest.Write(p)
+}
+
+func (l *limited) WriteString(s string) (int, error) {
+	if int64(len(s)) > l.max-l.n {
+		return 0, fmt.Errorf("%w: %d bytes would exceed %d", ErrInputTooLarge, l.n+int64(len(s)), l.max)
+	}
+	l.n += int64(len(s))
+	return l.digest.WriteString(s)
+}
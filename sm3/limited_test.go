@@ -0,0 +1,53 @@
+package sm3
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestNewLimited(t *testing.T) {
+	h, err := NewLimited(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := bytes.Repeat([]byte{'x'}, 100)
+
+	if n, err := h.Write(data[:60]); n != 60 || err != nil {
+		t.Fatalf("Write under the limit = %d, %v", n, err)
+	}
+	if n, err := h.Write(data[60:]); n != 40 || err != nil {
+		t.Fatalf("Write up to the limit = %d, %v", n, err)
+	}
+	want := Sum(data)
+	if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+		t.Errorf("Sum = %x, want %x", got, want)
+	}
+
+	if n, err := h.Write([]byte{'y'}); n != 0 || !errors.Is(err, ErrInputTooLarge) {
+		t.Fatalf("Write past the limit = %d, %v; want 0, ErrInputTooLarge", n, err)
+	}
+	if _, err := io.WriteString(h, "y"); !errors.Is(err, ErrInputTooLarge) {
+		t.Fatalf("WriteString past the limit: err = %v", err)
+	}
+	// The rejected writes were not absorbed.
+	if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+		t.Errorf("Sum after rejected write = %x, want %x", got, want)
+	}
+
+	h.Reset()
+	if _, err := h.Write(data[:50]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.Write(data[:51]); !errors.Is(err, ErrInputTooLarge) {
+		t.Errorf("write crossing the limit after Reset: err = %v", err)
+	}
+	if _, err := h.Write(data[:50]); err != nil {
+		t.Errorf("write up to the limit after Reset: err = %v", err)
+	}
+
+	if _, err := NewLimited(-1); !errors.Is(err, ErrBadLength) {
+		t.Errorf("NewLimited(-1): err = %v, want ErrBadLength", err)
+	}
+}
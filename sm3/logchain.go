@@ -0,0 +1,56 @@
+package sm3
+
+// LogChainSeed is the head of a LogChain with no entries: the SM3 digest
+// of the string "sm3 log chain".
+var LogChainSeed = Sum([]byte("sm3 log chain"))
+
+// LogChain is a tamper-evident hash chain over a sequence of log entries.
+// Appending entry moves the head from prev to
+//
+//	SM3(prev || SM3(entry))
+//
+// starting from LogChainSeed, so the head commits to every entry and to
+// their order: editing, inserting, dropping or reordering any entry
+// changes it. Anyone holding a trusted head can check a copy of the log
+// with VerifyLogChain. The zero LogChain is not valid; use NewLogChain.
+type LogChain struct {
+	head [Size]byte
+	n    int
+}
+
+// NewLogChain returns an empty chain whose head is LogChainSeed.
+func NewLogChain() *LogChain {
+	return &LogChain{head: LogChainSeed}
+}
+
+// Append adds entry to the chain and returns the new head.
+func (c *LogChain) Append(entry []byte) [Size]byte {
+	c.head = logChainNext(c.head, entry)
+	c.n++
+	return c.head
+}
+
+// Head returns the current head of the chain.
+func (c *LogChain) Head() [Size]byte { return c.head }
+
+// Len returns the number of entries appended.
+func (c *LogChain) Len() int { return c.n }
+
+// VerifyLogChain replays entries from LogChainSeed and reports whether
+// they produce head.
+func VerifyLogChain(entries [][]byte, head [Size]byte) bool {
+	h := LogChainSeed
+	for _, e := range entries {
+		h = logChainNext(h, e)
+	}
+	return Equal(h, head)
+}
+
+func logChainNext(prev [Size]byte, entry []byte) [Size]byte {
+	sum := Sum(entry)
+	var d digest
+	d.Reset()
+	d.Write(prev[:])
+	d.Write(sum[:])
+	return d.checkSum()
+}
@@ -0,0 +1,49 @@
+package sm3
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLogChain(t *testing.T) {
+	c := NewLogChain()
+	if c.Head() != LogChainSeed || c.Len() != 0 {
+		t.Fatal("new chain does not start at LogChainSeed")
+	}
+	if !VerifyLogChain(nil, LogChainSeed) {
+		t.Error("empty log does not verify against the seed")
+	}
+
+	entries := [][]byte{[]byte("login alice"), []byte("grant alice admin"), []byte("logout alice")}
+	prev := c.Head()
+	for i, e := range entries {
+		s := Sum(e)
+		want := Sum(append(prev[:], s[:]...))
+		if got := c.Append(e); got != want || c.Head() != want {
+			t.Fatalf("entry %d: head %x, want SM3(prev || SM3(entry)) = %x", i, got, want)
+		}
+		prev = want
+	}
+	if c.Len() != len(entries) {
+		t.Errorf("Len = %d, want %d", c.Len(), len(entries))
+	}
+	head := c.Head()
+	if !VerifyLogChain(entries, head) {
+		t.Fatal("untampered log does not verify")
+	}
+
+	edited := [][]byte{entries[0], []byte("grant alice user"), entries[2]}
+	reordered := [][]byte{entries[1], entries[0], entries[2]}
+	for name, log := range map[string][][]byte{
+		"edited":    edited,
+		"reordered": reordered,
+		"truncated": entries[:2],
+		"extended":  append(entries[:3:3], []byte("extra")),
+		"dropped":   {entries[0], entries[2]},
+		"merged":    {entries[0], bytes.Join(entries[1:], nil)},
+	} {
+		if VerifyLogChain(log, head) {
+			t.Errorf("%s log verifies against the original head", name)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package sm3
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+)
+
+// LRU memoizes values by the SM3 digest of their input, holding at most a
+// fixed number of entries and evicting the least recently used one to make
+// room. Only the 32-byte digest of each key is stored, however large the
+// key. An LRU is safe for concurrent use.
+type LRU[V any] struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // of *lruEntry[V], most recently used first
+	entries  map[[Size]byte]*list.Element
+}
+
+type lruEntry[V any] struct {
+	key [Size]byte
+	val V
+}
+
+// NewLRU returns an empty LRU holding up to capacity entries. It panics if
+// capacity < 1.
+func NewLRU[V any](capacity int) *LRU[V] {
+	if capacity < 1 {
+		panic(fmt.Sprintf("sm3: NewLRU called with capacity %d", capacity))
+	}
+	return &LRU[V]{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[[Size]byte]*list.Element, capacity),
+	}
+}
+
+// GetOrCompute returns the value cached for Sum(key), calling compute and
+// caching its result on a miss. compute runs without the lock held, so
+// concurrent misses on one key may each call it; the first result stored
+// wins and is returned to all of them.
+func (c *LRU[V]) GetOrCompute(key []byte, compute func() V) V {
+	sum := Sum(key)
+	c.mu.Lock()
+	if e, ok := c.entries[sum]; ok {
+		c.order.MoveToFront(e)
+		v := e.Value.(*lruEntry[V]).val
+		c.mu.Unlock()
+		return v
+	}
+	c.mu.Unlock()
+
+	v := compute()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[sum]; ok {
+		c.order.MoveToFront(e)
+		return e.Value.(*lruEntry[V]).val
+	}
+	c.entries[sum] = c.order.PushFront(&lruEntry[V]{key: sum, val: v})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry[V]).key)
+	}
+	return v
+}
+
+// Len returns the number of cached entries.
+func (c *LRU[V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
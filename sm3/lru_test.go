@@ -0,0 +1,72 @@
+package sm3
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestLRU(t *testing.T) {
+	c := NewLRU[string](2)
+	calls := 0
+	get := func(key string) string {
+		return c.GetOrCompute([]byte(key), func() string {
+			calls++
+			return "v" + key
+		})
+	}
+
+	if v := get("a"); v != "va" || calls != 1 {
+		t.Fatalf("miss: got %q after %d calls", v, calls)
+	}
+	if v := get("a"); v != "va" || calls != 1 {
+		t.Fatalf("hit: got %q after %d calls", v, calls)
+	}
+
+	// Touching a makes b the least recently used, so c evicts b.
+	get("b")
+	get("a")
+	get("c")
+	if c.Len() != 2 {
+		t.Fatalf("Len = %d, want 2", c.Len())
+	}
+	calls = 0
+	get("a")
+	get("c")
+	if calls != 0 {
+		t.Errorf("a or c was evicted")
+	}
+	get("b")
+	if calls != 1 {
+		t.Errorf("b was not evicted")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("NewLRU(0) did not panic")
+		}
+	}()
+	NewLRU[int](0)
+}
+
+func TestLRUConcurrent(t *testing.T) {
+	c := NewLRU[int](16)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				k := (i * (g + 1)) % 32
+				if v := c.GetOrCompute([]byte(strconv.Itoa(k)), func() int { return k }); v != k {
+					t.Errorf("GetOrCompute(%d) = %d", k, v)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	if c.Len() > 16 {
+		t.Errorf("Len = %d, want at most 16", c.Len())
+	}
+}
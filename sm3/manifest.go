@@ -0,0 +1,74 @@
+package sm3
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"sort"
+)
+
+// Manifest accumulates (name, digest) entries and commits to them with a
+// single "hash of hashes" root. The root does not depend on the order in
+// which entries were added.
+//
+// The zero value is an empty manifest ready to use.
+type Manifest struct {
+	entries map[string][Size]byte
+}
+
+// AddFile records digest under name. Adding the same name again replaces
+// the previous digest.
+func (m *Manifest) AddFile(name string, digest [Size]byte) {
+	if m.entries == nil {
+		m.entries = make(map[string][Size]byte)
+	}
+	m.entries[name] = digest
+}
+
+// Len returns the number of entries in the manifest.
+func (m *Manifest) Len() int {
+	return len(m.entries)
+}
+
+func (m *Manifest) sortedNames() []string {
+	names := make([]string, 0, len(m.entries))
+	for name := range m.entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Root returns the SM3 digest over the entries sorted by name. Each entry
+// is framed as an 8-byte big-endian name length, the name and the 32-byte
+// digest, so that no two distinct manifests share an encoding.
+func (m *Manifest) Root() [Size]byte {
+	var lenBuf [8]byte
+	h := New()
+	binary.BigEndian.PutUint64(lenBuf[:], uint64(len(m.entries)))
+	h.Write(lenBuf[:])
+	for _, name := range m.sortedNames() {
+		digest := m.entries[name]
+		binary.BigEndian.PutUint64(lenBuf[:], uint64(len(name)))
+		h.Write(lenBuf[:])
+		h.Write([]byte(name))
+		h.Write(digest[:])
+	}
+	var root [Size]byte
+	h.Sum(root[:0])
+	return root
+}
+
+// MarshalText implements encoding.TextMarshaler. It lists the entries sorted
+// by name, one per line, as the hex digest followed by two spaces and the
+// name, matching the output of the sm3sum/sha256sum family of tools.
+func (m *Manifest) MarshalText() ([]byte, error) {
+	var out []byte
+	for _, name := range m.sortedNames() {
+		digest := m.entries[name]
+		out = append(out, hex.EncodeToString(digest[:])...)
+		out = append(out, ' ', ' ')
+		out = append(out, name...)
+		out = append(out, '\n')
+	}
+	return out, nil
+}
@@ -0,0 +1,86 @@
+package sm3
+
+import (
+	"strings"
+	"testing"
+)
+
+var manifestFiles = []struct {
+	name string
+	data string
+}{
+	{"README.md", "# readme"},
+	{"cmd/main.go", "package main"},
+	{"go.mod", "module example.com/m"},
+	{"z", ""},
+}
+
+func TestManifestOrderIndependent(t *testing.T) {
+	var forward, backward Manifest
+	for _, f := range manifestFiles {
+		forward.AddFile(f.name, Sum([]byte(f.data)))
+	}
+	for i := len(manifestFiles) - 1; i >= 0; i-- {
+		f := manifestFiles[i]
+		backward.AddFile(f.name, Sum([]byte(f.data)))
+	}
+	if forward.Root() != backward.Root() {
+		t.Errorf("roots differ by insertion order: %x vs %x", forward.Root(), backward.Root())
+	}
+
+	a, _ := forward.MarshalText()
+	b, _ := backward.MarshalText()
+	if string(a) != string(b) {
+		t.Errorf("listings differ by insertion order:\n%s\nvs\n%s", a, b)
+	}
+}
+
+func TestManifestRename(t *testing.T) {
+	var orig, renamed Manifest
+	for i, f := range manifestFiles {
+		orig.AddFile(f.name, Sum([]byte(f.data)))
+		name := f.name
+		if i == 1 {
+			name = "cmd/main2.go"
+		}
+		renamed.AddFile(name, Sum([]byte(f.data)))
+	}
+	if orig.Root() == renamed.Root() {
+		t.Error("renaming a file did not change the root")
+	}
+}
+
+func TestManifestFraming(t *testing.T) {
+	// Moving bytes between the name and the preceding entry must not
+	// produce the same root.
+	var a, b Manifest
+	a.AddFile("ab", Sum(nil))
+	b.AddFile("a", Sum(nil))
+	b.AddFile("b", Sum(nil))
+	if a.Root() == b.Root() {
+		t.Error("distinct manifests share a root")
+	}
+
+	var empty Manifest
+	if empty.Root() == a.Root() {
+		t.Error("empty manifest shares a root with a non-empty one")
+	}
+}
+
+func TestManifestMarshalText(t *testing.T) {
+	var m Manifest
+	m.AddFile("b.txt", Sum([]byte("abc")))
+	m.AddFile("a.txt", Sum(nil))
+	text, err := m.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"1ab21d8355cfa17f8e61194831e81a8f22bec8c728fefb747ed035eb5082aa2b  a.txt",
+		"66c7f0f462eeedd9d1f2d46bdc10e4e24167c4875cf2f7a2297da02b8f4ba8e0  b.txt",
+		"",
+	}, "\n")
+	if string(text) != want {
+		t.Errorf("MarshalText =\n%s\nwant\n%s", text, want)
+	}
+}
@@ -0,0 +1,56 @@
+package sm3
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// The serialized hash state mirrors that of crypto/sha256: a magic string,
+// the chaining value, the buffered block zero-padded to BlockSize, and the
+// total length, all big-endian. The number of buffered bytes is implied by
+// the length.
+const (
+	marshalMagic = "sm3\x01"
+	marshaledLen = len(marshalMagic) + 8*4 + BlockSize + 8
+)
+
+// MarshalBinary implements encoding.BinaryMarshaler, so a hash from New
+// can be saved and resumed later with UnmarshalBinary.
+func (d *digest) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, marshaledLen)
+	b = append(b, marshalMagic...)
+	for _, s := range d.h {
+		b = binary.BigEndian.AppendUint32(b, s)
+	}
+	b = append(b, d.x[:d.nx]...)
+	b = b[:len(b)+len(d.x)-d.nx] // already zero
+	return binary.BigEndian.AppendUint64(b, d.len), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. A state that is
+// not exactly what MarshalBinary produces, having the wrong magic or
+// length or bytes past the buffered ones that are not zero, is rejected
+// with an error wrapping ErrInvalidState and leaves d unchanged, so a
+// corrupted blob cannot yield wrong digests or a panic in a later Write.
+func (d *digest) UnmarshalBinary(b []byte) error {
+	if len(b) < len(marshalMagic) || string(b[:len(marshalMagic)]) != marshalMagic {
+		return fmt.Errorf("%w: not an SM3 state", ErrInvalidState)
+	}
+	if len(b) != marshaledLen {
+		return fmt.Errorf("%w: %d bytes, want %d", ErrInvalidState, len(b), marshaledLen)
+	}
+	var next digest
+	b = b[len(marshalMagic):]
+	for i := range next.h {
+		next.h[i] = binary.BigEndian.Uint32(b)
+		b = b[4:]
+	}
+	b = b[copy(next.x[:], b):]
+	next.len = binary.BigEndian.Uint64(b)
+	next.nx = int(next.len % BlockSize)
+	if !allZero(next.x[next.nx:]) {
+		return fmt.Errorf("%w: %d buffered bytes but data past them", ErrInvalidState, next.nx)
+	}
+	*d = next
+	return nil
+}
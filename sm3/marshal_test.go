@@ -0,0 +1,70 @@
+package sm3
+
+import (
+	"bytes"
+	"encoding"
+	"errors"
+	"testing"
+)
+
+func TestMarshalRoundTrip(t *testing.T) {
+	data := treeTestData(3*BlockSize + 10)
+	for _, split := range []int{0, 1, 55, BlockSize, BlockSize + 1, 2*BlockSize - 1, len(data)} {
+		h := New()
+		h.Write(data[:split])
+		state, err := h.(encoding.BinaryMarshaler).MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(state) != marshaledLen {
+			t.Errorf("split %d: state is %d bytes, want %d", split, len(state), marshaledLen)
+		}
+		resumed := New()
+		if err := resumed.(encoding.BinaryUnmarshaler).UnmarshalBinary(state); err != nil {
+			t.Fatalf("split %d: %v", split, err)
+		}
+		resumed.Write(data[split:])
+		if got, want := resumed.Sum(nil), Sum(data); !bytes.Equal(got, want[:]) {
+			t.Errorf("split %d: resumed Sum = %x, want %x", split, got, want)
+		}
+	}
+}
+
+func TestUnmarshalRejectsCorruptState(t *testing.T) {
+	h := New()
+	h.Write([]byte("ten bytes!"))
+	good, _ := h.(encoding.BinaryMarshaler).MarshalBinary()
+
+	badMagic := bytes.Clone(good)
+	badMagic[0] = 'x'
+	// The length says 10 bytes are buffered, but the block holds more: as
+	// if nx had been stored larger than the data it describes.
+	oversized := bytes.Clone(good)
+	oversized[len(marshalMagic)+32+30] = 1
+	// A length claiming a full block of buffered bytes cannot arise; it
+	// encodes nx = 0 and so requires an empty buffer.
+	fullBlock := bytes.Clone(good)
+	fullBlock[marshaledLen-1] = BlockSize
+
+	for name, state := range map[string][]byte{
+		"empty":           nil,
+		"wrong magic":     badMagic,
+		"magic only":      good[:len(marshalMagic)],
+		"truncated":       good[:len(good)-1],
+		"trailing byte":   append(bytes.Clone(good), 0),
+		"oversized nx":    oversized,
+		"full block":      fullBlock,
+		"sha256 state":    append([]byte("sha\x03"), good[4:]...),
+		"other hash size": make([]byte, marshaledLen),
+	} {
+		d := New().(*digest)
+		d.Write([]byte("untouched"))
+		before := *d
+		if err := d.UnmarshalBinary(state); !errors.Is(err, ErrInvalidState) {
+			t.Errorf("%s: err = %v, want ErrInvalidState", name, err)
+		}
+		if *d != before {
+			t.Errorf("%s: failed UnmarshalBinary modified the hash", name)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package sm3
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// Merkle trees in the style of RFC 6962 (Certificate Transparency) with SM3
+// as the hash. Leaves and interior nodes are hashed with distinct prefix
+// bytes, 0x00 and 0x01, so a leaf can never be passed off as a node.
+
+// MerkleLeafHash returns SM3(0x00 || data), the hash of a tree leaf.
+func MerkleLeafHash(data []byte) [Size]byte {
+	var d digest
+	d.Reset()
+	d.Write([]byte{0x00})
+	d.Write(data)
+	return d.checkSum()
+}
+
+// MerkleNodeHash returns SM3(0x01 || left || right), the hash of an
+// interior node.
+func MerkleNodeHash(left, right [Size]byte) [Size]byte {
+	var d digest
+	d.Reset()
+	d.Write([]byte{0x01})
+	d.Write(left[:])
+	d.Write(right[:])
+	return d.checkSum()
+}
+
+// MerkleRoot returns the root of the tree over the given leaf hashes, as
+// the Merkle Tree Hash of RFC 6962 section 2.1. The root of an empty tree
+// is SM3 of the empty string.
+func MerkleRoot(leaves [][Size]byte) [Size]byte {
+	switch len(leaves) {
+	case 0:
+		return Sum(nil)
+	case 1:
+		return leaves[0]
+	}
+	k := splitPoint(len(leaves))
+	return MerkleNodeHash(MerkleRoot(leaves[:k]), MerkleRoot(leaves[k:]))
+}
+
+// splitPoint returns the largest power of two smaller than n, for n > 1.
+func splitPoint(n int) int {
+	return 1 << (bits.Len(uint(n-1)) - 1)
+}
+
+// ConsistencyProof returns the proof that the tree over the first oldSize
+// of leaves is a prefix of the tree over all of them (RFC 6962 section
+// 2.1.2), for checking with VerifyConsistency.
+func ConsistencyProof(leaves [][Size]byte, oldSize int) ([][Size]byte, error) {
+	if oldSize < 0 || oldSize > len(leaves) {
+		return nil, fmt.Errorf("%w: old size %d for a tree of %d leaves", ErrBadLength, oldSize, len(leaves))
+	}
+	if oldSize == 0 || oldSize == len(leaves) {
+		return nil, nil
+	}
+	return subproof(oldSize, leaves, true), nil
+}
+
+// subproof is SUBPROOF(m, leaves, complete) of RFC 6962.
+func subproof(m int, leaves [][Size]byte, complete bool) [][Size]byte {
+	n := len(leaves)
+	if m == n {
+		if complete {
+			return nil
+		}
+		return [][Size]byte{MerkleRoot(leaves)}
+	}
+	k := splitPoint(n)
+	if m <= k {
+		return append(subproof(m, leaves[:k], complete), MerkleRoot(leaves[k:]))
+	}
+	return append(subproof(m-k, leaves[k:], false), MerkleRoot(leaves[:k]))
+}
+
+// VerifyConsistency reports whether proof shows that the tree of newSize
+// leaves with root newRoot was formed by appending leaves to the tree of
+// oldSize leaves with root oldRoot, following the verification algorithm
+// of RFC 9162 section 2.1.4.2. Equal sizes need an empty proof and equal
+// roots, and every tree extends the empty one.
+func VerifyConsistency(oldRoot, newRoot [Size]byte, oldSize, newSize int, proof [][Size]byte) bool {
+	switch {
+	case oldSize < 0 || oldSize > newSize:
+		return false
+	case oldSize == newSize:
+		return len(proof) == 0 && oldRoot == newRoot
+	case oldSize == 0:
+		return len(proof) == 0
+	}
+
+	if oldSize&(oldSize-1) == 0 {
+		// The old tree is a complete subtree of the new one, and its root
+		// is the first node of the path.
+		proof = append([][Size]byte{oldRoot}, proof...)
+	}
+	if len(proof) == 0 {
+		return false
+	}
+
+	fn, sn := uint(oldSize-1), uint(newSize-1)
+	for fn&1 == 1 {
+		fn >>= 1
+		sn >>= 1
+	}
+	fr, sr := proof[0], proof[0]
+	for _, c := range proof[1:] {
+		if sn == 0 {
+			return false
+		}
+		if fn&1 == 1 || fn == sn {
+			fr = MerkleNodeHash(c, fr)
+			sr = MerkleNodeHash(c, sr)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			sr = MerkleNodeHash(sr, c)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	return sn == 0 && fr == oldRoot && sr == newRoot
+}
@@ -0,0 +1,80 @@
+package sm3
+
+import (
+	"fmt"
+	"testing"
+)
+
+func merkleLeaves(n int) [][Size]byte {
+	leaves := make([][Size]byte, n)
+	for i := range leaves {
+		leaves[i] = MerkleLeafHash([]byte(fmt.Sprintf("entry %d", i)))
+	}
+	return leaves
+}
+
+func TestMerkleRoot(t *testing.T) {
+	l := merkleLeaves(3)
+	want := MerkleNodeHash(MerkleNodeHash(l[0], l[1]), l[2])
+	if got := MerkleRoot(l); got != want {
+		t.Errorf("root of 3 leaves = %x, want %x", got, want)
+	}
+	if got := MerkleRoot(nil); got != Sum(nil) {
+		t.Errorf("empty root = %x, want SM3 of nothing", got)
+	}
+	if MerkleLeafHash(nil) == Sum(nil) {
+		t.Error("leaf hash is not domain-separated")
+	}
+}
+
+func TestVerifyConsistency(t *testing.T) {
+	const max = 20
+	leaves := merkleLeaves(max)
+	for newSize := 1; newSize <= max; newSize++ {
+		newRoot := MerkleRoot(leaves[:newSize])
+		for oldSize := 0; oldSize <= newSize; oldSize++ {
+			oldRoot := MerkleRoot(leaves[:oldSize])
+			proof, err := ConsistencyProof(leaves[:newSize], oldSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !VerifyConsistency(oldRoot, newRoot, oldSize, newSize, proof) {
+				t.Errorf("%d -> %d: valid proof rejected", oldSize, newSize)
+			}
+			if oldSize == 0 || oldSize == newSize {
+				continue
+			}
+
+			for i := range proof {
+				bad := append([][Size]byte(nil), proof...)
+				bad[i][0] ^= 1
+				if VerifyConsistency(oldRoot, newRoot, oldSize, newSize, bad) {
+					t.Errorf("%d -> %d: proof with node %d tampered accepted", oldSize, newSize, i)
+				}
+			}
+			if VerifyConsistency(oldRoot, newRoot, oldSize, newSize, proof[:len(proof)-1]) {
+				t.Errorf("%d -> %d: truncated proof accepted", oldSize, newSize)
+			}
+			if VerifyConsistency(oldRoot, newRoot, oldSize, newSize, append(proof, proof[0])) {
+				t.Errorf("%d -> %d: proof with an extra node accepted", oldSize, newSize)
+			}
+			wrongOld := oldRoot
+			wrongOld[Size-1] ^= 1
+			if VerifyConsistency(wrongOld, newRoot, oldSize, newSize, proof) {
+				t.Errorf("%d -> %d: wrong old root accepted", oldSize, newSize)
+			}
+		}
+	}
+
+	// A tree whose history was rewritten is not consistent with the old one.
+	forked := merkleLeaves(10)
+	forked[2] = MerkleLeafHash([]byte("rewritten"))
+	proof, _ := ConsistencyProof(forked, 5)
+	if VerifyConsistency(MerkleRoot(leaves[:5]), MerkleRoot(forked), 5, 10, proof) {
+		t.Error("proof for a rewritten log accepted")
+	}
+
+	if _, err := ConsistencyProof(leaves[:3], 4); err == nil {
+		t.Error("ConsistencyProof accepted an old size past the tree")
+	}
+}
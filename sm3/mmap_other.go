@@ -0,0 +1,10 @@
+//go:build !unix
+
+package sm3
+
+// SumMmap returns the SM3 checksum of the contents of the named file. On
+// this platform memory mapping is not implemented and it is the same as
+// SumFile.
+func SumMmap(path string) ([Size]byte, error) {
+	return SumFile(path)
+}
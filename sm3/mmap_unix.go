@@ -0,0 +1,39 @@
+//go:build unix
+
+package sm3
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// SumMmap returns the SM3 checksum of the contents of the named file,
+// mapping it into memory and hashing the mapping in one pass, without read
+// calls or copies. It falls back to SumFile for files that cannot be
+// mapped, such as pipes or files too large for the address space. The
+// mapping is always released before SumMmap returns.
+//
+// The file must not be truncated while it is being hashed: on most systems
+// touching a mapped page past the end of the file raises SIGBUS.
+func SumMmap(path string) ([Size]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return [Size]byte{}, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return [Size]byte{}, err
+	}
+	size := fi.Size()
+	if !fi.Mode().IsRegular() || size == 0 || int64(int(size)) != size {
+		return SumReader(f)
+	}
+	data, err := unix.Mmap(int(f.Fd()), 0, int(size), unix.PROT_READ, unix.MAP_SHARED)
+	if err != nil {
+		return SumReader(f)
+	}
+	defer unix.Munmap(data)
+	return Sum(data), nil
+}
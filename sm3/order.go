@@ -0,0 +1,36 @@
+package sm3
+
+import (
+	"bytes"
+	"slices"
+)
+
+// OrderKey returns Sum(data), for use as a sort key. Sorting items by the
+// OrderKey of an identifier gives an order that looks shuffled but is the
+// same on every run and platform, whatever order the items were inserted
+// or listed in: for deterministic test fixtures, or to break ties between
+// otherwise equal items without favoring those that sort first by name.
+// SortByDigest does this in place. Anyone who knows the identifiers can
+// compute the order, so it must not be used where the order has to be
+// unpredictable.
+func OrderKey(data []byte) [Size]byte { return Sum(data) }
+
+// SortByDigest sorts items in place by the OrderKey of key(item), compared
+// as big-endian byte strings. key is called once per item. Items whose keys
+// are equal keep their relative order.
+func SortByDigest[T any](items []T, key func(T) []byte) {
+	type keyed struct {
+		sum  [Size]byte
+		item T
+	}
+	ks := make([]keyed, len(items))
+	for i, it := range items {
+		ks[i] = keyed{OrderKey(key(it)), it}
+	}
+	slices.SortStableFunc(ks, func(a, b keyed) int {
+		return bytes.Compare(a.sum[:], b.sum[:])
+	})
+	for i := range ks {
+		items[i] = ks[i].item
+	}
+}
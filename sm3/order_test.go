@@ -0,0 +1,47 @@
+package sm3
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSortByDigest(t *testing.T) {
+	// The expected order was computed independently, sorting by
+	// hashlib.new("sm3", name).digest() in Python. It must never change:
+	// callers rely on it being the same across runs and releases.
+	want := []string{"dave", "carol", "frank", "eve", "bob", "alice"}
+	key := func(s string) []byte { return []byte(s) }
+	for _, in := range [][]string{
+		{"alice", "bob", "carol", "dave", "eve", "frank"},
+		{"frank", "eve", "dave", "carol", "bob", "alice"},
+		{"carol", "alice", "frank", "bob", "eve", "dave"},
+	} {
+		got := slices.Clone(in)
+		SortByDigest(got, key)
+		if !slices.Equal(got, want) {
+			t.Errorf("SortByDigest(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	// Items with equal keys keep their order.
+	type rec struct {
+		name string
+		seq  int
+	}
+	recs := []rec{{"b", 0}, {"a", 1}, {"b", 2}, {"a", 3}, {"b", 4}}
+	SortByDigest(recs, func(r rec) []byte { return []byte(r.name) })
+	var seqs []int
+	for _, r := range recs {
+		seqs = append(seqs, r.seq)
+	}
+	if first := recs[0].name; !slices.Equal(seqs, map[string][]int{"a": {1, 3, 0, 2, 4}, "b": {0, 2, 4, 1, 3}}[first]) {
+		t.Errorf("equal keys reordered: %v", recs)
+	}
+
+	for _, in := range []string{"", "x"} {
+		if OrderKey([]byte(in)) != Sum([]byte(in)) {
+			t.Errorf("OrderKey(%q) is not Sum", in)
+		}
+	}
+	SortByDigest([]string(nil), key)
+}
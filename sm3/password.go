@@ -0,0 +1,69 @@
+package sm3
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// passwordSaltSize is the length of the random salt HashPassword draws.
+const passwordSaltSize = 16
+
+// MaxPasswordIterations is the largest PBKDF2 iteration count HashPassword
+// accepts and VerifyPassword runs. It bounds the work a tampered or hostile
+// stored verifier can make one VerifyPassword call do, while staying well
+// above any practical cost setting.
+const MaxPasswordIterations = 10_000_000
+
+// HashPassword derives a verifier for password with PBKDF2-HMAC-SM3 over a
+// fresh random 16-byte salt and the given iteration count. The result is
+// self-describing,
+//
+//	$sm3$<iterations>$<salt>$<hash>
+//
+// with salt and hash in unpadded standard base64, so it can be stored as is
+// and later checked with VerifyPassword.
+func HashPassword(password []byte, iterations int) (string, error) {
+	if iterations < 1 || iterations > MaxPasswordIterations {
+		return "", fmt.Errorf("sm3: PBKDF2 iteration count %d is not in [1, %d]", iterations, MaxPasswordIterations)
+	}
+	salt := make([]byte, passwordSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("sm3: reading password salt: %w", err)
+	}
+	dk := pbkdf2.Key(password, salt, iterations, Size, New)
+	return "$sm3$" + strconv.Itoa(iterations) +
+		"$" + base64.RawStdEncoding.EncodeToString(salt) +
+		"$" + base64.RawStdEncoding.EncodeToString(dk), nil
+}
+
+// VerifyPassword reports whether password matches the verifier encoded by
+// HashPassword. The derived keys are compared in constant time. An error
+// wrapping ErrMalformedPasswordHash is returned if encoded cannot be
+// parsed or its iteration count exceeds MaxPasswordIterations, in which
+// case no key is derived.
+func VerifyPassword(password []byte, encoded string) (bool, error) {
+	fields := strings.Split(encoded, "$")
+	if len(fields) != 5 || fields[0] != "" || fields[1] != "sm3" {
+		return false, fmt.Errorf("%w: unrecognized format", ErrMalformedPasswordHash)
+	}
+	iterations, err := strconv.Atoi(fields[2])
+	if err != nil || iterations < 1 || iterations > MaxPasswordIterations || strconv.Itoa(iterations) != fields[2] {
+		return false, fmt.Errorf("%w: bad iteration count %q", ErrMalformedPasswordHash, fields[2])
+	}
+	salt, err := base64.RawStdEncoding.Strict().DecodeString(fields[3])
+	if err != nil || len(salt) == 0 {
+		return false, fmt.Errorf("%w: bad salt", ErrMalformedPasswordHash)
+	}
+	want, err := base64.RawStdEncoding.Strict().DecodeString(fields[4])
+	if err != nil || len(want) != Size {
+		return false, fmt.Errorf("%w: bad hash", ErrMalformedPasswordHash)
+	}
+	dk := pbkdf2.Key(password, salt, iterations, Size, New)
+	return subtle.ConstantTimeCompare(dk, want) == 1, nil
+}
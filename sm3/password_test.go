@@ -0,0 +1,107 @@
+package sm3
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+func TestHashPasswordRoundTrip(t *testing.T) {
+	encoded, err := HashPassword([]byte("correct horse"), 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(encoded, "$sm3$1000$") {
+		t.Errorf("encoded = %q, want $sm3$1000$ prefix", encoded)
+	}
+	if ok, err := VerifyPassword([]byte("correct horse"), encoded); !ok || err != nil {
+		t.Errorf("VerifyPassword(right password) = %v, %v", ok, err)
+	}
+	if ok, err := VerifyPassword([]byte("wrong horse"), encoded); ok || err != nil {
+		t.Errorf("VerifyPassword(wrong password) = %v, %v", ok, err)
+	}
+
+	again, err := HashPassword([]byte("correct horse"), 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again == encoded {
+		t.Error("two hashes of the same password share a salt")
+	}
+}
+
+func TestVerifyPasswordKnownEncoding(t *testing.T) {
+	salt := []byte("0123456789abcdef")
+	dk := pbkdf2.Key([]byte("password"), salt, 3, Size, New)
+	encoded := "$sm3$3$" + base64.RawStdEncoding.EncodeToString(salt) + "$" + base64.RawStdEncoding.EncodeToString(dk)
+	if ok, err := VerifyPassword([]byte("password"), encoded); !ok || err != nil {
+		t.Errorf("VerifyPassword = %v, %v", ok, err)
+	}
+}
+
+func TestVerifyPasswordTampered(t *testing.T) {
+	encoded, err := HashPassword([]byte("pw"), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := strings.Split(encoded, "$")
+	flip := func(s string) string {
+		if s[0] == 'A' {
+			return "B" + s[1:]
+		}
+		return "A" + s[1:]
+	}
+
+	// Changing the salt, the hash or the iteration count keeps the
+	// encoding valid but breaks the match.
+	for name, e := range map[string]string{
+		"iterations": strings.Join([]string{"", "sm3", "11", fields[3], fields[4]}, "$"),
+		"salt":       strings.Join([]string{"", "sm3", "10", flip(fields[3]), fields[4]}, "$"),
+		"hash":       strings.Join([]string{"", "sm3", "10", fields[3], flip(fields[4])}, "$"),
+	} {
+		if ok, err := VerifyPassword([]byte("pw"), e); ok || err != nil {
+			t.Errorf("tampered %s: VerifyPassword = %v, %v, want false, nil", name, ok, err)
+		}
+	}
+
+	for _, e := range []string{
+		"",
+		"sm3$10$" + fields[3] + "$" + fields[4],
+		"$sha256$10$" + fields[3] + "$" + fields[4],
+		"$sm3$0$" + fields[3] + "$" + fields[4],
+		"$sm3$-1$" + fields[3] + "$" + fields[4],
+		"$sm3$010$" + fields[3] + "$" + fields[4],
+		"$sm3$ten$" + fields[3] + "$" + fields[4],
+		"$sm3$10000001$" + fields[3] + "$" + fields[4], // above MaxPasswordIterations
+		"$sm3$9223372036854775807$" + fields[3] + "$" + fields[4],
+		"$sm3$10$$" + fields[4],
+		"$sm3$10$" + fields[3] + "!$" + fields[4],
+		"$sm3$10$" + fields[3] + "$" + fields[4][:20],
+		encoded + "$extra",
+	} {
+		if ok, err := VerifyPassword([]byte("pw"), e); ok || !errors.Is(err, ErrMalformedPasswordHash) {
+			t.Errorf("VerifyPassword(%q) = %v, %v, want %v", e, ok, err, ErrMalformedPasswordHash)
+		}
+	}
+
+	if _, err := HashPassword([]byte("pw"), 0); err == nil {
+		t.Error("HashPassword accepted zero iterations")
+	}
+	if _, err := HashPassword([]byte("pw"), MaxPasswordIterations+1); err == nil {
+		t.Error("HashPassword accepted more than MaxPasswordIterations")
+	}
+}
+
+func TestPBKDF2WithHash(t *testing.T) {
+	// PBKDF2-HMAC-SM3 from Python's hashlib over OpenSSL, checked against
+	// x/crypto/pbkdf2 driven by Hash.
+	want, _ := hex.DecodeString("e8b635a41dfe5aaab7cf828cff6f3608e22cac59ba16edd70e000b293d00bc9118504f57ab46673d")
+	if got := pbkdf2.Key([]byte("password"), []byte("salt"), 1000, len(want), Hash()); !bytes.Equal(got, want) {
+		t.Errorf("pbkdf2.Key(Hash()) = %x, want %x", got, want)
+	}
+}
@@ -0,0 +1,31 @@
+package sm3
+
+import (
+	"hash"
+	"sync"
+)
+
+var hasherPool = sync.Pool{
+	New: func() any { return New() },
+}
+
+// GetHasher returns a reset SM3 hash.Hash from a package-wide pool. Return
+// it with PutHasher once its Sum has been taken; it must not be used after
+// that.
+func GetHasher() hash.Hash {
+	h := hasherPool.Get().(hash.Hash)
+	h.Reset()
+	return h
+}
+
+// PutHasher resets h and returns it to the pool used by GetHasher. Only
+// hashes created by New or GetHasher are pooled; nil and any other
+// hash.Hash, including the package's other constructions, are ignored.
+func PutHasher(h hash.Hash) {
+	d, ok := h.(*digest)
+	if !ok || d == nil {
+		return
+	}
+	d.Reset()
+	hasherPool.Put(d)
+}
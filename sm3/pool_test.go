@@ -0,0 +1,63 @@
+package sm3
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"sync"
+	"testing"
+)
+
+func TestHasherPool(t *testing.T) {
+	h := GetHasher()
+	h.Write([]byte("abc"))
+	if got, want := h.Sum(nil), Sum([]byte("abc")); !bytes.Equal(got, want[:]) {
+		t.Errorf("pooled hasher Sum = %x, want %x", got, want)
+	}
+	h.Write([]byte("left over state"))
+	PutHasher(h)
+	if got, want := GetHasher().Sum(nil), Sum(nil); !bytes.Equal(got, want[:]) {
+		t.Errorf("hasher from pool is not reset: Sum = %x, want %x", got, want)
+	}
+
+	// None of these may panic or end up in the pool.
+	var nilDigest *digest
+	for _, h := range []hash.Hash{nil, nilDigest, sha256.New(), NewHMAC([]byte("k")), NewReusable()} {
+		PutHasher(h)
+	}
+	for i := 0; i < 10; i++ {
+		if _, ok := GetHasher().(*digest); !ok {
+			t.Fatal("GetHasher returned a foreign hasher")
+		}
+	}
+}
+
+// TestHasherPoolConcurrent is meant to be run with -race.
+func TestHasherPoolConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	errs := make(chan error, 32)
+	for g := 0; g < 32; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				msg := []byte(fmt.Sprintf("goroutine %d message %d", g, i))
+				h := GetHasher()
+				h.Write(msg[:len(msg)/2])
+				h.Write(msg[len(msg)/2:])
+				got := h.Sum(nil)
+				PutHasher(h)
+				if want := Sum(msg); !bytes.Equal(got, want[:]) {
+					errs <- fmt.Errorf("%q: got %x, want %x", msg, got, want)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
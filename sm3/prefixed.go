@@ -0,0 +1,25 @@
+package sm3
+
+import "hash"
+
+// NewPrefixed absorbs prefix once and returns a constructor for hashers
+// that start from the resulting state. Each call to the returned function
+// yields an independent hash.Hash equivalent to New followed by
+// Write(prefix), without recompressing the prefix. Reset on such a hasher
+// returns it to the state just after the prefix.
+func NewPrefixed(prefix []byte) func() hash.Hash {
+	var base digest
+	base.Reset()
+	base.Write(prefix)
+	return func() hash.Hash {
+		return &prefixed{digest: base, base: &base}
+	}
+}
+
+// prefixed is a digest whose Reset restores a cached post-prefix state.
+type prefixed struct {
+	digest
+	base *digest
+}
+
+func (p *prefixed) Reset() { p.digest = *p.base }
@@ -0,0 +1,51 @@
+package sm3
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNewPrefixed(t *testing.T) {
+	for _, n := range []int{0, 5, BlockSize, 3*BlockSize + 9} {
+		prefix := bytes.Repeat([]byte{'p'}, n)
+		newHash := NewPrefixed(prefix)
+		for _, suffix := range []string{"", "x", "variable suffix spanning more than a single sixty-four byte block of input"} {
+			want := Sum(append(append([]byte(nil), prefix...), suffix...))
+
+			h := newHash()
+			h.Write([]byte(suffix))
+			if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+				t.Errorf("prefix %d, suffix %q: Sum = %x, want %x", n, suffix, got, want)
+			}
+			h.Reset()
+			h.Write([]byte(suffix))
+			if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+				t.Errorf("prefix %d, suffix %q: Sum after Reset = %x, want %x", n, suffix, got, want)
+			}
+		}
+	}
+}
+
+func TestNewPrefixedIndependent(t *testing.T) {
+	newHash := NewPrefixed([]byte("shared prefix"))
+	a, b := newHash(), newHash()
+	a.Write([]byte("a"))
+	b.Write([]byte("b"))
+	if got, want := a.Sum(nil), Sum([]byte("shared prefixa")); !bytes.Equal(got, want[:]) {
+		t.Errorf("first hasher = %x, want %x", got, want)
+	}
+	if got, want := b.Sum(nil), Sum([]byte("shared prefixb")); !bytes.Equal(got, want[:]) {
+		t.Errorf("second hasher = %x, want %x", got, want)
+	}
+}
+
+func BenchmarkPrefixed(b *testing.B) {
+	newHash := NewPrefixed(buf[:4096])
+	suffix := buf[:64]
+	b.SetBytes(int64(len(suffix)))
+	for i := 0; i < b.N; i++ {
+		h := newHash()
+		h.Write(suffix)
+		h.Sum(nil)
+	}
+}
@@ -0,0 +1,33 @@
+package sm3
+
+import "io"
+
+// prefixHasher is an io.Writer hashing only the first bytes written to it.
+type prefixHasher struct {
+	d    digest
+	left int // prefix bytes still to absorb
+}
+
+// NewPrefixHasher returns a writer that hashes the first prefixLen bytes
+// written to it with SM3 and discards the rest, and a function returning
+// the digest of the prefix absorbed so far, which is shorter than
+// prefixLen if fewer bytes have been written. It fingerprints large
+// objects by a bounded prefix. Write reports every byte as written, so the
+// count from io.Copy is the total length, not the prefix length. A
+// negative prefixLen is treated as zero. The writer is not safe for
+// concurrent use.
+func NewPrefixHasher(prefixLen int) (io.Writer, func() [Size]byte) {
+	p := &prefixHasher{left: max(prefixLen, 0)}
+	p.d.Reset()
+	return p, func() [Size]byte {
+		d0 := p.d
+		return d0.checkSum()
+	}
+}
+
+func (p *prefixHasher) Write(b []byte) (int, error) {
+	n := min(len(b), p.left)
+	p.d.Write(b[:n])
+	p.left -= n
+	return len(b), nil
+}
@@ -0,0 +1,28 @@
+package sm3
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestPrefixHasher(t *testing.T) {
+	data := treeTestData(300)
+	const prefixLen = 100
+	for _, n := range []int{0, 50, prefixLen - 1, prefixLen, prefixLen + 1, 300} {
+		w, sum := NewPrefixHasher(prefixLen)
+		written, err := io.Copy(w, io.MultiReader(bytes.NewReader(data[:n/2]), bytes.NewReader(data[n/2:n])))
+		if err != nil || written != int64(n) {
+			t.Errorf("%d bytes: io.Copy = %d, %v; want the total %d", n, written, err, n)
+		}
+		if got, want := sum(), Sum(data[:min(n, prefixLen)]); got != want {
+			t.Errorf("%d bytes: digest %x, want the digest of the first %d bytes %x", n, got, min(n, prefixLen), want)
+		}
+	}
+
+	w, sum := NewPrefixHasher(-5)
+	w.Write([]byte("ignored"))
+	if sum() != Sum(nil) {
+		t.Error("negative prefix length absorbed data")
+	}
+}
@@ -0,0 +1,17 @@
+package sm3
+
+// InsecurePrefixMAC returns SM3(key || msg).
+//
+// It is NOT a secure MAC and exists only to demonstrate why: SM3, like
+// SHA-256, is a Merkle–Damgård hash, so the tag is the full chaining value
+// after key || msg || padding. Anyone holding a tag and knowing len(key)
+// can resume hashing from it and tag msg || padding || ext for any ext
+// without the key; TestLengthExtension performs the forgery. Use NewHMAC
+// for message authentication.
+func InsecurePrefixMAC(key, msg []byte) [Size]byte {
+	var d digest
+	d.Reset()
+	d.Write(key)
+	d.Write(msg)
+	return d.checkSum()
+}
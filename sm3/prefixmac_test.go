@@ -0,0 +1,50 @@
+package sm3
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// extend forges a tag for msg || glue || ext from the tag over an unknown
+// keyLen-byte key and msg, as a length-extension attacker would. It returns
+// the forged message (without the key) and its tag.
+func extend(t *testing.T, tag [Size]byte, keyLen int, msg, ext []byte) ([]byte, [Size]byte) {
+	t.Helper()
+	glue := appendPadding(nil, uint64(keyLen+len(msg))<<3)
+	var h [8]uint32
+	for i := range h {
+		h[i] = binary.BigEndian.Uint32(tag[4*i:])
+	}
+	d, err := NewFromState(h, uint64(keyLen+len(msg)+len(glue)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Write(ext)
+	var forged [Size]byte
+	d.Sum(forged[:0])
+	return append(append(append([]byte(nil), msg...), glue...), ext...), forged
+}
+
+func TestLengthExtension(t *testing.T) {
+	key := []byte("server-side secret")
+	msg := []byte("user=alice&role=user")
+	ext := []byte("&role=admin")
+
+	// The attacker sees only msg, its tag and len(key).
+	forgedMsg, forgedTag := extend(t, InsecurePrefixMAC(key, msg), len(key), msg, ext)
+	if InsecurePrefixMAC(key, forgedMsg) != forgedTag {
+		t.Fatal("length extension failed to forge InsecurePrefixMAC")
+	}
+
+	// The same attack against HMAC-SM3 yields a tag the verifier rejects.
+	mac := NewHMAC(key)
+	mac.Write(msg)
+	var tag [Size]byte
+	mac.Sum(tag[:0])
+	forgedMsg, forgedTag = extend(t, tag, len(key), msg, ext)
+	mac.Reset()
+	mac.Write(forgedMsg)
+	if mac.Verify(forgedTag[:]) {
+		t.Fatal("length extension forged an HMAC-SM3 tag")
+	}
+}
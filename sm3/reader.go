@@ -0,0 +1,86 @@
+package sm3
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// SumReaderLimit returns the SM3 checksum of at most n bytes read from r,
+// along with the number of bytes hashed.
+//
+// If r holds more than n bytes the digest of the first n is returned
+// together with ErrTruncated. Detecting this consumes one byte past the
+// limit from r.
+func SumReaderLimit(r io.Reader, n int64) (digest [Size]byte, read int64, err error) {
+	if n < 0 {
+		n = 0
+	}
+	h := New()
+	read, err = io.Copy(h, &io.LimitedReader{R: r, N: n})
+	h.Sum(digest[:0])
+	if err != nil {
+		return digest, read, err
+	}
+	if read == n {
+		var probe [1]byte
+		switch _, perr := io.ReadFull(r, probe[:]); perr {
+		case nil:
+			err = ErrTruncated
+		case io.EOF:
+		default:
+			err = perr
+		}
+	}
+	return digest, read, err
+}
+
+// SumReaderBuf returns the SM3 checksum of everything read from r until
+// EOF, reading through the caller's buf so that chunk size and allocation
+// stay under the caller's control. Read errors other than io.EOF are
+// returned; buf must not be empty.
+func SumReaderBuf(r io.Reader, buf []byte) ([Size]byte, error) {
+	if len(buf) == 0 {
+		return [Size]byte{}, fmt.Errorf("%w: empty read buffer", ErrBadLength)
+	}
+	var d digest
+	d.Reset()
+	for {
+		n, err := r.Read(buf)
+		d.Write(buf[:n])
+		if err == io.EOF {
+			return d.checkSum(), nil
+		}
+		if err != nil {
+			return [Size]byte{}, err
+		}
+	}
+}
+
+// SumReaderWithSize returns the SM3 checksum of everything read from r
+// until EOF together with the number of bytes read, in a single pass.
+func SumReaderWithSize(r io.Reader) (sum [Size]byte, size int64, err error) {
+	var d digest
+	d.Reset()
+	size, err = io.Copy(&d, r)
+	if err != nil {
+		return sum, size, err
+	}
+	return d.checkSum(), size, nil
+}
+
+// SumAndBuffer reads r to EOF and returns the SM3 checksum of its contents
+// together with a reader that replays exactly those bytes, so a
+// non-seekable source can be hashed and then processed without reading it
+// twice. The whole stream is held in memory; bound r, for example with
+// io.LimitReader, if its size is not trusted.
+func SumAndBuffer(r io.Reader) ([Size]byte, io.Reader, error) {
+	var d digest
+	d.Reset()
+	var b bytes.Buffer
+	if _, err := b.ReadFrom(r); err != nil {
+		return [Size]byte{}, nil, err
+	}
+	d.Write(b.Bytes())
+	return d.checkSum(), bytes.NewReader(b.Bytes()), nil
+}
@@ -0,0 +1,121 @@
+package sm3
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestSumReaderLimit(t *testing.T) {
+	data := bytes.Repeat([]byte("sm3 reader "), 20)
+	for _, tt := range []struct {
+		name    string
+		limit   int64
+		read    int64
+		wantErr error
+	}{
+		{"shorter", int64(len(data)) + 10, int64(len(data)), nil},
+		{"equal", int64(len(data)), int64(len(data)), nil},
+		{"longer", 100, 100, ErrTruncated},
+		{"zero", 0, 0, ErrTruncated},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			digest, read, err := SumReaderLimit(bytes.NewReader(data), tt.limit)
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if read != tt.read {
+				t.Errorf("read = %d, want %d", read, tt.read)
+			}
+			if want := Sum(data[:read]); digest != want {
+				t.Errorf("digest = %x, want %x", digest, want)
+			}
+		})
+	}
+
+	if _, _, err := SumReaderLimit(bytes.NewReader(nil), 0); err != nil {
+		t.Errorf("empty stream with zero limit: err = %v", err)
+	}
+
+	readErr := errors.New("read failed")
+	if _, _, err := SumReaderLimit(iotest.ErrReader(readErr), 10); err != readErr {
+		t.Errorf("err = %v, want %v", err, readErr)
+	}
+}
+
+func TestSumReaderBuf(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789abcdef"), 300)
+	want := Sum(data)
+	for _, size := range []int{1, 63, BlockSize, 4096, 2 * len(data)} {
+		got, err := SumReaderBuf(bytes.NewReader(data), make([]byte, size))
+		if err != nil {
+			t.Fatalf("buffer %d: %v", size, err)
+		}
+		if got != want {
+			t.Errorf("buffer %d: digest = %x, want %x", size, got, want)
+		}
+	}
+	if got, err := SumReaderBuf(iotest.OneByteReader(bytes.NewReader(data)), make([]byte, 100)); err != nil || got != want {
+		t.Errorf("one-byte reads: (%x, %v), want (%x, nil)", got, err, want)
+	}
+	if got, err := SumReaderBuf(iotest.DataErrReader(bytes.NewReader(data)), make([]byte, 100)); err != nil || got != want {
+		t.Errorf("data with EOF: (%x, %v), want (%x, nil)", got, err, want)
+	}
+
+	readErr := errors.New("read failed")
+	if _, err := SumReaderBuf(iotest.TimeoutReader(bytes.NewReader(data)), make([]byte, 10)); !errors.Is(err, iotest.ErrTimeout) {
+		t.Errorf("err = %v, want %v", err, iotest.ErrTimeout)
+	}
+	if _, err := SumReaderBuf(iotest.ErrReader(readErr), make([]byte, 10)); err != readErr {
+		t.Errorf("err = %v, want %v", err, readErr)
+	}
+	if _, err := SumReaderBuf(bytes.NewReader(data), nil); !errors.Is(err, ErrBadLength) {
+		t.Errorf("empty buffer: err = %v, want %v", err, ErrBadLength)
+	}
+}
+
+func TestSumReaderWithSize(t *testing.T) {
+	for _, n := range []int{0, 1, 55, 64, 1000, 100000} {
+		data := bytes.Repeat([]byte{'s'}, n)
+		digest, size, err := SumReaderWithSize(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("%d bytes: %v", n, err)
+		}
+		if size != int64(n) {
+			t.Errorf("%d bytes: size = %d", n, size)
+		}
+		if want := Sum(data); digest != want {
+			t.Errorf("%d bytes: digest = %x, want %x", n, digest, want)
+		}
+	}
+
+	readErr := errors.New("read failed")
+	if _, _, err := SumReaderWithSize(iotest.ErrReader(readErr)); err != readErr {
+		t.Errorf("err = %v, want %v", err, readErr)
+	}
+}
+
+func TestSumAndBuffer(t *testing.T) {
+	for _, n := range []int{0, 1, 100, 70000} {
+		data := bytes.Repeat([]byte("replay"), n)[:n]
+		// OneByteReader hides the source's type, as for a network stream.
+		digest, replay, err := SumAndBuffer(iotest.OneByteReader(bytes.NewReader(data)))
+		if err != nil {
+			t.Fatalf("%d bytes: %v", n, err)
+		}
+		if want := Sum(data); digest != want {
+			t.Errorf("%d bytes: digest = %x, want %x", n, digest, want)
+		}
+		got, err := io.ReadAll(replay)
+		if err != nil || !bytes.Equal(got, data) {
+			t.Errorf("%d bytes: replay = %d bytes, %v", n, len(got), err)
+		}
+	}
+
+	readErr := errors.New("read failed")
+	if _, replay, err := SumAndBuffer(iotest.ErrReader(readErr)); err != readErr || replay != nil {
+		t.Errorf("SumAndBuffer = %v, %v, want nil, %v", replay, err, readErr)
+	}
+}
@@ -0,0 +1,93 @@
+package sm3
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+func TestRecordHasherDistinct(t *testing.T) {
+	records := map[string]*RecordHasher{
+		"empty":             NewRecordHasher(),
+		`string "1"`:        NewRecordHasher().AddString("1"),
+		"uint64 1":          NewRecordHasher().AddUint64(1),
+		`bytes "1"`:         NewRecordHasher().AddBytes([]byte("1")),
+		"bool true":         NewRecordHasher().AddBool(true),
+		"bool false":        NewRecordHasher().AddBool(false),
+		"uint64 0":          NewRecordHasher().AddUint64(0),
+		"int64 1":           NewRecordHasher().AddInt64(1),
+		"int64 -1":          NewRecordHasher().AddInt64(-1),
+		"uint64 max":        NewRecordHasher().AddUint64(1<<64 - 1),
+		`string ""`:         NewRecordHasher().AddString(""),
+		`bytes ""`:          NewRecordHasher().AddBytes(nil),
+		`strings "ab", ""`:  NewRecordHasher().AddString("ab").AddString(""),
+		`strings "a", "b"`:  NewRecordHasher().AddString("a").AddString("b"),
+		`string "ab"`:       NewRecordHasher().AddString("ab"),
+		"uint64 1, bool":    NewRecordHasher().AddUint64(1).AddBool(true),
+		"bool, uint64 1":    NewRecordHasher().AddBool(true).AddUint64(1),
+		"string of uint64":  NewRecordHasher().AddString(string(binary.BigEndian.AppendUint64(nil, 1))),
+		"bytes of uint64 1": NewRecordHasher().AddBytes(binary.BigEndian.AppendUint64(nil, 1)),
+	}
+	seen := make(map[[Size]byte]string)
+	for name, r := range records {
+		sum := r.Sum()
+		if other, ok := seen[sum]; ok {
+			t.Errorf("%s and %s have the same digest", name, other)
+		}
+		seen[sum] = name
+	}
+}
+
+func TestRecordHasherEncoding(t *testing.T) {
+	r := NewRecordHasher().AddUint64(7).AddString("hi").AddBool(true)
+	want := Sum([]byte{
+		1, 0, 0, 0, 0, 0, 0, 0, 8, 0, 0, 0, 0, 0, 0, 0, 7,
+		2, 0, 0, 0, 0, 0, 0, 0, 2, 'h', 'i',
+		4, 0, 0, 0, 0, 0, 0, 0, 1, 1,
+	})
+	if got := r.Sum(); got != want {
+		t.Errorf("Sum = %x, want %x", got, want)
+	}
+	if r.Sum() != want {
+		t.Error("second Sum differs")
+	}
+	r.AddBytes([]byte("more"))
+	if r.Sum() == want {
+		t.Error("adding a field after Sum did not change the digest")
+	}
+	if r.Reset(); r.Sum() != NewRecordHasher().Sum() {
+		t.Error("Reset did not return to the empty record")
+	}
+}
+
+func TestRecordHasherTime(t *testing.T) {
+	instant := time.Date(2024, 3, 1, 12, 30, 0, 500, time.UTC)
+	shanghai := time.FixedZone("CST", 8*60*60)
+	newYork := time.FixedZone("EST", -5*60*60)
+	want := NewRecordHasher().AddTime(instant).Sum()
+	for _, tm := range []time.Time{instant.In(shanghai), instant.In(newYork), instant.Local()} {
+		if got := NewRecordHasher().AddTime(tm).Sum(); got != want {
+			t.Errorf("AddTime(%v) = %x, want the digest of the same UTC instant %x", tm, got, want)
+		}
+	}
+	// Wall-clock fields that differ only by zone are different instants.
+	if NewRecordHasher().AddTime(time.Date(2024, 3, 1, 12, 30, 0, 500, shanghai)).Sum() == want {
+		t.Error("different instants hash alike")
+	}
+	if NewRecordHasher().AddTime(instant).Sum() == NewRecordHasher().AddUint64(uint64(instant.UnixNano())).Sum() {
+		t.Error("a time and a uint64 of its nanoseconds hash alike")
+	}
+}
+
+func TestRecordHasherUUID(t *testing.T) {
+	u := [16]byte{0x01, 0x8e, 0x2b, 0x4c, 0x7a, 0x3d, 0x7f, 0x00, 0x80, 0x00, 1, 2, 3, 4, 5, 6}
+	got := NewRecordHasher().AddUUID(u).Sum()
+	if got == NewRecordHasher().AddBytes(u[:]).Sum() {
+		t.Error("a UUID and its bytes hash alike")
+	}
+	v := u
+	v[15]++
+	if got == NewRecordHasher().AddUUID(v).Sum() {
+		t.Error("different UUIDs hash alike")
+	}
+}
@@ -0,0 +1,59 @@
+package sm3
+
+import (
+	"fmt"
+	"io"
+)
+
+// Digest is a concrete SM3 hash. It implements hash.Hash, and because it
+// is a named pointer type rather than an interface value it can be kept and
+// reused across many messages: call Reset between them and pass a reused
+// slice to Sum, and hashing allocates nothing.
+type Digest struct {
+	digest
+}
+
+// NewReusable returns a new Digest in its initial state.
+func NewReusable() *Digest {
+	d := new(Digest)
+	d.Reset()
+	return d
+}
+
+// Clone returns an independent copy of d, which continues from the same
+// state.
+func (d *Digest) Clone() *Digest {
+	c := *d
+	return &c
+}
+
+// Checksum returns the checksum of the data written so far, like Sum(nil)
+// but as a Sum256 value. It does not change d, which can keep absorbing
+// data.
+func (d *Digest) Checksum() Sum256 {
+	sum := d.digest
+	return sum.checkSum()
+}
+
+// Absorb reads exactly n bytes from r and writes them to d, which suits
+// length-prefixed wire formats whose field sizes are known. If r ends
+// before n bytes, even before the first, it returns io.ErrUnexpectedEOF,
+// and the bytes read so far have already been absorbed.
+func (d *Digest) Absorb(r io.Reader, n int) error {
+	if n < 0 {
+		return fmt.Errorf("%w: negative length %d", ErrBadLength, n)
+	}
+	var buf [8 * BlockSize]byte
+	for read := 0; read < n; {
+		m, err := io.ReadFull(r, buf[:min(n-read, len(buf))])
+		d.Write(buf[:m])
+		read += m
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
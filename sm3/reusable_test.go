@@ -0,0 +1,149 @@
+package sm3
+
+import (
+	"bytes"
+	"errors"
+	"hash"
+	"io"
+	"testing"
+)
+
+func TestReusable(t *testing.T) {
+	d := NewReusable()
+	for _, msg := range []string{"abc", "", "a longer message that spills over one sixty-four byte block"} {
+		d.Reset()
+		d.Write([]byte(msg))
+		if got, want := d.Sum(nil), Sum([]byte(msg)); !bytes.Equal(got, want[:]) {
+			t.Errorf("Sum(%q) = %x, want %x", msg, got, want)
+		}
+	}
+}
+
+func TestReusableClone(t *testing.T) {
+	d := NewReusable()
+	d.Write([]byte("shared "))
+	c := d.Clone()
+	d.Write([]byte("one"))
+	c.Write([]byte("two"))
+	if got, want := d.Sum(nil), Sum([]byte("shared one")); !bytes.Equal(got, want[:]) {
+		t.Errorf("original = %x, want %x", got, want)
+	}
+	if got, want := c.Sum(nil), Sum([]byte("shared two")); !bytes.Equal(got, want[:]) {
+		t.Errorf("clone = %x, want %x", got, want)
+	}
+}
+
+func TestDigestChecksum(t *testing.T) {
+	msg := []byte("abc")
+	d := NewReusable()
+	d.Write(msg)
+	want := Sum(msg)
+
+	sum := d.Checksum()
+	if sum != want {
+		t.Fatalf("Checksum = %x, want %x", sum, want)
+	}
+	b := sum.Bytes()
+	if !bytes.Equal(b, want[:]) {
+		t.Fatalf("Bytes = %x, want %x", b, want)
+	}
+	b[0] ^= 0xff
+	if sum != want {
+		t.Errorf("Checksum after mutating its Bytes = %x, want %x", sum, want)
+	}
+	if got, want := sum.Hex(), SumHex(msg); got != want {
+		t.Errorf("Hex = %s, want %s", got, want)
+	}
+	if !Equal(d.Checksum(), want) || IsEmpty(d.Checksum()) || XORDigests(d.Checksum(), want) != (Sum256{}) {
+		t.Error("Checksum is not accepted as the Sum256 of Sum")
+	}
+
+	// Checksum does not finalize d.
+	d.Write(msg)
+	if got, want := d.Checksum().Hex(), SumHex([]byte("abcabc")); got != want {
+		t.Errorf("Checksum after a further Write = %s, want %s", got, want)
+	}
+}
+
+func TestReusableAllocs(t *testing.T) {
+	d := NewReusable()
+	sum := make([]byte, 0, Size)
+	if n := testing.AllocsPerRun(10, func() {
+		d.Reset()
+		d.Write(buf[:1024])
+		d.Sum(sum[:0])
+	}); n > 0 {
+		t.Errorf("reused Digest allocates %v times per message, want 0", n)
+	}
+}
+
+// sinkHash keeps the hashers in BenchmarkNewPerMessage on the heap, as
+// they are for a caller that stores or passes them on.
+var sinkHash hash.Hash
+
+func BenchmarkNewPerMessage(b *testing.B) {
+	sum := make([]byte, 0, Size)
+	b.ReportAllocs()
+	b.SetBytes(64)
+	for i := 0; i < b.N; i++ {
+		h := New()
+		h.Write(buf[:64])
+		h.Sum(sum[:0])
+		sinkHash = h
+	}
+}
+
+func BenchmarkReusable(b *testing.B) {
+	d := NewReusable()
+	sum := make([]byte, 0, Size)
+	b.ReportAllocs()
+	b.SetBytes(64)
+	for i := 0; i < b.N; i++ {
+		d.Reset()
+		d.Write(buf[:64])
+		d.Sum(sum[:0])
+	}
+}
+
+func TestAbsorb(t *testing.T) {
+	// A sequence of fields, each a one-byte length followed by the data.
+	fields := [][]byte{[]byte("header"), nil, bytes.Repeat([]byte{'b'}, 200), []byte("trailer")}
+	var wire []byte
+	for _, f := range fields {
+		wire = append(wire, byte(len(f)))
+		wire = append(wire, f...)
+	}
+
+	want := NewReusable()
+	for _, f := range fields {
+		want.Write(f)
+	}
+
+	d := NewReusable()
+	r := bytes.NewReader(wire)
+	for {
+		l, err := r.ReadByte()
+		if err != nil {
+			break
+		}
+		if err := d.Absorb(r, int(l)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := d.Sum(nil); !bytes.Equal(got, want.Sum(nil)) {
+		t.Errorf("Absorb digest = %x, want %x", got, want.Sum(nil))
+	}
+
+	if err := d.Absorb(bytes.NewReader([]byte("short")), 10); err != io.ErrUnexpectedEOF {
+		t.Errorf("short field: err = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+	if err := d.Absorb(bytes.NewReader(nil), 1); err != io.ErrUnexpectedEOF {
+		t.Errorf("empty reader: err = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+	if err := d.Absorb(bytes.NewReader(nil), 0); err != nil {
+		t.Errorf("zero-length field: err = %v", err)
+	}
+	if err := d.Absorb(bytes.NewReader(nil), -1); !errors.Is(err, ErrBadLength) {
+		t.Errorf("negative length: err = %v, want %v", err, ErrBadLength)
+	}
+}
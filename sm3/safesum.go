@@ -0,0 +1,22 @@
+package sm3
+
+import "fmt"
+
+// SafeSum is Sum for servers that must not crash on any input: a panic
+// while hashing is recovered and returned as an error wrapping
+// ErrInvalidState, with a zero digest. Sum has no known panics, so today
+// SafeSum always returns Sum(data) and a nil error; it states the contract
+// for callers that want it in writing.
+func SafeSum(data []byte) ([Size]byte, error) {
+	return safeSum(Sum, data)
+}
+
+// safeSum calls sum on data, turning a panic into an error.
+func safeSum(sum func([]byte) [Size]byte, data []byte) (digest [Size]byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			digest, err = [Size]byte{}, fmt.Errorf("%w: panic while hashing: %v", ErrInvalidState, r)
+		}
+	}()
+	return sum(data), nil
+}
@@ -0,0 +1,21 @@
+package sm3
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSafeSum(t *testing.T) {
+	data := treeTestData(3*BlockSize + 1)
+	for n := 0; n <= len(data); n++ {
+		got, err := SafeSum(data[:n])
+		if err != nil || got != Sum(data[:n]) {
+			t.Fatalf("SafeSum(%d bytes) = %x, %v, want %x, nil", n, got, err, Sum(data[:n]))
+		}
+	}
+
+	panicky := func([]byte) [Size]byte { panic("index out of range") }
+	if got, err := safeSum(panicky, data); !errors.Is(err, ErrInvalidState) || got != ([Size]byte{}) {
+		t.Errorf("safeSum(panicking) = %x, %v, want zero digest and %v", got, err, ErrInvalidState)
+	}
+}
@@ -0,0 +1,30 @@
+package sm3
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"testing"
+)
+
+func TestSeed64(t *testing.T) {
+	label := []byte("scenario-7")
+	sum := Sum(label)
+	seed := Seed64(label)
+	if want := int64(binary.BigEndian.Uint64(sum[:8])); seed != want {
+		t.Fatalf("Seed64 = %d, want %d", seed, want)
+	}
+	if Seed64(label) != seed {
+		t.Error("Seed64 is not stable")
+	}
+	if Seed64([]byte("scenario-8")) == seed {
+		t.Error("different labels gave the same seed")
+	}
+
+	r1 := rand.New(rand.NewSource(Seed64(label)))
+	r2 := rand.New(rand.NewSource(Seed64(label)))
+	for i := 0; i < 100; i++ {
+		if a, b := r1.Int63(), r2.Int63(); a != b {
+			t.Fatalf("draw %d: %d != %d", i, a, b)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package sm3
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// selfTestVectors are the known answers checked by selfTest: the two
+// examples of GB/T 32905-2016 Appendix A, one and two blocks long.
+var selfTestVectors = []struct{ in, out string }{
+	{"abc", "66c7f0f462eeedd9d1f2d46bdc10e4e24167c4875cf2f7a2297da02b8f4ba8e0"},
+	{strings.Repeat("abcd", 16), "debe9ff92275b8a138604889c18e5a4d6fdb70e5387e5765293dcba39c0c5732"},
+}
+
+// selfTest runs the known-answer test against sum and reports the first
+// vector it gets wrong. Built with the selftest tag, the package runs it
+// on Sum from init and panics on failure; see selftest_init.go.
+func selfTest(sum func([]byte) [Size]byte) error {
+	for _, v := range selfTestVectors {
+		got := sum([]byte(v.in))
+		if hex.EncodeToString(got[:]) != v.out {
+			return fmt.Errorf("sm3: self-test failed on %d-byte input: got %x, want %s", len(v.in), got, v.out)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+//go:build selftest
+
+package sm3
+
+// selfTestPassed records that the power-on self-test ran, for the tagged
+// test to check.
+var selfTestPassed bool
+
+// With the selftest build tag the package refuses to start with a broken
+// SM3 implementation, in the manner of a FIPS 140 power-on self-test.
+// Without it there is no startup cost.
+func init() {
+	if err := selfTest(Sum); err != nil {
+		panic(err)
+	}
+	selfTestPassed = true
+}
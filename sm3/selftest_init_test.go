@@ -0,0 +1,11 @@
+//go:build selftest
+
+package sm3
+
+import "testing"
+
+func TestSelfTestInit(t *testing.T) {
+	if !selfTestPassed {
+		t.Fatal("selftest build tag set but the init self-test did not run")
+	}
+}
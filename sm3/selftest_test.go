@@ -0,0 +1,57 @@
+package sm3
+
+import (
+	"encoding/binary"
+	"math/bits"
+	"os/exec"
+	"testing"
+)
+
+func TestSelfTest(t *testing.T) {
+	if err := selfTest(Sum); err != nil {
+		t.Fatal(err)
+	}
+
+	// A block function that miscomputes a single word of the chaining
+	// value, as a miscompiled assembly path might, must trip the test.
+	broken := func(d *digest, p []byte) {
+		block(d, p)
+		d.h[5] = bits.RotateLeft32(d.h[5], 1)
+	}
+	if selfTest(sumWith(block)) != nil {
+		t.Fatal("sumWith(block) fails the self-test")
+	}
+	if err := selfTest(sumWith(broken)); err == nil {
+		t.Error("self-test passed with a broken block function")
+	}
+}
+
+// sumWith returns a one-shot SM3 built on blockFn in place of block.
+func sumWith(blockFn func(*digest, []byte)) func([]byte) [Size]byte {
+	return func(data []byte) [Size]byte {
+		var d digest
+		d.Reset()
+		blockFn(&d, padMessage(data))
+		var out [Size]byte
+		for i, s := range d.h {
+			binary.BigEndian.PutUint32(out[4*i:], s)
+		}
+		return out
+	}
+}
+
+// TestSelfTestBuildTag builds and runs the package tests with the selftest
+// tag, so the init-time self-test is exercised by a plain go test.
+func TestSelfTestBuildTag(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping tagged rebuild in short mode")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found")
+	}
+	out, err := exec.Command(goTool, "test", "-tags", "selftest", "-run", "^TestSelfTest(Init)?$", ".").CombinedOutput()
+	if err != nil {
+		t.Fatalf("go test -tags selftest: %v\n%s", err, out)
+	}
+}
@@ -0,0 +1,56 @@
+package sm3
+
+import (
+	"encoding/binary"
+	"hash"
+	"math"
+)
+
+// Shuffle permutes n elements by calling swap, like math/rand.Shuffle, but
+// draws its randomness from SM3 in counter mode keyed by seed, so the same
+// seed always gives the same permutation on every platform and Go release.
+// It is meant for reproducible test data and load spreading; seeds are
+// often guessable, so it must not be used where an adversary must not
+// predict the order.
+func Shuffle(n int, seed []byte, swap func(i, j int)) {
+	if n < 0 {
+		panic("sm3: Shuffle called with negative n")
+	}
+	s := shuffleStream{newHash: NewPrefixed(seed)}
+	for i := n - 1; i > 0; i-- {
+		swap(i, int(s.uniform(uint64(i)+1)))
+	}
+}
+
+// shuffleStream yields the words of SM3(seed || ctr) for ctr = 0, 1, ...
+type shuffleStream struct {
+	newHash func() hash.Hash
+	ctr     uint64
+	block   [Size]byte
+	off     int
+}
+
+func (s *shuffleStream) next() uint64 {
+	if s.off == 0 {
+		h := s.newHash()
+		var ctr [8]byte
+		binary.BigEndian.PutUint64(ctr[:], s.ctr)
+		h.Write(ctr[:])
+		h.Sum(s.block[:0])
+		s.ctr++
+	}
+	v := binary.BigEndian.Uint64(s.block[s.off:])
+	s.off = (s.off + 8) % Size
+	return v
+}
+
+// uniform returns a uniformly distributed value in [0, m), rejecting the
+// top partial range of uint64 to avoid modulo bias.
+func (s *shuffleStream) uniform(m uint64) uint64 {
+	limit := math.MaxUint64 - math.MaxUint64%m
+	for {
+		if v := s.next(); v < limit {
+			return v % m
+		}
+	}
+}
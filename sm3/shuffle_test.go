@@ -0,0 +1,59 @@
+package sm3
+
+import (
+	"slices"
+	"testing"
+)
+
+func shuffled(n int, seed string) []int {
+	p := make([]int, n)
+	for i := range p {
+		p[i] = i
+	}
+	Shuffle(n, []byte(seed), func(i, j int) { p[i], p[j] = p[j], p[i] })
+	return p
+}
+
+func TestShuffle(t *testing.T) {
+	a, b := shuffled(100, "seed"), shuffled(100, "seed")
+	if !slices.Equal(a, b) {
+		t.Error("same seed gave different permutations")
+	}
+	if c := shuffled(100, "seed2"); slices.Equal(a, c) {
+		t.Error("different seeds gave the same permutation")
+	}
+	sorted := slices.Clone(a)
+	slices.Sort(sorted)
+	for i, v := range sorted {
+		if v != i {
+			t.Fatalf("result is not a permutation: %v", a)
+		}
+	}
+	// Pin the output so that changes to the stream are noticed.
+	if got, want := shuffled(8, "pinned"), []int{0, 3, 6, 5, 7, 2, 1, 4}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	shuffled(0, "")
+	shuffled(1, "")
+}
+
+func TestShuffleUniform(t *testing.T) {
+	// Every element of a 4-element shuffle should land in each position
+	// about a quarter of the time over many seeds.
+	var counts [4][4]int
+	const trials = 4000
+	for s := 0; s < trials; s++ {
+		p := shuffled(4, string(rune(s))+"uniform")
+		for pos, v := range p {
+			counts[v][pos]++
+		}
+	}
+	for v := range counts {
+		for pos, c := range counts[v] {
+			if c < trials/4-200 || c > trials/4+200 {
+				t.Errorf("element %d at position %d %d times out of %d", v, pos, c, trials)
+			}
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package sm3
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+)
+
+// SM2 is the elliptic curve public key algorithm of GB/T 32918 (GM/T 0003).
+// Its hash function is SM3, which is why it lives alongside it here.
+
+var (
+	sm2InitOnce sync.Once
+	sm2Params   *elliptic.CurveParams
+)
+
+func initSM2() {
+	sm2Params = &elliptic.CurveParams{Name: "sm2p256v1", BitSize: 256}
+	sm2Params.P, _ = new(big.Int).SetString("FFFFFFFEFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF00000000FFFFFFFFFFFFFFFF", 16)
+	sm2Params.N, _ = new(big.Int).SetString("FFFFFFFEFFFFFFFFFFFFFFFFFFFFFFFF7203DF6B21C6052B53BBF40939D54123", 16)
+	sm2Params.B, _ = new(big.Int).SetString("28E9FA9E9D9F5E344D5A9E4BCF6509A7F39789F515AB8F92DDBCBD414D940E93", 16)
+	sm2Params.Gx, _ = new(big.Int).SetString("32C4AE2C1F1981195F9904466A39C9948FE30BBFF2660BE1715A4589334C74C7", 16)
+	sm2Params.Gy, _ = new(big.Int).SetString("BC3736A2F4F6779C59BDCEE36B692153D0A9877CC62A474002DF32E52139F0A0", 16)
+}
+
+// P256SM2 returns a Curve which implements sm2p256v1, the curve recommended
+// for SM2 by GB/T 32918.5.
+//
+// The coefficient a of sm2p256v1 is p-3, so the generic [elliptic.CurveParams]
+// arithmetic applies unchanged.
+func P256SM2() elliptic.Curve {
+	sm2InitOnce.Do(initSM2)
+	return sm2Params
+}
+
+// SM2Curve is sm2p256v1, the same Curve as P256SM2 returns. Its Params
+// hold the parameters published in GB/T 32918.5: the prime p, b, the base
+// point (Gx, Gy) and its order n. The coefficient a = p-3 is implied, as
+// for every [elliptic.CurveParams] curve, and is given by SM2CurveA.
+var SM2Curve = P256SM2()
+
+// SM2CurveA returns the coefficient a of sm2p256v1, which is p-3.
+func SM2CurveA() *big.Int {
+	return new(big.Int).Sub(P256SM2().Params().P, big.NewInt(3))
+}
+
+// SM2PublicKey represents an SM2 public key.
+type SM2PublicKey struct {
+	elliptic.Curve
+	X, Y *big.Int
+}
+
+// SM2PrivateKey represents an SM2 private key.
+type SM2PrivateKey struct {
+	SM2PublicKey
+	D *big.Int
+}
+
+// Public returns the public key corresponding to priv.
+func (priv *SM2PrivateKey) Public() crypto.PublicKey {
+	return &priv.SM2PublicKey
+}
+
+// GenerateSM2Key generates a new SM2 key pair on sm2p256v1.
+//
+// The private scalar is drawn by rejection sampling from rand until it falls
+// in [1, n-2], so a deterministic rand yields a deterministic key; a rand
+// that yields no such scalar in a bounded number of tries, such as an
+// all-zero one, gives an error wrapping ErrNoValidScalar. GB/T 32918.1
+// excludes n-1 from the range defined for ECDSA-style keys because signing
+// needs (1+d)^-1 mod n.
+func GenerateSM2Key(rand io.Reader) (*SM2PrivateKey, error) {
+	c := P256SM2()
+	d, err := randScalar(c, rand)
+	if err != nil {
+		return nil, fmt.Errorf("sm3: reading SM2 key randomness: %w", err)
+	}
+	return sm2KeyFromScalar(d), nil
+}
+
+// sm2KeyFromScalar returns the key pair with private scalar d.
+func sm2KeyFromScalar(d *big.Int) *SM2PrivateKey {
+	c := P256SM2()
+	priv := new(SM2PrivateKey)
+	priv.Curve = c
+	priv.D = d
+	priv.X, priv.Y = c.ScalarBaseMult(d.Bytes())
+	return priv
+}
+
+// sm2ScalarAttempts bounds the number of candidates randScalar draws. A
+// uniform candidate falls outside [1, n-2] with probability about 2^-32,
+// so only a broken rand exhausts it.
+const sm2ScalarAttempts = 100
+
+// randScalar returns a uniformly random scalar in [1, n-2]. It returns
+// ErrNoValidScalar if sm2ScalarAttempts candidates read from rand all fall
+// outside that range.
+func randScalar(c elliptic.Curve, rand io.Reader) (*big.Int, error) {
+	params := c.Params()
+	buf := make([]byte, (params.N.BitLen()+7)/8)
+	nMinus1 := new(big.Int).Sub(params.N, big.NewInt(1))
+	k := new(big.Int)
+	for i := 0; i < sm2ScalarAttempts; i++ {
+		if _, err := io.ReadFull(rand, buf); err != nil {
+			return nil, err
+		}
+		k.SetBytes(buf)
+		if k.Sign() > 0 && k.Cmp(nMinus1) < 0 {
+			return k, nil
+		}
+	}
+	return nil, fmt.Errorf("%w after %d attempts", ErrNoValidScalar, sm2ScalarAttempts)
+}
+
+// DefaultSM2UID is the user identity used by GM/T 0009 when the
+// application does not agree on one.
+var DefaultSM2UID = []byte("1234567812345678")
+
+// ZA returns the SM2 user identity hash
+//
+//	ZA = SM3(ENTL || ID || a || b || xG || yG || xA || yA)
+//
+// of GB/T 32918.2 (section 5.5), where ENTL is the bit length of id as a
+// 16-bit big-endian integer.
+func ZA(pub *SM2PublicKey, id []byte) ([Size]byte, error) {
+	var za [Size]byte
+	if len(id) >= 1<<13 {
+		return za, fmt.Errorf("%w: SM2 user ID of %d bytes exceeds 8191", ErrBadLength, len(id))
+	}
+	params := P256SM2().Params()
+	a := new(big.Int).Sub(params.P, big.NewInt(3))
+
+	h := New()
+	h.Write([]byte{byte(len(id) >> 5), byte(len(id) << 3)})
+	h.Write(id)
+	var buf [32]byte
+	for _, v := range []*big.Int{a, params.B, params.Gx, params.Gy, pub.X, pub.Y} {
+		h.Write(v.FillBytes(buf[:]))
+	}
+	h.Sum(za[:0])
+	return za, nil
+}
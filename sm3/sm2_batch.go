@@ -0,0 +1,50 @@
+package sm3
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// SM2VerifyItem is one signature to check with BatchVerifySM2.
+type SM2VerifyItem struct {
+	PublicKey *SM2PublicKey
+	UID       []byte // signer identity, usually DefaultSM2UID
+	Message   []byte
+	Signature []byte // DER-encoded
+}
+
+// BatchVerifySM2 verifies each item as VerifySM2 would, spreading the work
+// over up to GOMAXPROCS goroutines, and returns one result per item in the
+// same order. Items are checked independently, so an invalid or malformed
+// signature only makes its own result false.
+//
+// The error is nil unless some item could not be checked at all, because
+// its signature is not well-formed DER or its UID is too long. It then
+// joins one error per such item, each naming the item's index; the result
+// slice is complete either way.
+func BatchVerifySM2(items []SM2VerifyItem) ([]bool, error) {
+	results := make([]bool, len(items))
+	errs := make([]error, len(items))
+	workers := min(runtime.GOMAXPROCS(0), len(items))
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		first, last := w*len(items)/workers, (w+1)*len(items)/workers
+		wg.Add(1)
+		go func(first, last int) {
+			defer wg.Done()
+			for i := first; i < last; i++ {
+				it := &items[i]
+				ok, err := VerifySM2(it.PublicKey, it.UID, it.Message, it.Signature)
+				results[i] = ok
+				if err != nil {
+					errs[i] = fmt.Errorf("item %d: %w", i, err)
+				}
+			}
+		}(first, last)
+	}
+	wg.Wait()
+	return results, errors.Join(errs...)
+}
@@ -0,0 +1,60 @@
+package sm3
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestBatchVerifySM2(t *testing.T) {
+	rng := rand.New(rand.NewSource(142))
+	var items []SM2VerifyItem
+	var want []bool
+	for i := 0; i < 20; i++ {
+		priv, err := GenerateSM2Key(rng)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg := []byte(fmt.Sprintf("message %d", i))
+		sig, err := priv.SignDeterministic(DefaultSM2UID, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		item := SM2VerifyItem{PublicKey: &priv.SM2PublicKey, UID: DefaultSM2UID, Message: msg, Signature: sig}
+		valid := true
+		switch i % 4 {
+		case 1:
+			item.Message = []byte("another message")
+			valid = false
+		case 2:
+			item.UID = []byte("someone else")
+			valid = false
+		}
+		items = append(items, item)
+		want = append(want, valid)
+	}
+	items = append(items, SM2VerifyItem{PublicKey: items[0].PublicKey, Message: items[0].Message, UID: DefaultSM2UID, Signature: []byte{0x30, 0x00}})
+	want = append(want, false)
+
+	got, err := BatchVerifySM2(items)
+	if len(got) != len(want) {
+		t.Fatalf("got %d results, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("item %d: valid = %v, want %v", i, got[i], want[i])
+		}
+	}
+	if !errors.Is(err, ErrMalformedSignature) || !strings.Contains(err.Error(), fmt.Sprintf("item %d", len(items)-1)) {
+		t.Errorf("err = %v, want ErrMalformedSignature for item %d", err, len(items)-1)
+	}
+
+	if got, err := BatchVerifySM2(items[:4]); err != nil || !got[0] || got[1] || got[2] || !got[3] {
+		t.Errorf("well-formed batch: %v, %v", got, err)
+	}
+	if got, err := BatchVerifySM2(nil); err != nil || len(got) != 0 {
+		t.Errorf("empty batch: %v, %v", got, err)
+	}
+}
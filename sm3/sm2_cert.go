@@ -0,0 +1,314 @@
+package sm3
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+
+	"golang.org/x/crypto/cryptobyte"
+	cbasn1 "golang.org/x/crypto/cryptobyte/asn1"
+)
+
+// Verification of X.509 certificates signed with SM2-with-SM3 (GM/T 0015).
+//
+// crypto/x509 rejects sm2p256v1 public keys when parsing, so a certificate
+// carrying one never becomes an *x509.Certificate. The functions here work
+// on DER instead, so callers can check GM certificate chains before, or in
+// place of, handing them to crypto/x509.
+
+var (
+	oidSignatureSM2WithSM3 = asn1.ObjectIdentifier{1, 2, 156, 10197, 1, 501}
+	oidNamedCurveSM2       = asn1.ObjectIdentifier{1, 2, 156, 10197, 1, 301}
+	oidPublicKeyECDSA      = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+
+	oidExtensionKeyUsage         = asn1.ObjectIdentifier{2, 5, 29, 15}
+	oidExtensionBasicConstraints = asn1.ObjectIdentifier{2, 5, 29, 19}
+)
+
+// ParseSM2PublicKeyInfo parses a DER-encoded SubjectPublicKeyInfo holding
+// an uncompressed sm2p256v1 point. Both the common id-ecPublicKey encoding
+// with the SM2 named curve and the bare SM2 algorithm OID are accepted.
+func ParseSM2PublicKeyInfo(spki []byte) (*SM2PublicKey, error) {
+	var (
+		input   = cryptobyte.String(spki)
+		info    cryptobyte.String
+		algo    cryptobyte.String
+		oid     asn1.ObjectIdentifier
+		keyBits asn1.BitString
+	)
+	if !input.ReadASN1(&info, cbasn1.SEQUENCE) || !input.Empty() ||
+		!info.ReadASN1(&algo, cbasn1.SEQUENCE) ||
+		!algo.ReadASN1ObjectIdentifier(&oid) ||
+		!info.ReadASN1BitString(&keyBits) || !info.Empty() {
+		return nil, fmt.Errorf("%w: bad SubjectPublicKeyInfo", ErrMalformedCertificate)
+	}
+	switch {
+	case oid.Equal(oidNamedCurveSM2):
+	case oid.Equal(oidPublicKeyECDSA):
+		var curve asn1.ObjectIdentifier
+		if !algo.ReadASN1ObjectIdentifier(&curve) {
+			return nil, fmt.Errorf("%w: missing named curve", ErrMalformedCertificate)
+		}
+		if !curve.Equal(oidNamedCurveSM2) {
+			return nil, fmt.Errorf("%w: elliptic curve %v", ErrUnsupportedAlgorithm, curve)
+		}
+	default:
+		return nil, fmt.Errorf("%w: public key algorithm %v", ErrUnsupportedAlgorithm, oid)
+	}
+
+	c := P256SM2()
+	byteLen := (c.Params().BitSize + 7) / 8
+	point := keyBits.RightAlign()
+	if keyBits.BitLength%8 != 0 || len(point) != 1+2*byteLen || point[0] != 4 {
+		return nil, fmt.Errorf("%w: not an uncompressed point", ErrInvalidPublicKey)
+	}
+	x := new(big.Int).SetBytes(point[1 : 1+byteLen])
+	y := new(big.Int).SetBytes(point[1+byteLen:])
+	if !c.IsOnCurve(x, y) {
+		return nil, ErrInvalidPublicKey
+	}
+	return &SM2PublicKey{Curve: c, X: x, Y: y}, nil
+}
+
+// VerifyCertSM2 checks that the DER certificate cert carries a valid
+// SM2-with-SM3 signature by the SM2 key in the DER certificate parent. The
+// signer identity is DefaultSM2UID, as GM/T 0015 requires for
+// certificates.
+//
+// Only the signature is checked; validity periods, name chaining and
+// extensions are left to the caller. ErrUnsupportedAlgorithm is returned if
+// cert is not signed with SM2-with-SM3 or parent's key is not SM2, and
+// ErrInvalidSignature if the signature does not verify.
+func VerifyCertSM2(cert, parent []byte) error {
+	tbs, sigAlgo, sig, err := splitCertificate(cert)
+	if err != nil {
+		return err
+	}
+	if !sigAlgo.Equal(oidSignatureSM2WithSM3) {
+		return fmt.Errorf("%w: signature algorithm %v", ErrUnsupportedAlgorithm, sigAlgo)
+	}
+	spki, err := certPublicKeyInfo(parent)
+	if err != nil {
+		return err
+	}
+	pub, err := ParseSM2PublicKeyInfo(spki)
+	if err != nil {
+		return err
+	}
+	ok, err := VerifySM2(pub, DefaultSM2UID, tbs, sig)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// VerifyChainSM2 checks an SM2-with-SM3 certificate chain in DER, leaf
+// first, as a TLS peer sends it. Each certificate must name the next one
+// as its issuer and carry a valid signature by it (see VerifyCertSM2), and
+// the last must be one of roots or be issued and signed by one of them.
+// Every issuer, intermediate or root, must also be a CA as
+// x509.Certificate.Verify requires: its basic constraints must mark it as
+// a CA, its key usage, if present, must include keyCertSign, and its path
+// length constraint, if any, must allow the intermediates below it.
+//
+// Validity periods, extended key usage and host names are left to the
+// caller. ErrInvalidSignature is returned if a signature does not verify
+// or the chain does not end at a root, and ErrNotAuthorizedToSign if an
+// issuer is not a CA allowed to sign.
+func VerifyChainSM2(chain, roots [][]byte) error {
+	if len(chain) == 0 {
+		return fmt.Errorf("%w: empty certificate chain", ErrMalformedCertificate)
+	}
+	for i := 0; i+1 < len(chain); i++ {
+		if err := verifyIssuedSM2(chain[i], chain[i+1]); err != nil {
+			return fmt.Errorf("certificate %d: %w", i, err)
+		}
+		if err := checkIssuerSM2(chain[i+1], i); err != nil {
+			return fmt.Errorf("certificate %d: %w", i+1, err)
+		}
+	}
+	last := chain[len(chain)-1]
+	for _, root := range roots {
+		if bytes.Equal(last, root) {
+			return nil
+		}
+	}
+	var rootErr error
+	for _, root := range roots {
+		if verifyIssuedSM2(last, root) == nil {
+			if rootErr = checkIssuerSM2(root, len(chain)-1); rootErr == nil {
+				return nil
+			}
+		}
+	}
+	if rootErr != nil {
+		return fmt.Errorf("root: %w", rootErr)
+	}
+	return fmt.Errorf("%w: certificate chain does not end at a trusted root", ErrInvalidSignature)
+}
+
+// checkIssuerSM2 checks that the DER certificate parent is a CA that may
+// sign certificates with below intermediates between it and the leaf.
+func checkIssuerSM2(parent []byte, below int) error {
+	exts, err := certExtensions(parent)
+	if err != nil {
+		return err
+	}
+	var (
+		isCA, hasKeyUsage, certSign bool
+		pathLen                     = -1
+	)
+	for !exts.Empty() {
+		var (
+			ext, value cryptobyte.String
+			oid        asn1.ObjectIdentifier
+			critical   bool
+		)
+		if !exts.ReadASN1(&ext, cbasn1.SEQUENCE) ||
+			!ext.ReadASN1ObjectIdentifier(&oid) ||
+			(ext.PeekASN1Tag(cbasn1.BOOLEAN) && !ext.ReadASN1Boolean(&critical)) ||
+			!ext.ReadASN1(&value, cbasn1.OCTET_STRING) || !ext.Empty() {
+			return fmt.Errorf("%w: bad extension", ErrMalformedCertificate)
+		}
+		switch {
+		case oid.Equal(oidExtensionBasicConstraints):
+			var bc cryptobyte.String
+			if !value.ReadASN1(&bc, cbasn1.SEQUENCE) ||
+				(bc.PeekASN1Tag(cbasn1.BOOLEAN) && !bc.ReadASN1Boolean(&isCA)) ||
+				(bc.PeekASN1Tag(cbasn1.INTEGER) && (!bc.ReadASN1Integer(&pathLen) || pathLen < 0)) ||
+				!bc.Empty() {
+				return fmt.Errorf("%w: bad basic constraints", ErrMalformedCertificate)
+			}
+		case oid.Equal(oidExtensionKeyUsage):
+			var bits asn1.BitString
+			if !value.ReadASN1BitString(&bits) {
+				return fmt.Errorf("%w: bad key usage", ErrMalformedCertificate)
+			}
+			hasKeyUsage, certSign = true, bits.At(5) == 1 // keyCertSign
+		}
+	}
+	switch {
+	case !isCA:
+		return fmt.Errorf("%w: issuer is not a CA", ErrNotAuthorizedToSign)
+	case hasKeyUsage && !certSign:
+		return fmt.Errorf("%w: issuer key usage lacks keyCertSign", ErrNotAuthorizedToSign)
+	case pathLen >= 0 && below > pathLen:
+		return fmt.Errorf("%w: %d intermediates below an issuer with path length %d", ErrNotAuthorizedToSign, below, pathLen)
+	}
+	return nil
+}
+
+// verifyIssuedSM2 checks that parent's subject is cert's issuer and that
+// parent signed cert.
+func verifyIssuedSM2(cert, parent []byte) error {
+	issuer, _, _, err := certFields(cert)
+	if err != nil {
+		return err
+	}
+	_, subject, _, err := certFields(parent)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(issuer, subject) {
+		return fmt.Errorf("%w: issuer does not match the next certificate's subject", ErrInvalidSignature)
+	}
+	return VerifyCertSM2(cert, parent)
+}
+
+// splitCertificate returns the raw TBSCertificate, the signature algorithm
+// and the signature value of a DER certificate. It also checks that the
+// algorithm inside the TBSCertificate matches the outer one.
+func splitCertificate(der []byte) (tbs []byte, sigAlgo asn1.ObjectIdentifier, sig []byte, err error) {
+	var (
+		input                = cryptobyte.String(der)
+		cert, rawTBS, inner  cryptobyte.String
+		outerAlgo, innerAlgo cryptobyte.String
+		sigBits              asn1.BitString
+	)
+	if !input.ReadASN1(&cert, cbasn1.SEQUENCE) || !input.Empty() ||
+		!cert.ReadASN1Element(&rawTBS, cbasn1.SEQUENCE) ||
+		!cert.ReadASN1Element(&outerAlgo, cbasn1.SEQUENCE) ||
+		!cert.ReadASN1BitString(&sigBits) || !cert.Empty() ||
+		sigBits.BitLength%8 != 0 {
+		return nil, nil, nil, fmt.Errorf("%w: bad certificate structure", ErrMalformedCertificate)
+	}
+	tbsBody := rawTBS
+	if !tbsBody.ReadASN1(&inner, cbasn1.SEQUENCE) ||
+		!inner.SkipOptionalASN1(cbasn1.Tag(0).Constructed().ContextSpecific()) ||
+		!inner.SkipASN1(cbasn1.INTEGER) ||
+		!inner.ReadASN1Element(&innerAlgo, cbasn1.SEQUENCE) {
+		return nil, nil, nil, fmt.Errorf("%w: bad TBSCertificate", ErrMalformedCertificate)
+	}
+	if string(innerAlgo) != string(outerAlgo) {
+		return nil, nil, nil, fmt.Errorf("%w: signature algorithm mismatch", ErrMalformedCertificate)
+	}
+	var algo cryptobyte.String
+	if !outerAlgo.ReadASN1(&algo, cbasn1.SEQUENCE) || !algo.ReadASN1ObjectIdentifier(&sigAlgo) {
+		return nil, nil, nil, fmt.Errorf("%w: bad signature algorithm", ErrMalformedCertificate)
+	}
+	return rawTBS, sigAlgo, sigBits.Bytes, nil
+}
+
+// certPublicKeyInfo returns the raw SubjectPublicKeyInfo of a DER
+// certificate.
+func certPublicKeyInfo(der []byte) ([]byte, error) {
+	_, _, spki, err := certFields(der)
+	return spki, err
+}
+
+// certExtensions returns the contents of the extensions SEQUENCE of a DER
+// certificate, empty if it has none.
+func certExtensions(der []byte) (cryptobyte.String, error) {
+	var (
+		input         = cryptobyte.String(der)
+		cert, tbs     cryptobyte.String
+		wrapped, exts cryptobyte.String
+		hasExtensions bool
+	)
+	if !input.ReadASN1(&cert, cbasn1.SEQUENCE) || !input.Empty() ||
+		!cert.ReadASN1(&tbs, cbasn1.SEQUENCE) ||
+		!tbs.SkipOptionalASN1(cbasn1.Tag(0).Constructed().ContextSpecific()) ||
+		!tbs.SkipASN1(cbasn1.INTEGER) ||
+		!tbs.SkipASN1(cbasn1.SEQUENCE) || // signature
+		!tbs.SkipASN1(cbasn1.SEQUENCE) || // issuer
+		!tbs.SkipASN1(cbasn1.SEQUENCE) || // validity
+		!tbs.SkipASN1(cbasn1.SEQUENCE) || // subject
+		!tbs.SkipASN1(cbasn1.SEQUENCE) || // subjectPublicKeyInfo
+		!tbs.SkipOptionalASN1(cbasn1.Tag(1).ContextSpecific()) ||
+		!tbs.SkipOptionalASN1(cbasn1.Tag(2).ContextSpecific()) ||
+		!tbs.ReadOptionalASN1(&wrapped, &hasExtensions, cbasn1.Tag(3).Constructed().ContextSpecific()) ||
+		!tbs.Empty() {
+		return nil, fmt.Errorf("%w: bad TBSCertificate", ErrMalformedCertificate)
+	}
+	if hasExtensions && (!wrapped.ReadASN1(&exts, cbasn1.SEQUENCE) || !wrapped.Empty()) {
+		return nil, fmt.Errorf("%w: bad extensions", ErrMalformedCertificate)
+	}
+	return exts, nil
+}
+
+// certFields returns the raw issuer and subject Names and the raw
+// SubjectPublicKeyInfo of a DER certificate.
+func certFields(der []byte) (issuer, subject, spki []byte, err error) {
+	var (
+		input                 = cryptobyte.String(der)
+		cert, tbs             cryptobyte.String
+		rawIssuer, rawSubject cryptobyte.String
+		rawSPKI               cryptobyte.String
+	)
+	if !input.ReadASN1(&cert, cbasn1.SEQUENCE) || !input.Empty() ||
+		!cert.ReadASN1(&tbs, cbasn1.SEQUENCE) ||
+		!tbs.SkipOptionalASN1(cbasn1.Tag(0).Constructed().ContextSpecific()) ||
+		!tbs.SkipASN1(cbasn1.INTEGER) ||
+		!tbs.SkipASN1(cbasn1.SEQUENCE) || // signature
+		!tbs.ReadASN1Element(&rawIssuer, cbasn1.SEQUENCE) ||
+		!tbs.SkipASN1(cbasn1.SEQUENCE) || // validity
+		!tbs.ReadASN1Element(&rawSubject, cbasn1.SEQUENCE) ||
+		!tbs.ReadASN1Element(&rawSPKI, cbasn1.SEQUENCE) {
+		return nil, nil, nil, fmt.Errorf("%w: bad TBSCertificate", ErrMalformedCertificate)
+	}
+	return rawIssuer, rawSubject, rawSPKI, nil
+}
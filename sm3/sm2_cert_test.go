@@ -0,0 +1,253 @@
+package sm3
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/cryptobyte"
+	cbasn1 "golang.org/x/crypto/cryptobyte/asn1"
+)
+
+// sm2SelfSignedPEM is a self-signed SM2-with-SM3 CA certificate generated
+// with
+//
+//	openssl req -x509 -new -key sm2.key -sm3 -sigopt distid:1234567812345678 \
+//		-subj "/CN=SM2 Test Root" -days 36500
+const sm2SelfSignedPEM = `-----BEGIN CERTIFICATE-----
+MIIBhzCCAS2gAwIBAgIUMqHXmqgkhIgzxLqVE2msnPF07G8wCgYIKoEcz1UBg3Uw
+GDEWMBQGA1UEAwwNU00yIFRlc3QgUm9vdDAgFw0yNjEwMTQxNTIwMzVaGA8yMTI2
+MDkyMDE1MjAzNVowGDEWMBQGA1UEAwwNU00yIFRlc3QgUm9vdDBZMBMGByqGSM49
+AgEGCCqBHM9VAYItA0IABNFmQ6q/rUYgl4sEv1JbHjJHWc3wlCt3VTXzcWRaetlO
+zl1unQzBjzfCkLl3RsSyB6PRXVwNp6ShZjSoeMitGpCjUzBRMB0GA1UdDgQWBBSC
+sIL+8R81gTvuHzGcWRsI6LpOajAfBgNVHSMEGDAWgBSCsIL+8R81gTvuHzGcWRsI
+6LpOajAPBgNVHRMBAf8EBTADAQH/MAoGCCqBHM9VAYN1A0gAMEUCIDramE8TgSFi
+oolKPM8iI84a7Lq64iEuk3+JSiMdfWOcAiEAjVlTSe9C6VefZ+rfWISu7YgkEMqL
+EY/CrwfAdBI7lgE=
+-----END CERTIFICATE-----
+`
+
+func sm2SelfSignedDER(t *testing.T) []byte {
+	t.Helper()
+	block, _ := pem.Decode([]byte(sm2SelfSignedPEM))
+	if block == nil {
+		t.Fatal("failed to decode certificate PEM")
+	}
+	return block.Bytes
+}
+
+func TestVerifyCertSM2SelfSigned(t *testing.T) {
+	der := sm2SelfSignedDER(t)
+	if err := VerifyCertSM2(der, der); err != nil {
+		t.Fatalf("VerifyCertSM2 = %v", err)
+	}
+}
+
+func TestVerifyCertSM2Tampered(t *testing.T) {
+	der := sm2SelfSignedDER(t)
+	// Change a letter of the issuer common name, inside the signed part.
+	tampered := append([]byte(nil), der...)
+	tampered[bytes.Index(tampered, []byte("Root"))] = 'B'
+	if err := VerifyCertSM2(tampered, der); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("tampered certificate: err = %v, want %v", err, ErrInvalidSignature)
+	}
+}
+
+// sm2ChainDER returns the root, intermediate and leaf certificates of
+// testdata/sm2_chain.pem.
+func sm2ChainDER(t *testing.T) (root, intermediate, leaf []byte) {
+	t.Helper()
+	data, err := os.ReadFile("testdata/sm2_chain.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var certs [][]byte
+	for {
+		var block *pem.Block
+		if block, data = pem.Decode(data); block == nil {
+			break
+		}
+		certs = append(certs, block.Bytes)
+	}
+	if len(certs) != 3 {
+		t.Fatalf("testdata/sm2_chain.pem holds %d certificates, want 3", len(certs))
+	}
+	return certs[0], certs[1], certs[2]
+}
+
+// sm2LeafIssuedDER returns testdata/sm2_leaf_issued.pem, an end-entity
+// certificate for evil.example signed by the leaf of sm2ChainDER.
+func sm2LeafIssuedDER(t *testing.T) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/sm2_leaf_issued.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		t.Fatal("failed to decode testdata/sm2_leaf_issued.pem")
+	}
+	return block.Bytes
+}
+
+func TestVerifyChainSM2(t *testing.T) {
+	root, intermediate, leaf := sm2ChainDER(t)
+	leafIssued := sm2LeafIssuedDER(t)
+	tampered := append([]byte(nil), leaf...)
+	tampered[bytes.Index(tampered, []byte("leaf.example"))] = 'L'
+
+	for _, tt := range []struct {
+		name         string
+		chain, roots [][]byte
+		want         error
+	}{
+		{"leaf and intermediate", [][]byte{leaf, intermediate}, [][]byte{root}, nil},
+		{"root included", [][]byte{leaf, intermediate, root}, [][]byte{root}, nil},
+		{"intermediate as root", [][]byte{leaf}, [][]byte{intermediate}, nil},
+		{"altered leaf", [][]byte{tampered, intermediate}, [][]byte{root}, ErrInvalidSignature},
+		{"missing intermediate", [][]byte{leaf}, [][]byte{root}, ErrInvalidSignature},
+		{"wrong order", [][]byte{intermediate, leaf}, [][]byte{root}, ErrInvalidSignature},
+		{"untrusted root", [][]byte{leaf, intermediate}, [][]byte{sm2SelfSignedDER(t)}, ErrInvalidSignature},
+		{"empty chain", nil, [][]byte{root}, ErrMalformedCertificate},
+		{"leaf as intermediate", [][]byte{leafIssued, leaf, intermediate}, [][]byte{root}, ErrNotAuthorizedToSign},
+		{"leaf as root", [][]byte{leafIssued}, [][]byte{leaf}, ErrNotAuthorizedToSign},
+	} {
+		if err := VerifyChainSM2(tt.chain, tt.roots); !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestParseSM2PublicKeyInfo(t *testing.T) {
+	spki, err := certPublicKeyInfo(sm2SelfSignedDER(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := ParseSM2PublicKeyInfo(spki)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const wantX = "d16643aabfad4620978b04bf525b1e324759cdf0942b775535f371645a7ad94e"
+	if got := hex.EncodeToString(pub.X.FillBytes(make([]byte, 32))); got != wantX {
+		t.Errorf("X = %s, want %s", got, wantX)
+	}
+
+	// The same point under the bare SM2 algorithm OID.
+	var b cryptobyte.Builder
+	b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+			b.AddASN1ObjectIdentifier(oidNamedCurveSM2)
+		})
+		b.AddASN1BitString(elliptic.Marshal(pub.Curve, pub.X, pub.Y))
+	})
+	bare, err := ParseSM2PublicKeyInfo(b.BytesOrPanic())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bare.X.Cmp(pub.X) != 0 || bare.Y.Cmp(pub.Y) != 0 {
+		t.Error("bare SM2 OID encoding parsed to a different point")
+	}
+}
+
+func TestVerifyCertSM2Rejects(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "P-256"},
+		NotBefore:    time.Unix(0, 0),
+		NotAfter:     time.Unix(1<<31, 0),
+	}
+	p256, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sm2 := sm2SelfSignedDER(t)
+
+	for _, tt := range []struct {
+		name         string
+		cert, parent []byte
+		want         error
+	}{
+		{"ECDSA certificate", p256, p256, ErrUnsupportedAlgorithm},
+		{"ECDSA parent", sm2, p256, ErrUnsupportedAlgorithm},
+		{"garbage", []byte("not a certificate"), sm2, ErrMalformedCertificate},
+		{"trailing data", append(append([]byte(nil), sm2...), 0), sm2, ErrMalformedCertificate},
+	} {
+		if err := VerifyCertSM2(tt.cert, tt.parent); !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	if _, err := ParseSM2PublicKeyInfo(mustMarshalPKIX(t, &key.PublicKey)); !errors.Is(err, ErrUnsupportedAlgorithm) {
+		t.Errorf("P-256 SubjectPublicKeyInfo: err = %v, want %v", err, ErrUnsupportedAlgorithm)
+	}
+}
+
+func TestCheckIssuerSM2(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// checkIssuerSM2 reads only the extensions, so P-256 certificates from
+	// crypto/x509 serve to exercise them.
+	issuer := func(modify func(*x509.Certificate)) []byte {
+		tmpl := &x509.Certificate{
+			SerialNumber:          big.NewInt(1),
+			Subject:               pkix.Name{CommonName: "CA"},
+			NotBefore:             time.Unix(0, 0),
+			NotAfter:              time.Unix(1<<31, 0),
+			BasicConstraintsValid: true,
+			IsCA:                  true,
+			MaxPathLen:            -1,
+		}
+		modify(tmpl)
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return der
+	}
+
+	for _, tt := range []struct {
+		name   string
+		modify func(*x509.Certificate)
+		below  int
+		want   error
+	}{
+		{"CA", func(*x509.Certificate) {}, 5, nil},
+		{"cert sign usage", func(c *x509.Certificate) { c.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign }, 0, nil},
+		{"path length met", func(c *x509.Certificate) { c.MaxPathLen = 1 }, 1, nil},
+		{"zero path length", func(c *x509.Certificate) { c.MaxPathLen, c.MaxPathLenZero = 0, true }, 0, nil},
+		{"path length exceeded", func(c *x509.Certificate) { c.MaxPathLen = 1 }, 2, ErrNotAuthorizedToSign},
+		{"zero path length exceeded", func(c *x509.Certificate) { c.MaxPathLen, c.MaxPathLenZero = 0, true }, 1, ErrNotAuthorizedToSign},
+		{"not a CA", func(c *x509.Certificate) { c.IsCA, c.MaxPathLen = false, 0 }, 0, ErrNotAuthorizedToSign},
+		{"no basic constraints", func(c *x509.Certificate) { c.BasicConstraintsValid, c.IsCA = false, false }, 0, ErrNotAuthorizedToSign},
+		{"no cert sign usage", func(c *x509.Certificate) { c.KeyUsage = x509.KeyUsageDigitalSignature }, 0, ErrNotAuthorizedToSign},
+	} {
+		if err := checkIssuerSM2(issuer(tt.modify), tt.below); !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func mustMarshalPKIX(t *testing.T, pub *ecdsa.PublicKey) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
@@ -0,0 +1,33 @@
+package sm3
+
+import "math/big"
+
+// SM2Challenge returns c = SM3(R || pub || msg) mod n, the challenge of a
+// Schnorr-style signature over sm2p256v1, whose order is n. R and pub are
+// concatenated as given, so callers should use fixed-length encodings for
+// them, such as MarshalPoint, to keep the input unambiguous.
+//
+// The 256-bit digest is read big-endian and reduced modulo n. Because n is
+// about 2^256 - 2^224, a digest is at or above n with probability about
+// 2^-32; the reduction subtracts n once for those, so small challenges are
+// slightly favored, by the same 2^-32.
+//
+// This is for experimental constructions. It is not part of the GB/T 32918
+// signature scheme, which is implemented by SignDeterministic and
+// VerifySM2.
+func SM2Challenge(R, pub, msg []byte) *big.Int {
+	h := New()
+	h.Write(R)
+	h.Write(pub)
+	h.Write(msg)
+	var sum [Size]byte
+	h.Sum(sum[:0])
+	return sm2ScalarFromDigest(sum)
+}
+
+// sm2ScalarFromDigest reads sum as a big-endian integer reduced modulo the
+// order of sm2p256v1.
+func sm2ScalarFromDigest(sum [Size]byte) *big.Int {
+	c := new(big.Int).SetBytes(sum[:])
+	return c.Mod(c, P256SM2().Params().N)
+}
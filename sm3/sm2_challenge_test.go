@@ -0,0 +1,54 @@
+package sm3
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+)
+
+func TestSM2Challenge(t *testing.T) {
+	priv := sm2VectorKey(t)
+	rx, ry := P256SM2().ScalarBaseMult([]byte{7})
+	R := MarshalPoint(rx, ry, false)
+	pub := MarshalPoint(priv.X, priv.Y, false)
+	msg := []byte("message digest")
+
+	c := SM2Challenge(R, pub, msg)
+	if again := SM2Challenge(R, pub, msg); c.Cmp(again) != 0 {
+		t.Errorf("SM2Challenge is not deterministic: %X, %X", c, again)
+	}
+	sum := Sum(bytes.Join([][]byte{R, pub, msg}, nil))
+	n := P256SM2().Params().N
+	want := new(big.Int).Mod(new(big.Int).SetBytes(sum[:]), n)
+	if c.Cmp(want) != 0 {
+		t.Errorf("SM2Challenge = %X, want %X", c, want)
+	}
+	if other := SM2Challenge(R, pub, []byte("message digesT")); other.Cmp(c) == 0 {
+		t.Error("different messages gave the same challenge")
+	}
+}
+
+func TestSM2ScalarFromDigest(t *testing.T) {
+	// A digest at or above n, which SM3 yields with probability about
+	// 2^-32, has to be reduced.
+	n := P256SM2().Params().N
+	var top [Size]byte
+	for i := range top {
+		top[i] = 0xff
+	}
+	all := new(big.Int).SetBytes(top[:])
+	if got, want := sm2ScalarFromDigest(top), new(big.Int).Sub(all, n); got.Cmp(want) != 0 {
+		t.Errorf("reduced 2^256-1 = %X, want %X", got, want)
+	}
+
+	var atN [Size]byte
+	n.FillBytes(atN[:])
+	if got := sm2ScalarFromDigest(atN); got.Sign() != 0 {
+		t.Errorf("reduced n = %X, want 0", got)
+	}
+	var below [Size]byte
+	new(big.Int).Sub(n, big.NewInt(1)).FillBytes(below[:])
+	if got := sm2ScalarFromDigest(below); got.Cmp(new(big.Int).Sub(n, big.NewInt(1))) != 0 {
+		t.Errorf("reduced n-1 = %X, want n-1", got)
+	}
+}
@@ -0,0 +1,87 @@
+package sm3
+
+import (
+	"math/big"
+
+	"golang.org/x/crypto/cryptobyte"
+	"golang.org/x/crypto/cryptobyte/asn1"
+)
+
+// Encodings of SM2 public key encryption ciphertexts (GB/T 32918.4). A
+// ciphertext is made of the ephemeral point C1, the SM3 check value C3 and
+// the masked message C2. It travels either as the raw concatenation
+// C1 || C3 || C2 with C1 an uncompressed point, or in the ASN.1 form of
+// GM/T 0009:
+//
+//	SM2Cipher ::= SEQUENCE {
+//		XCoordinate INTEGER,
+//		YCoordinate INTEGER,
+//		HASH        OCTET STRING SIZE(32),
+//		CipherText  OCTET STRING
+//	}
+
+// EncodeSM2Cipher returns the ASN.1 DER encoding of the SM2 ciphertext
+// with ephemeral point (c1x, c1y), check value c3 and masked message c2.
+func EncodeSM2Cipher(c1x, c1y *big.Int, c3, c2 []byte) ([]byte, error) {
+	if len(c3) != Size {
+		return nil, ErrMalformedCiphertext
+	}
+	var b cryptobyte.Builder
+	b.AddASN1(asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		b.AddASN1BigInt(c1x)
+		b.AddASN1BigInt(c1y)
+		b.AddASN1OctetString(c3)
+		b.AddASN1OctetString(c2)
+	})
+	return b.Bytes()
+}
+
+// DecodeSM2Cipher parses an ASN.1 DER SM2 ciphertext, returning
+// ErrMalformedCiphertext if it does not match the SM2Cipher structure. The
+// point is not checked against the curve; that is left to decryption.
+func DecodeSM2Cipher(der []byte) (c1x, c1y *big.Int, c3, c2 []byte, err error) {
+	c1x, c1y = new(big.Int), new(big.Int)
+	var inner cryptobyte.String
+	input := cryptobyte.String(der)
+	if !input.ReadASN1(&inner, asn1.SEQUENCE) ||
+		!input.Empty() ||
+		!inner.ReadASN1Integer(c1x) ||
+		!inner.ReadASN1Integer(c1y) ||
+		!inner.ReadASN1Bytes(&c3, asn1.OCTET_STRING) ||
+		!inner.ReadASN1Bytes(&c2, asn1.OCTET_STRING) ||
+		!inner.Empty() ||
+		c1x.Sign() < 0 || c1y.Sign() < 0 || len(c3) != Size {
+		return nil, nil, nil, nil, ErrMalformedCiphertext
+	}
+	return c1x, c1y, c3, c2, nil
+}
+
+// EncodeSM2CipherRaw returns the SM2 ciphertext as C1 || C3 || C2, with C1
+// the uncompressed encoding 0x04 || x || y of the ephemeral point.
+func EncodeSM2CipherRaw(c1x, c1y *big.Int, c3, c2 []byte) ([]byte, error) {
+	byteLen := (P256SM2().Params().BitSize + 7) / 8
+	if len(c3) != Size || c1x.Sign() < 0 || c1y.Sign() < 0 ||
+		c1x.BitLen() > 8*byteLen || c1y.BitLen() > 8*byteLen {
+		return nil, ErrMalformedCiphertext
+	}
+	out := make([]byte, 1+2*byteLen, 1+2*byteLen+Size+len(c2))
+	out[0] = 4
+	c1x.FillBytes(out[1 : 1+byteLen])
+	c1y.FillBytes(out[1+byteLen:])
+	out = append(out, c3...)
+	return append(out, c2...), nil
+}
+
+// DecodeSM2CipherRaw splits a C1 || C3 || C2 SM2 ciphertext, returning
+// ErrMalformedCiphertext if it is too short or C1 is not an uncompressed
+// point. The returned slices alias raw.
+func DecodeSM2CipherRaw(raw []byte) (c1x, c1y *big.Int, c3, c2 []byte, err error) {
+	byteLen := (P256SM2().Params().BitSize + 7) / 8
+	pointLen := 1 + 2*byteLen
+	if len(raw) < pointLen+Size || raw[0] != 4 {
+		return nil, nil, nil, nil, ErrMalformedCiphertext
+	}
+	c1x = new(big.Int).SetBytes(raw[1 : 1+byteLen])
+	c1y = new(big.Int).SetBytes(raw[1+byteLen : pointLen])
+	return c1x, c1y, raw[pointLen : pointLen+Size], raw[pointLen+Size:], nil
+}
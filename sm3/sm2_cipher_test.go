@@ -0,0 +1,147 @@
+package sm3
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/cryptobyte"
+	"golang.org/x/crypto/cryptobyte/asn1"
+)
+
+// sm2CipherFixture is the ASN.1 ciphertext that
+//
+//	openssl pkeyutl -encrypt -inkey sm2.key
+//
+// produced for "encryption standard", where sm2.key holds the private
+// scalar sm2CipherFixtureKey.
+const (
+	sm2CipherFixture = "307b02204684fddf3adb8ea2d465644d46155782c9d079cd9f2dad5b6c453d6c89ba07ad" +
+		"02203d9b7cef3db474b5a8c2cfb8e23dd2c7fe6730a046fb4934043f598c4f494bf5" +
+		"0420b5554d10d9d9eab757362b92dd1898a382bebb43a1e1f4e6c0c58afa9cf55a41" +
+		"041396cb5a817f39de2079082f79500bddb0e757c0"
+	sm2CipherFixtureKey = "33ce46ecede6104ec3a8b03f6a64c2701c3bad5797933db633e05fd7d3770fa2"
+)
+
+func TestDecodeSM2CipherFixture(t *testing.T) {
+	der, _ := hex.DecodeString(sm2CipherFixture)
+	c1x, c1y, c3, c2, err := DecodeSM2Cipher(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := bigFromHex(t, "4684FDDF3ADB8EA2D465644D46155782C9D079CD9F2DAD5B6C453D6C89BA07AD"); c1x.Cmp(want) != 0 {
+		t.Errorf("C1 x = %X, want %X", c1x, want)
+	}
+
+	// Decrypt by hand (GB/T 32918.4 section 7) to check the fields were
+	// split correctly.
+	c := P256SM2()
+	if !c.IsOnCurve(c1x, c1y) {
+		t.Fatal("C1 is not on the curve")
+	}
+	d := bigFromHex(t, sm2CipherFixtureKey)
+	x2, y2 := c.ScalarMult(c1x, c1y, d.Bytes())
+	xy := append(x2.FillBytes(make([]byte, 32)), y2.FillBytes(make([]byte, 32))...)
+	msg := KDF(xy, len(c2))
+	for i := range msg {
+		msg[i] ^= c2[i]
+	}
+	if string(msg) != "encryption standard" {
+		t.Errorf("decrypted message = %q", msg)
+	}
+	h := New()
+	h.Write(xy[:32])
+	h.Write(msg)
+	h.Write(xy[32:])
+	if got := h.Sum(nil); !bytes.Equal(got, c3) {
+		t.Errorf("C3 = %x, want %x", c3, got)
+	}
+
+	// Re-encoding gives back the fixture, in both layouts.
+	again, err := EncodeSM2Cipher(c1x, c1y, c3, c2)
+	if err != nil || !bytes.Equal(again, der) {
+		t.Errorf("EncodeSM2Cipher = %x, %v, want %x", again, err, der)
+	}
+	raw, err := EncodeSM2CipherRaw(c1x, c1y, c3, c2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(raw) != 65+Size+len(c2) || raw[0] != 4 {
+		t.Errorf("raw encoding has length %d and prefix %#x", len(raw), raw[0])
+	}
+}
+
+func TestSM2CipherRoundTrip(t *testing.T) {
+	c3 := bytes.Repeat([]byte{0xc3}, Size)
+	for _, tt := range []struct {
+		x, y *big.Int
+		c2   []byte
+	}{
+		{big.NewInt(1), big.NewInt(2), nil},
+		{bigFromHex(t, "80"+strings.Repeat("ff", 31)), bigFromHex(t, "01"), []byte("x")},
+		{bigFromHex(t, strings.Repeat("11", 32)), bigFromHex(t, strings.Repeat("22", 32)), bytes.Repeat([]byte("m"), 300)},
+	} {
+		der, err := EncodeSM2Cipher(tt.x, tt.y, c3, tt.c2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		x, y, h, m, err := DecodeSM2Cipher(der)
+		if err != nil || x.Cmp(tt.x) != 0 || y.Cmp(tt.y) != 0 || !bytes.Equal(h, c3) || !bytes.Equal(m, tt.c2) {
+			t.Errorf("ASN.1 round trip of (%X, %X, %d-byte C2) failed: %v", tt.x, tt.y, len(tt.c2), err)
+		}
+
+		raw, err := EncodeSM2CipherRaw(tt.x, tt.y, c3, tt.c2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		x, y, h, m, err = DecodeSM2CipherRaw(raw)
+		if err != nil || x.Cmp(tt.x) != 0 || y.Cmp(tt.y) != 0 || !bytes.Equal(h, c3) || !bytes.Equal(m, tt.c2) {
+			t.Errorf("raw round trip of (%X, %X, %d-byte C2) failed: %v", tt.x, tt.y, len(tt.c2), err)
+		}
+	}
+}
+
+func TestSM2CipherMalformed(t *testing.T) {
+	der, _ := hex.DecodeString(sm2CipherFixture)
+	for name, in := range map[string][]byte{
+		"empty":          nil,
+		"truncated":      der[:len(der)-1],
+		"trailing":       append(append([]byte(nil), der...), 0),
+		"short C3":       encodeCipherUnchecked(bytes.Repeat([]byte{1}, Size-1)),
+		"not a sequence": {0x04, 0x00},
+	} {
+		if _, _, _, _, err := DecodeSM2Cipher(in); !errors.Is(err, ErrMalformedCiphertext) {
+			t.Errorf("DecodeSM2Cipher(%s): err = %v, want %v", name, err, ErrMalformedCiphertext)
+		}
+	}
+	for name, in := range map[string][]byte{
+		"short":      make([]byte, 65+Size-1),
+		"compressed": append([]byte{2}, make([]byte, 64+Size)...),
+	} {
+		if _, _, _, _, err := DecodeSM2CipherRaw(in); !errors.Is(err, ErrMalformedCiphertext) {
+			t.Errorf("DecodeSM2CipherRaw(%s): err = %v, want %v", name, err, ErrMalformedCiphertext)
+		}
+	}
+	if _, err := EncodeSM2Cipher(big.NewInt(1), big.NewInt(2), make([]byte, 20), nil); !errors.Is(err, ErrMalformedCiphertext) {
+		t.Errorf("EncodeSM2Cipher with a 20-byte C3: err = %v", err)
+	}
+	if _, err := EncodeSM2CipherRaw(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(2), make([]byte, Size), nil); !errors.Is(err, ErrMalformedCiphertext) {
+		t.Errorf("EncodeSM2CipherRaw with a 257-bit x: err = %v", err)
+	}
+}
+
+// encodeCipherUnchecked builds an SM2Cipher SEQUENCE with an arbitrary
+// C3, bypassing the length check in EncodeSM2Cipher.
+func encodeCipherUnchecked(c3 []byte) []byte {
+	var b cryptobyte.Builder
+	b.AddASN1(asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		b.AddASN1Int64(1)
+		b.AddASN1Int64(2)
+		b.AddASN1OctetString(c3)
+		b.AddASN1OctetString([]byte("c2"))
+	})
+	return b.BytesOrPanic()
+}
@@ -0,0 +1,101 @@
+package sm3
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"io"
+)
+
+// sm2EncryptAttempts bounds the number of ephemeral keys EncryptSM2 tries.
+// An all-zero KDF mask has probability 2^(-8·len(msg)) per attempt,
+// so reaching the bound means the KDF or the random source is broken.
+const sm2EncryptAttempts = 100
+
+// EncryptSM2 encrypts msg to pub with SM2 public key encryption
+// (GB/T 32918.4 section 6) and returns the ciphertext in the ASN.1 form of
+// EncodeSM2Cipher, as OpenSSL produces it. The ephemeral key is drawn from
+// rand.
+//
+// If the KDF mask t comes out all zero the standard requires a new
+// ephemeral key. EncryptSM2 gives up after a bounded number of attempts
+// with an error wrapping ErrKDFFailure rather than looping forever. An
+// empty msg, whose mask is trivially all zero, is rejected with
+// ErrBadLength.
+func EncryptSM2(rand io.Reader, pub *SM2PublicKey, msg []byte) ([]byte, error) {
+	return encryptSM2(rand, pub, msg, KDF)
+}
+
+// encryptSM2 is EncryptSM2 with the key derivation function as a
+// parameter, so tests can force the all-zero retry path.
+func encryptSM2(rand io.Reader, pub *SM2PublicKey, msg []byte, kdf func(z []byte, keyLen int) []byte) ([]byte, error) {
+	if len(msg) == 0 {
+		return nil, fmt.Errorf("%w: empty SM2 plaintext", ErrBadLength)
+	}
+	c := P256SM2()
+	if pub == nil || pub.X == nil || pub.Y == nil || !c.IsOnCurve(pub.X, pub.Y) {
+		return nil, ErrInvalidPublicKey
+	}
+	for i := 0; i < sm2EncryptAttempts; i++ {
+		k, err := randScalar(c, rand)
+		if err != nil {
+			return nil, fmt.Errorf("sm3: reading SM2 encryption randomness: %w", err)
+		}
+		c1x, c1y := c.ScalarBaseMult(k.Bytes())
+		x2, y2 := c.ScalarMult(pub.X, pub.Y, k.Bytes())
+		xy := append(x2.FillBytes(make([]byte, 32)), y2.FillBytes(make([]byte, 32))...)
+		t := kdf(xy, len(msg))
+		if allZero(t) {
+			continue
+		}
+		subtle.XORBytes(t, t, msg)
+		return EncodeSM2Cipher(c1x, c1y, sm2CheckValue(xy, msg), t)
+	}
+	return nil, fmt.Errorf("%w after %d ephemeral keys", ErrKDFFailure, sm2EncryptAttempts)
+}
+
+// DecryptSM2 decrypts an SM2 ciphertext in the ASN.1 form of
+// EncodeSM2Cipher with priv (GB/T 32918.4 section 7). It returns an error
+// wrapping ErrMalformedCiphertext if the ciphertext cannot be decoded or
+// C1 is not on the curve, ErrKDFFailure if the mask is all zero, and
+// ErrAuth if the check value C3 does not match.
+func DecryptSM2(priv *SM2PrivateKey, ciphertext []byte) ([]byte, error) {
+	c1x, c1y, c3, c2, err := DecodeSM2Cipher(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	c := P256SM2()
+	if !c.IsOnCurve(c1x, c1y) {
+		return nil, fmt.Errorf("%w: C1 is not on the curve", ErrMalformedCiphertext)
+	}
+	x2, y2 := c.ScalarMult(c1x, c1y, priv.D.Bytes())
+	xy := append(x2.FillBytes(make([]byte, 32)), y2.FillBytes(make([]byte, 32))...)
+	msg := KDF(xy, len(c2))
+	if allZero(msg) {
+		return nil, ErrKDFFailure
+	}
+	subtle.XORBytes(msg, msg, c2)
+	if u := sm2CheckValue(xy, msg); subtle.ConstantTimeCompare(u, c3) != 1 {
+		return nil, ErrAuth
+	}
+	return msg, nil
+}
+
+// sm2CheckValue returns C3 = SM3(x2 || msg || y2) for xy = x2 || y2.
+func sm2CheckValue(xy, msg []byte) []byte {
+	var d digest
+	d.Reset()
+	d.Write(xy[:32])
+	d.Write(msg)
+	d.Write(xy[32:])
+	sum := d.checkSum()
+	return sum[:]
+}
+
+// allZero reports whether every byte of b is zero.
+func allZero(b []byte) bool {
+	var acc byte
+	for _, v := range b {
+		acc |= v
+	}
+	return acc == 0
+}
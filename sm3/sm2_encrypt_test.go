@@ -0,0 +1,109 @@
+package sm3
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+func TestDecryptSM2Fixture(t *testing.T) {
+	ct, _ := hex.DecodeString(sm2CipherFixture)
+	priv := sm2KeyFromScalar(bigFromHex(t, sm2CipherFixtureKey))
+	msg, err := DecryptSM2(priv, ct)
+	if err != nil || string(msg) != "encryption standard" {
+		t.Fatalf("DecryptSM2(OpenSSL ciphertext) = %q, %v", msg, err)
+	}
+
+	tampered := bytes.Clone(ct)
+	tampered[len(tampered)-1] ^= 1
+	if _, err := DecryptSM2(priv, tampered); !errors.Is(err, ErrAuth) {
+		t.Errorf("tampered C2: err = %v, want ErrAuth", err)
+	}
+	other := sm2KeyFromScalar(bigFromHex(t, sm2SignVector.d))
+	if _, err := DecryptSM2(other, ct); !errors.Is(err, ErrAuth) {
+		t.Errorf("wrong key: err = %v, want ErrAuth", err)
+	}
+	if _, err := DecryptSM2(priv, ct[:10]); !errors.Is(err, ErrMalformedCiphertext) {
+		t.Errorf("truncated: err = %v, want ErrMalformedCiphertext", err)
+	}
+}
+
+func TestEncryptSM2RoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(5))
+	priv, err := GenerateSM2Key(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []int{1, 19, Size, 100} {
+		msg := treeTestData(n)
+		ct, err := EncryptSM2(r, &priv.SM2PublicKey, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := DecryptSM2(priv, ct); err != nil || !bytes.Equal(got, msg) {
+			t.Errorf("%d bytes: DecryptSM2 = %x, %v; want %x", n, got, err, msg)
+		}
+	}
+	if _, err := EncryptSM2(r, &priv.SM2PublicKey, nil); !errors.Is(err, ErrBadLength) {
+		t.Errorf("empty message: err = %v, want ErrBadLength", err)
+	}
+	bad := priv.SM2PublicKey
+	bad.Y = bad.X
+	if _, err := EncryptSM2(r, &bad, []byte("x")); !errors.Is(err, ErrInvalidPublicKey) {
+		t.Errorf("off-curve key: err = %v, want ErrInvalidPublicKey", err)
+	}
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+func TestEncryptSM2KDFFailure(t *testing.T) {
+	priv, err := GenerateSM2Key(rand.New(rand.NewSource(6)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := 0
+	zeroKDF := func(z []byte, n int) []byte {
+		calls++
+		return make([]byte, n)
+	}
+	rng := &countingReader{r: rand.New(rand.NewSource(7))}
+	_, err = encryptSM2(rng, &priv.SM2PublicKey, []byte("msg"), zeroKDF)
+	if !errors.Is(err, ErrKDFFailure) {
+		t.Fatalf("err = %v, want ErrKDFFailure", err)
+	}
+	if calls != sm2EncryptAttempts {
+		t.Errorf("KDF called %d times, want %d", calls, sm2EncryptAttempts)
+	}
+	if rng.n < sm2EncryptAttempts*32 {
+		t.Errorf("read %d random bytes, want a fresh ephemeral key per attempt", rng.n)
+	}
+
+	// A mask that is zero only the first few times is retried past.
+	calls = 0
+	flaky := func(z []byte, n int) []byte {
+		if calls++; calls < 3 {
+			return make([]byte, n)
+		}
+		return KDF(z, n)
+	}
+	ct, err := encryptSM2(rand.New(rand.NewSource(8)), &priv.SM2PublicKey, []byte("msg"), flaky)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg, err := DecryptSM2(priv, ct); err != nil || string(msg) != "msg" {
+		t.Errorf("DecryptSM2 after retries = %q, %v", msg, err)
+	}
+}
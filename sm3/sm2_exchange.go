@@ -0,0 +1,127 @@
+package sm3
+
+import (
+	"errors"
+	"math/big"
+)
+
+// SM2 key exchange as specified in GB/T 32918.3 (GM/T 0003.3) section 6.
+
+// reduceX computes x̄ = 2^w + (x & (2^w - 1)) with w = ⌈⌈log2(n)⌉/2⌉ - 1,
+// keeping the low w bits of x and setting bit w.
+func reduceX(x *big.Int) *big.Int {
+	w := (P256SM2().Params().N.BitLen()+1)/2 - 1
+	mask := new(big.Int).Lsh(big.NewInt(1), uint(w))
+	r := new(big.Int).Sub(mask, big.NewInt(1))
+	r.And(r, x)
+	return r.Or(r, mask)
+}
+
+// exchangeSecret computes [t](P + [x̄]R), where t = (d + x̄(self)·r) mod n
+// from the local static scalar d and ephemeral key r, and (P, R) are the
+// peer's static and ephemeral public keys.
+func exchangeSecret(priv, ephemeral *SM2PrivateKey, peer, peerEphemeral *SM2PublicKey) (x, y *big.Int, err error) {
+	c := P256SM2()
+	if !c.IsOnCurve(peer.X, peer.Y) || !c.IsOnCurve(peerEphemeral.X, peerEphemeral.Y) {
+		return nil, nil, ErrInvalidPublicKey
+	}
+	n := c.Params().N
+
+	t := reduceX(ephemeral.X)
+	t.Mul(t, ephemeral.D)
+	t.Add(t, priv.D)
+	t.Mod(t, n)
+
+	rx, ry := c.ScalarMult(peerEphemeral.X, peerEphemeral.Y, reduceX(peerEphemeral.X).Bytes())
+	px, py := c.Add(peer.X, peer.Y, rx, ry)
+	// The cofactor h of sm2p256v1 is 1.
+	x, y = c.ScalarMult(px, py, t.Bytes())
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return nil, nil, errors.New("sm3: SM2 key exchange produced the point at infinity")
+	}
+	return x, y, nil
+}
+
+// exchangeOutputs derives the shared key and the two confirmation tags
+//
+//	S(tag) = SM3(tag || y || SM3(x || ZA || ZB || x1 || y1 || x2 || y2))
+//
+// for tag 0x02 (SB/S1) and 0x03 (SA/S2), where (x, y) is the shared point
+// and (x1, y1), (x2, y2) are the initiator's and responder's ephemeral keys.
+func exchangeOutputs(x, y *big.Int, za, zb [Size]byte, ra, rb *SM2PublicKey, keyLen int) (key []byte, s2, s3 [Size]byte) {
+	var buf [32]byte
+	z := make([]byte, 0, 4*32)
+	z = append(z, x.FillBytes(buf[:])...)
+	z = append(z, y.FillBytes(buf[:])...)
+	z = append(z, za[:]...)
+	z = append(z, zb[:]...)
+	key = KDF(z, keyLen)
+
+	h := New()
+	h.Write(x.FillBytes(buf[:]))
+	h.Write(za[:])
+	h.Write(zb[:])
+	for _, v := range []*big.Int{ra.X, ra.Y, rb.X, rb.Y} {
+		h.Write(v.FillBytes(buf[:]))
+	}
+	inner := h.Sum(nil)
+
+	for _, tag := range []byte{0x02, 0x03} {
+		h.Reset()
+		h.Write([]byte{tag})
+		h.Write(y.FillBytes(buf[:]))
+		h.Write(inner)
+		if tag == 0x02 {
+			h.Sum(s2[:0])
+		} else {
+			h.Sum(s3[:0])
+		}
+	}
+	return key, s2, s3
+}
+
+// InitiatorKeyExchange runs the initiator (user A) side of SM2 key exchange.
+// priv and ephemeral are A's static key and the ephemeral key whose public
+// half RA was sent to B; peer and peerEphemeral are B's static key and RB.
+//
+// It returns keyLen bytes of shared key, S1, which must equal the SB sent by
+// the responder, and SA, which is sent to the responder for its S2 check.
+func InitiatorKeyExchange(priv, ephemeral *SM2PrivateKey, id []byte, peer, peerEphemeral *SM2PublicKey, peerID []byte, keyLen int) (key []byte, s1, sa [Size]byte, err error) {
+	x, y, err := exchangeSecret(priv, ephemeral, peer, peerEphemeral)
+	if err != nil {
+		return nil, s1, sa, err
+	}
+	za, err := ZA(&priv.SM2PublicKey, id)
+	if err != nil {
+		return nil, s1, sa, err
+	}
+	zb, err := ZA(peer, peerID)
+	if err != nil {
+		return nil, s1, sa, err
+	}
+	key, s1, sa = exchangeOutputs(x, y, za, zb, &ephemeral.SM2PublicKey, peerEphemeral, keyLen)
+	return key, s1, sa, nil
+}
+
+// ResponderKeyExchange runs the responder (user B) side of SM2 key exchange.
+// priv and ephemeral are B's static key and the ephemeral key whose public
+// half RB is sent to A; peer and peerEphemeral are A's static key and RA.
+//
+// It returns keyLen bytes of shared key, SB, which is sent to the initiator,
+// and S2, which must equal the SA received from the initiator.
+func ResponderKeyExchange(priv, ephemeral *SM2PrivateKey, id []byte, peer, peerEphemeral *SM2PublicKey, peerID []byte, keyLen int) (key []byte, sb, s2 [Size]byte, err error) {
+	x, y, err := exchangeSecret(priv, ephemeral, peer, peerEphemeral)
+	if err != nil {
+		return nil, sb, s2, err
+	}
+	za, err := ZA(peer, peerID)
+	if err != nil {
+		return nil, sb, s2, err
+	}
+	zb, err := ZA(&priv.SM2PublicKey, id)
+	if err != nil {
+		return nil, sb, s2, err
+	}
+	key, sb, s2 = exchangeOutputs(x, y, za, zb, peerEphemeral, &ephemeral.SM2PublicKey, keyLen)
+	return key, sb, s2, nil
+}
@@ -0,0 +1,132 @@
+package sm3
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func scalarFromHex(t *testing.T, s string) *SM2PrivateKey {
+	t.Helper()
+	d, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		t.Fatalf("bad scalar %q", s)
+	}
+	return sm2KeyFromScalar(d)
+}
+
+func TestReduceX(t *testing.T) {
+	x, _ := new(big.Int).SetString("FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF0123456789ABCDEF0123456789ABCDEF", 16)
+	// w = 127: keep the low 127 bits and set bit 127.
+	want, _ := new(big.Int).SetString("8123456789ABCDEF0123456789ABCDEF", 16)
+	if got := reduceX(x); got.Cmp(want) != 0 {
+		t.Errorf("reduceX = %x, want %x", got, want)
+	}
+	if got := reduceX(big.NewInt(0)); got.BitLen() != 128 {
+		t.Errorf("reduceX(0) = %x, want 2^127", got)
+	}
+}
+
+// sm2ExchangeVector is on sm2p256v1. The worked example of GM/T 0003.3
+// Annex A uses a 256-bit test curve instead, so the expected values were
+// produced with an independent SM2 implementation.
+var sm2ExchangeVector = struct {
+	staticA, ephemeralA, staticB, ephemeralB string
+	sharedPoint, key                         string
+}{
+	"e04c3fd77408b56a648ad439f673511a2ae248def3bab26bdfc9cdbd0ae9607e",
+	"6fe0bac5b09d3ab10f724638811c34464790520e4604e71e6cb0e5310623b5b1",
+	"7a1136f60d2c5531447e5a3093078c2a505abf74f33aefed927ac0a5b27e7dd7",
+	"d0233bdbb0b8a7bfe1aab66132ef06fc4efaedd5d5000692bc21185242a31f6f",
+	"6ab5c9709277837cedc515730d04751ef81c71e81e0e52357a98cf41796ab560508da6e858b40c6264f17943037434174284a847f32c4f54104a98af5148d89f",
+	"1ad809ebc56ddda532020c352e1e60b121ebeb7b4e632db4dd90a362cf844f8bba85140e30984ddb581199bf5a9dda22",
+}
+
+func TestSM2KeyExchangeVector(t *testing.T) {
+	v := sm2ExchangeVector
+	a, ra := scalarFromHex(t, v.staticA), scalarFromHex(t, v.ephemeralA)
+	b, rb := scalarFromHex(t, v.staticB), scalarFromHex(t, v.ephemeralB)
+	idA, idB := []byte("Alice"), []byte("Bob")
+
+	ux, uy, err := exchangeSecret(a, ra, &b.SM2PublicKey, &rb.SM2PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf [64]byte
+	ux.FillBytes(buf[:32])
+	uy.FillBytes(buf[32:])
+	if got := hex.EncodeToString(buf[:]); got != v.sharedPoint {
+		t.Errorf("U = %s, want %s", got, v.sharedPoint)
+	}
+
+	keyA, s1, sa, err := InitiatorKeyExchange(a, ra, idA, &b.SM2PublicKey, &rb.SM2PublicKey, idB, 48)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyB, sb, s2, err := ResponderKeyExchange(b, rb, idB, &a.SM2PublicKey, &ra.SM2PublicKey, idA, 48)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hex.EncodeToString(keyA); got != v.key {
+		t.Errorf("KA = %s, want %s", got, v.key)
+	}
+	if !bytes.Equal(keyA, keyB) {
+		t.Errorf("KA = %x, KB = %x", keyA, keyB)
+	}
+	if s1 != sb {
+		t.Errorf("S1 = %x, SB = %x", s1, sb)
+	}
+	if sa != s2 {
+		t.Errorf("SA = %x, S2 = %x", sa, s2)
+	}
+	if s1 == sa {
+		t.Error("the two confirmation tags are equal")
+	}
+}
+
+func TestSM2KeyExchangeRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	keys := make([]*SM2PrivateKey, 4)
+	for i := range keys {
+		var err error
+		if keys[i], err = GenerateSM2Key(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a, ra, b, rb := keys[0], keys[1], keys[2], keys[3]
+
+	keyA, s1, sa, err := InitiatorKeyExchange(a, ra, DefaultSM2UID, &b.SM2PublicKey, &rb.SM2PublicKey, DefaultSM2UID, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyB, sb, s2, err := ResponderKeyExchange(b, rb, DefaultSM2UID, &a.SM2PublicKey, &ra.SM2PublicKey, DefaultSM2UID, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(keyA, keyB) || s1 != sb || sa != s2 {
+		t.Error("initiator and responder disagree")
+	}
+
+	// A responder that thinks it is talking to someone else derives a
+	// different key and the confirmation tags catch it.
+	keyB, sb, _, err = ResponderKeyExchange(b, rb, DefaultSM2UID, &a.SM2PublicKey, &ra.SM2PublicKey, []byte("mallory"), 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(keyA, keyB) || s1 == sb {
+		t.Error("mismatched identities still agree")
+	}
+}
+
+func TestSM2KeyExchangeRejectsOffCurve(t *testing.T) {
+	r := rand.New(rand.NewSource(4))
+	a, _ := GenerateSM2Key(r)
+	ra, _ := GenerateSM2Key(r)
+	b, _ := GenerateSM2Key(r)
+	bad := &SM2PublicKey{Curve: P256SM2(), X: big.NewInt(1), Y: big.NewInt(1)}
+	if _, _, _, err := InitiatorKeyExchange(a, ra, nil, &b.SM2PublicKey, bad, nil, 16); err == nil {
+		t.Error("off-curve ephemeral key was accepted")
+	}
+}
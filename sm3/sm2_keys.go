@@ -0,0 +1,189 @@
+package sm3
+
+import (
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+
+	"golang.org/x/crypto/cryptobyte"
+	cbasn1 "golang.org/x/crypto/cryptobyte/asn1"
+)
+
+// DER encodings of SM2 keys, as written by GM tooling and OpenSSL: PKCS#8
+// (RFC 5208) wrapping a SEC1 ECPrivateKey (RFC 5915) for private keys, and
+// SubjectPublicKeyInfo for public keys, both with algorithm id-ecPublicKey
+// and the SM2 named curve.
+
+// MarshalSM2PublicKey returns the DER SubjectPublicKeyInfo of pub.
+func MarshalSM2PublicKey(pub *SM2PublicKey) ([]byte, error) {
+	point, err := marshalSM2Point(pub)
+	if err != nil {
+		return nil, err
+	}
+	var b cryptobyte.Builder
+	b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		addSM2AlgorithmIdentifier(b)
+		b.AddASN1BitString(point)
+	})
+	return b.Bytes()
+}
+
+// MarshalSM2PrivateKey returns the DER PKCS#8 encoding of key, with the
+// public point included in the inner ECPrivateKey.
+func MarshalSM2PrivateKey(key *SM2PrivateKey) ([]byte, error) {
+	if !validSM2Scalar(key.D) {
+		return nil, ErrMalformedPrivateKey
+	}
+	point, err := marshalSM2Point(&key.SM2PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	byteLen := (P256SM2().Params().BitSize + 7) / 8
+
+	var sec1 cryptobyte.Builder
+	sec1.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		b.AddASN1Int64(1)
+		b.AddASN1OctetString(key.D.FillBytes(make([]byte, byteLen)))
+		b.AddASN1(cbasn1.Tag(1).Constructed().ContextSpecific(), func(b *cryptobyte.Builder) {
+			b.AddASN1BitString(point)
+		})
+	})
+	inner, err := sec1.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	var b cryptobyte.Builder
+	b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		b.AddASN1Int64(0)
+		addSM2AlgorithmIdentifier(b)
+		b.AddASN1OctetString(inner)
+	})
+	return b.Bytes()
+}
+
+// ParseSM2PrivateKey parses an SM2 private key in PKCS#8 DER, or a bare
+// SEC1 ECPrivateKey. If the encoding carries the public point it must
+// match the private scalar. Errors wrap ErrMalformedPrivateKey, or
+// ErrUnsupportedAlgorithm for a key on another curve.
+func ParseSM2PrivateKey(der []byte) (*SM2PrivateKey, error) {
+	var (
+		input    = cryptobyte.String(der)
+		seq      cryptobyte.String
+		version  int64
+		algo     cryptobyte.String
+		algoOID  asn1.ObjectIdentifier
+		curveOID asn1.ObjectIdentifier
+		sec1     []byte
+	)
+	if !input.ReadASN1(&seq, cbasn1.SEQUENCE) || !input.Empty() ||
+		!seq.ReadASN1Integer(&version) {
+		return nil, fmt.Errorf("%w: not a DER SEQUENCE", ErrMalformedPrivateKey)
+	}
+	if version == 1 {
+		return parseSEC1PrivateKey(der, false)
+	}
+	// Any attributes after the key are ignored.
+	if version != 0 ||
+		!seq.ReadASN1(&algo, cbasn1.SEQUENCE) ||
+		!algo.ReadASN1ObjectIdentifier(&algoOID) ||
+		!seq.ReadASN1Bytes(&sec1, cbasn1.OCTET_STRING) {
+		return nil, fmt.Errorf("%w: bad PKCS#8 structure", ErrMalformedPrivateKey)
+	}
+	switch {
+	case algoOID.Equal(oidNamedCurveSM2):
+	case algoOID.Equal(oidPublicKeyECDSA):
+		if !algo.ReadASN1ObjectIdentifier(&curveOID) {
+			return nil, fmt.Errorf("%w: missing named curve", ErrMalformedPrivateKey)
+		}
+		if !curveOID.Equal(oidNamedCurveSM2) {
+			return nil, fmt.Errorf("%w: elliptic curve %v", ErrUnsupportedAlgorithm, curveOID)
+		}
+	default:
+		return nil, fmt.Errorf("%w: private key algorithm %v", ErrUnsupportedAlgorithm, algoOID)
+	}
+	return parseSEC1PrivateKey(sec1, true)
+}
+
+// parseSEC1PrivateKey parses an ECPrivateKey. Unless the enclosing PKCS#8
+// structure already named the SM2 curve, the parameters field must.
+func parseSEC1PrivateKey(der []byte, curveKnown bool) (*SM2PrivateKey, error) {
+	var (
+		input     = cryptobyte.String(der)
+		seq       cryptobyte.String
+		version   int64
+		scalar    []byte
+		params    cryptobyte.String
+		hasParams bool
+		pubField  cryptobyte.String
+		hasPub    bool
+	)
+	tagParams := cbasn1.Tag(0).Constructed().ContextSpecific()
+	tagPub := cbasn1.Tag(1).Constructed().ContextSpecific()
+	if !input.ReadASN1(&seq, cbasn1.SEQUENCE) || !input.Empty() ||
+		!seq.ReadASN1Integer(&version) || version != 1 ||
+		!seq.ReadASN1Bytes(&scalar, cbasn1.OCTET_STRING) ||
+		!seq.ReadOptionalASN1(&params, &hasParams, tagParams) ||
+		!seq.ReadOptionalASN1(&pubField, &hasPub, tagPub) ||
+		!seq.Empty() {
+		return nil, fmt.Errorf("%w: bad ECPrivateKey structure", ErrMalformedPrivateKey)
+	}
+	if hasParams {
+		var oid asn1.ObjectIdentifier
+		if !params.ReadASN1ObjectIdentifier(&oid) || !params.Empty() {
+			return nil, fmt.Errorf("%w: bad curve parameters", ErrMalformedPrivateKey)
+		}
+		if !oid.Equal(oidNamedCurveSM2) {
+			return nil, fmt.Errorf("%w: elliptic curve %v", ErrUnsupportedAlgorithm, oid)
+		}
+	} else if !curveKnown {
+		return nil, fmt.Errorf("%w: missing named curve", ErrMalformedPrivateKey)
+	}
+
+	d := new(big.Int).SetBytes(scalar)
+	if !validSM2Scalar(d) {
+		return nil, fmt.Errorf("%w: scalar out of range", ErrMalformedPrivateKey)
+	}
+	key := sm2KeyFromScalar(d)
+	if hasPub {
+		var point asn1.BitString
+		if !pubField.ReadASN1BitString(&point) || !pubField.Empty() {
+			return nil, fmt.Errorf("%w: bad public key field", ErrMalformedPrivateKey)
+		}
+		want, err := marshalSM2Point(&key.SM2PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		if point.BitLength != 8*len(want) || string(point.Bytes) != string(want) {
+			return nil, fmt.Errorf("%w: public key does not match the private scalar", ErrMalformedPrivateKey)
+		}
+	}
+	return key, nil
+}
+
+// validSM2Scalar reports whether d is in [1, n-2], the range of SM2
+// private keys.
+func validSM2Scalar(d *big.Int) bool {
+	nMinus1 := new(big.Int).Sub(P256SM2().Params().N, big.NewInt(1))
+	return d != nil && d.Sign() > 0 && d.Cmp(nMinus1) < 0
+}
+
+// marshalSM2Point returns the uncompressed encoding 0x04 || x || y of pub.
+func marshalSM2Point(pub *SM2PublicKey) ([]byte, error) {
+	if pub == nil {
+		return nil, ErrInvalidPublicKey
+	}
+	point := MarshalPoint(pub.X, pub.Y, false)
+	if point == nil {
+		return nil, ErrInvalidPublicKey
+	}
+	return point, nil
+}
+
+// addSM2AlgorithmIdentifier adds the AlgorithmIdentifier for an SM2 key.
+func addSM2AlgorithmIdentifier(b *cryptobyte.Builder) {
+	b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		b.AddASN1ObjectIdentifier(oidPublicKeyECDSA)
+		b.AddASN1ObjectIdentifier(oidNamedCurveSM2)
+	})
+}
@@ -0,0 +1,76 @@
+package sm3
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// MarshalPoint returns the SEC 1 encoding of the sm2p256v1 point (x, y):
+// 0x04 || x || y uncompressed, or 0x02 or 0x03, for even or odd y, followed
+// by x when compressed. Coordinates are 32 bytes, big-endian. It returns
+// nil if (x, y) is not on the curve.
+func MarshalPoint(x, y *big.Int, compressed bool) []byte {
+	c := P256SM2()
+	if x == nil || y == nil || !c.IsOnCurve(x, y) {
+		return nil
+	}
+	byteLen := (c.Params().BitSize + 7) / 8
+	if compressed {
+		out := make([]byte, 1+byteLen)
+		out[0] = 2 | byte(y.Bit(0))
+		x.FillBytes(out[1:])
+		return out
+	}
+	out := make([]byte, 1+2*byteLen)
+	out[0] = 4
+	x.FillBytes(out[1 : 1+byteLen])
+	y.FillBytes(out[1+byteLen:])
+	return out
+}
+
+// UnmarshalPoint parses a point on sm2p256v1 in either SEC 1 form produced
+// by MarshalPoint, recovering y from x for the compressed form by solving
+// y² = x³ + ax + b modulo p. It returns an error wrapping
+// ErrInvalidPublicKey if data is not such an encoding or the point is not
+// on the curve; the point at infinity is rejected.
+func UnmarshalPoint(data []byte) (x, y *big.Int, err error) {
+	c := P256SM2()
+	p := c.Params().P
+	byteLen := (c.Params().BitSize + 7) / 8
+	if len(data) == 0 {
+		return nil, nil, fmt.Errorf("%w: empty point encoding", ErrInvalidPublicKey)
+	}
+	switch form := data[0]; {
+	case form == 4 && len(data) == 1+2*byteLen:
+		x = new(big.Int).SetBytes(data[1 : 1+byteLen])
+		y = new(big.Int).SetBytes(data[1+byteLen:])
+		if x.Cmp(p) >= 0 || y.Cmp(p) >= 0 || !c.IsOnCurve(x, y) {
+			return nil, nil, fmt.Errorf("%w: point not on the curve", ErrInvalidPublicKey)
+		}
+		return x, y, nil
+	case (form == 2 || form == 3) && len(data) == 1+byteLen:
+		x = new(big.Int).SetBytes(data[1:])
+		if x.Cmp(p) >= 0 {
+			return nil, nil, fmt.Errorf("%w: x not reduced modulo p", ErrInvalidPublicKey)
+		}
+		// y² = x³ - 3x + b
+		y2 := new(big.Int).Exp(x, big.NewInt(3), p)
+		y2.Sub(y2, new(big.Int).Lsh(x, 1))
+		y2.Sub(y2, x)
+		y2.Add(y2, c.Params().B)
+		y2.Mod(y2, p)
+		y = new(big.Int).ModSqrt(y2, p)
+		if y == nil {
+			return nil, nil, fmt.Errorf("%w: no point with this x", ErrInvalidPublicKey)
+		}
+		if y.Bit(0) != uint(form&1) {
+			if y.Sign() == 0 {
+				return nil, nil, fmt.Errorf("%w: odd y requested for y = 0", ErrInvalidPublicKey)
+			}
+			y.Sub(p, y)
+		}
+		return x, y, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: %d-byte encoding with form byte %#02x", ErrInvalidPublicKey, len(data), data[0])
+	}
+}
@@ -0,0 +1,88 @@
+package sm3
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestMarshalPointGenerator(t *testing.T) {
+	params := P256SM2().Params()
+	// Gy ends in 0xA0, so G compresses with the even prefix.
+	want := append([]byte{0x02}, params.Gx.FillBytes(make([]byte, 32))...)
+	if got := MarshalPoint(params.Gx, params.Gy, true); !bytes.Equal(got, want) {
+		t.Errorf("compressed G = %x, want %x", got, want)
+	}
+	uncompressed := MarshalPoint(params.Gx, params.Gy, false)
+	if len(uncompressed) != 65 || uncompressed[0] != 0x04 {
+		t.Errorf("uncompressed G = %x", uncompressed)
+	}
+}
+
+func TestPointRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	var sawEven, sawOdd bool
+	for i := 0; i < 16; i++ {
+		priv, err := GenerateSM2Key(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, compressed := range []bool{false, true} {
+			data := MarshalPoint(priv.X, priv.Y, compressed)
+			x, y, err := UnmarshalPoint(data)
+			if err != nil {
+				t.Fatalf("key %d, compressed %v: %v", i, compressed, err)
+			}
+			if x.Cmp(priv.X) != 0 || y.Cmp(priv.Y) != 0 {
+				t.Errorf("key %d, compressed %v: round trip gave a different point", i, compressed)
+			}
+			if compressed {
+				sawEven = sawEven || data[0] == 2
+				sawOdd = sawOdd || data[0] == 3
+			}
+		}
+	}
+	if !sawEven || !sawOdd {
+		t.Error("test keys did not cover both compressed prefixes")
+	}
+}
+
+func TestUnmarshalPointInvalid(t *testing.T) {
+	params := P256SM2().Params()
+	good := MarshalPoint(params.Gx, params.Gy, false)
+
+	offCurve := bytes.Clone(good)
+	offCurve[64] ^= 1
+
+	// An x for which x³ + ax + b is not a square modulo p.
+	var noSqrt []byte
+	for x := int64(1); noSqrt == nil; x++ {
+		xb := big.NewInt(x).FillBytes(make([]byte, 32))
+		if _, _, err := UnmarshalPoint(append([]byte{0x02}, xb...)); err != nil {
+			noSqrt = append([]byte{0x02}, xb...)
+		}
+	}
+
+	unreduced := append([]byte{0x03}, params.P.FillBytes(make([]byte, 32))...)
+
+	for name, data := range map[string][]byte{
+		"empty":        nil,
+		"infinity":     {0x00},
+		"off curve":    offCurve,
+		"no sqrt":      noSqrt,
+		"x = p":        unreduced,
+		"truncated":    good[:64],
+		"bad prefix":   append([]byte{0x05}, good[1:]...),
+		"hybrid form":  append([]byte{0x06}, good[1:]...),
+		"long compact": append(MarshalPoint(params.Gx, params.Gy, true), 0),
+	} {
+		if _, _, err := UnmarshalPoint(data); !errors.Is(err, ErrInvalidPublicKey) {
+			t.Errorf("%s: err = %v, want ErrInvalidPublicKey", name, err)
+		}
+	}
+	if MarshalPoint(big.NewInt(1), big.NewInt(1), false) != nil {
+		t.Error("MarshalPoint accepted an off-curve point")
+	}
+}
@@ -0,0 +1,70 @@
+package sm3
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"testing"
+)
+
+func TestRecoverSM2(t *testing.T) {
+	priv := sm2VectorKey(t)
+	pub := &priv.SM2PublicKey
+	e, err := SM2Digest(pub, DefaultSM2UID, []byte(sm2SignVector.msg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := sm2VectorSignature(t)
+
+	// The recovery ID of the example follows from its nonce point.
+	n := P256SM2().Params().N
+	rx, ry := P256SM2().ScalarBaseMult(bigFromHex(t, sm2SignVector.k).Bytes())
+	id := int(ry.Bit(0))
+	if rx.Cmp(n) >= 0 {
+		id |= 2
+	}
+	got, err := RecoverSM2(e, sig, id)
+	if err != nil {
+		t.Fatalf("RecoverSM2(id %d) = %v", id, err)
+	}
+	if got.X.Cmp(pub.X) != 0 || got.Y.Cmp(pub.Y) != 0 {
+		t.Errorf("RecoverSM2(id %d) = (%X, %X), want (%X, %X)", id, got.X, got.Y, pub.X, pub.Y)
+	}
+
+	// The other parity gives some other key, or none.
+	if other, err := RecoverSM2(e, sig, id^1); err == nil && other.X.Cmp(pub.X) == 0 {
+		t.Errorf("RecoverSM2(id %d) also gave the signer's key", id^1)
+	}
+
+	for _, bad := range []int{-1, 4} {
+		if _, err := RecoverSM2(e, sig, bad); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("RecoverSM2(id %d): err = %v, want %v", bad, err, ErrInvalidSignature)
+		}
+	}
+}
+
+func TestRecoverSM2Random(t *testing.T) {
+	// For fresh keys and nonces exactly one recovery ID with bit 1 clear
+	// yields the signer; x1 >= n happens with negligible probability.
+	for i := 0; i < 8; i++ {
+		priv, err := GenerateSM2Key(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e := Sum([]byte{byte(i)})
+		sig, err := priv.SignDigest(rand.Reader, e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		matches := 0
+		for id := 0; id < 2; id++ {
+			if pub, err := RecoverSM2(e, sig, id); err == nil &&
+				bytes.Equal(MarshalPoint(pub.X, pub.Y, false), MarshalPoint(priv.X, priv.Y, false)) {
+				matches++
+			}
+		}
+		if matches != 1 {
+			t.Errorf("key %d: %d recovery IDs gave the signer's key, want 1", i, matches)
+		}
+	}
+}
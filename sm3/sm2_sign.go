@@ -0,0 +1,304 @@
+package sm3
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/hmac"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math/big"
+
+	"golang.org/x/crypto/cryptobyte"
+	"golang.org/x/crypto/cryptobyte/asn1"
+)
+
+// SM2 digital signatures as specified in GB/T 32918.2 (GM/T 0003.2).
+
+// SM2Digest returns e = SM3(ZA || msg), the value an SM2 signature over msg
+// by the holder of pub with identity id is computed on.
+func SM2Digest(pub *SM2PublicKey, id, msg []byte) ([Size]byte, error) {
+	za, err := ZA(pub, id)
+	if err != nil {
+		return [Size]byte{}, err
+	}
+	h := New()
+	h.Write(za[:])
+	h.Write(msg)
+	var e [Size]byte
+	h.Sum(e[:0])
+	return e, nil
+}
+
+// EncodeSignature returns the DER encoding of the SM2 signature (r, s) as
+// the ASN.1 SEQUENCE { r INTEGER, s INTEGER } of GM/T 0009.
+func EncodeSignature(r, s *big.Int) ([]byte, error) {
+	var b cryptobyte.Builder
+	b.AddASN1(asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		b.AddASN1BigInt(r)
+		b.AddASN1BigInt(s)
+	})
+	return b.Bytes()
+}
+
+// DecodeSignature parses a DER-encoded SM2 signature, returning
+// ErrMalformedSignature if it is not a SEQUENCE of exactly two INTEGERs.
+func DecodeSignature(sig []byte) (r, s *big.Int, err error) {
+	r, s = new(big.Int), new(big.Int)
+	var inner cryptobyte.String
+	input := cryptobyte.String(sig)
+	if !input.ReadASN1(&inner, asn1.SEQUENCE) ||
+		!input.Empty() ||
+		!inner.ReadASN1Integer(r) ||
+		!inner.ReadASN1Integer(s) ||
+		!inner.Empty() {
+		return nil, nil, ErrMalformedSignature
+	}
+	return r, s, nil
+}
+
+// VerifySM2 reports whether sig is a valid DER-encoded SM2 signature of msg
+// by the holder of pub with identity id. It recomputes ZA and
+// e = SM3(ZA || msg) and checks the verification equation of GB/T 32918.2
+// section 7.
+//
+// An error is returned only if sig is not well-formed DER or id is too long
+// for ZA. Signatures with r or s outside [1, n-1], or a pub that is not on
+// the curve, are reported as invalid with a nil error.
+func VerifySM2(pub *SM2PublicKey, id, msg, sig []byte) (bool, error) {
+	r, s, err := DecodeSignature(sig)
+	if err != nil {
+		return false, err
+	}
+	e, err := SM2Digest(pub, id, msg)
+	if err != nil {
+		return false, err
+	}
+	return verifySM2Digest(pub, e[:], r, s), nil
+}
+
+// verifySM2Digest checks the SM2 signature (r, s) over the digest e.
+func verifySM2Digest(pub *SM2PublicKey, e []byte, r, s *big.Int) bool {
+	c := P256SM2()
+	n := c.Params().N
+	if r.Sign() <= 0 || s.Sign() <= 0 || r.Cmp(n) >= 0 || s.Cmp(n) >= 0 {
+		return false
+	}
+	if pub == nil || pub.X == nil || pub.Y == nil || !c.IsOnCurve(pub.X, pub.Y) {
+		return false
+	}
+
+	t := new(big.Int).Add(r, s)
+	t.Mod(t, n)
+	if t.Sign() == 0 {
+		return false
+	}
+
+	// (x1, y1) = [s]G + [t]PA
+	x1, y1 := c.ScalarBaseMult(s.Bytes())
+	x2, y2 := c.ScalarMult(pub.X, pub.Y, t.Bytes())
+	x1, _ = c.Add(x1, y1, x2, y2)
+
+	// R = (e + x1) mod n
+	v := new(big.Int).SetBytes(e)
+	v.Add(v, x1)
+	v.Mod(v, n)
+	return v.Cmp(r) == 0
+}
+
+// rfc6979 returns a generator of deterministic nonces in [1, n-1] for the
+// private scalar x and message digest h1, following RFC 6979 section 3.2
+// with HMAC over newHash. Each call returns the next candidate, so a nonce
+// rejected by the signer is replaced as in step h.3.
+func rfc6979(newHash func() hash.Hash, n, x *big.Int, h1 []byte) func() *big.Int {
+	qlen := n.BitLen()
+	rlen := (qlen + 7) / 8
+	hlen := newHash().Size()
+
+	// bits2int, taking the leftmost qlen bits.
+	bits2int := func(b []byte) *big.Int {
+		v := new(big.Int).SetBytes(b)
+		if excess := len(b)*8 - qlen; excess > 0 {
+			v.Rsh(v, uint(excess))
+		}
+		return v
+	}
+	// bits2octets(h1) = int2octets(bits2int(h1) mod n)
+	z := bits2int(h1)
+	if z.Cmp(n) >= 0 {
+		z.Sub(z, n)
+	}
+	seed := append(x.FillBytes(make([]byte, rlen)), z.FillBytes(make([]byte, rlen))...)
+
+	v := bytes.Repeat([]byte{0x01}, hlen)
+	k := make([]byte, hlen)
+	mac := func(key []byte, parts ...[]byte) []byte {
+		m := hmac.New(newHash, key)
+		for _, p := range parts {
+			m.Write(p)
+		}
+		return m.Sum(nil)
+	}
+	k = mac(k, v, []byte{0x00}, seed)
+	v = mac(k, v)
+	k = mac(k, v, []byte{0x01}, seed)
+	v = mac(k, v)
+
+	first := true
+	return func() *big.Int {
+		for {
+			if !first {
+				k = mac(k, v, []byte{0x00})
+				v = mac(k, v)
+			}
+			first = false
+			var t []byte
+			for len(t) < rlen {
+				v = mac(k, v)
+				t = append(t, v...)
+			}
+			if c := bits2int(t[:rlen]); c.Sign() > 0 && c.Cmp(n) < 0 {
+				return c
+			}
+		}
+	}
+}
+
+// signSM2Digest signs the digest e with priv, drawing nonces from nextK
+// until one yields a valid signature (GB/T 32918.2 section 6.1).
+func signSM2Digest(priv *SM2PrivateKey, e []byte, nextK func() (*big.Int, error)) (r, s *big.Int, err error) {
+	c := P256SM2()
+	n := c.Params().N
+	ev := new(big.Int).SetBytes(e)
+
+	dInv := new(big.Int).Add(priv.D, big.NewInt(1))
+	if dInv.ModInverse(dInv, n) == nil {
+		return nil, nil, errors.New("sm3: invalid SM2 private key")
+	}
+	for {
+		k, err := nextK()
+		if err != nil {
+			return nil, nil, err
+		}
+		x1, _ := c.ScalarBaseMult(k.Bytes())
+
+		// r = (e + x1) mod n, retrying if r = 0 or r + k = n.
+		r = new(big.Int).Add(ev, x1)
+		r.Mod(r, n)
+		if r.Sign() == 0 || new(big.Int).Add(r, k).Cmp(n) == 0 {
+			continue
+		}
+
+		// s = ((1 + d)^-1 · (k - r·d)) mod n
+		s = new(big.Int).Mul(r, priv.D)
+		s.Sub(k, s)
+		s.Mul(s, dInv)
+		s.Mod(s, n)
+		if s.Sign() != 0 {
+			return r, s, nil
+		}
+	}
+}
+
+// SignDeterministic returns a DER-encoded SM2 signature of msg by priv
+// under identity id. The nonce is derived from the private key and
+// e = SM3(ZA || msg) as in RFC 6979, with HMAC-SM3 as the PRF and the order
+// of sm2p256v1 as q, so the same key and message always give the same
+// signature and no random source is needed.
+func (priv *SM2PrivateKey) SignDeterministic(id, msg []byte) ([]byte, error) {
+	e, err := SM2Digest(&priv.SM2PublicKey, id, msg)
+	if err != nil {
+		return nil, err
+	}
+	next := rfc6979(New, P256SM2().Params().N, priv.D, e[:])
+	r, s, err := signSM2Digest(priv, e[:], func() (*big.Int, error) { return next(), nil })
+	if err != nil {
+		return nil, err
+	}
+	return EncodeSignature(r, s)
+}
+
+// SignDigest returns a DER-encoded SM2 signature by priv over e, an
+// already computed e = SM3(ZA || msg) as returned by SM2Digest, so that
+// pipelines which hash the message separately do not hash it twice. The
+// nonce is drawn from rand, which must be a cryptographically secure
+// source; a repeated or predictable nonce reveals the private key.
+//
+// The result verifies with VerifySM2 over msg exactly as a signature made
+// by SignDeterministic does.
+func (priv *SM2PrivateKey) SignDigest(rand io.Reader, e [Size]byte) ([]byte, error) {
+	c := P256SM2()
+	r, s, err := signSM2Digest(priv, e[:], func() (*big.Int, error) {
+		k, err := randScalar(c, rand)
+		if err != nil {
+			return nil, fmt.Errorf("sm3: reading SM2 nonce randomness: %w", err)
+		}
+		return k, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return EncodeSignature(r, s)
+}
+
+// SM2SignerOpts are the crypto.SignerOpts of SM2PrivateKey.Sign. UID is
+// the signer identity hashed into ZA; nil means DefaultSM2UID.
+type SM2SignerOpts struct {
+	UID []byte
+}
+
+// HashFunc returns zero: SM2 hashes the message itself, as SM3(ZA || msg).
+func (*SM2SignerOpts) HashFunc() crypto.Hash { return 0 }
+
+// Sign implements crypto.Signer, so an SM2PrivateKey can be used as the
+// private key of a tls.Certificate. As with ed25519, msg is the whole
+// message, not a digest, and opts.HashFunc() must be zero; the signature
+// is over SM3(ZA || msg) with the identity from opts if it is an
+// *SM2SignerOpts, or DefaultSM2UID otherwise. The nonce is drawn from rand
+// as in SignDigest.
+func (priv *SM2PrivateKey) Sign(rand io.Reader, msg []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if h := opts.HashFunc(); h != 0 {
+		return nil, fmt.Errorf("%w: SM2 signs whole messages, not %v digests", ErrUnsupportedAlgorithm, h)
+	}
+	id := DefaultSM2UID
+	if o, ok := opts.(*SM2SignerOpts); ok && o.UID != nil {
+		id = o.UID
+	}
+	e, err := SM2Digest(&priv.SM2PublicKey, id, msg)
+	if err != nil {
+		return nil, err
+	}
+	return priv.SignDigest(rand, e)
+}
+
+// VerifySM2Strict is VerifySM2 with the additional requirement that s lie
+// in the lower half of its range, s <= n/2, for protocols that need
+// signatures in a single canonical form.
+//
+// Standard GM verification does not impose this and signers following
+// GB/T 32918.2 produce s > n/2 about half the time, including in the
+// GM/T 0003.5 example, so strict verification is opt-in for protocols that
+// require it and agree on low-s signers. Unlike ECDSA, negating s does not
+// produce another valid SM2 signature, since s also enters the verification
+// equation through t = r + s; a signer that wants a low-s signature has to
+// retry with a fresh nonce.
+func VerifySM2Strict(pub *SM2PublicKey, id, msg, sig []byte) (bool, error) {
+	r, s, err := DecodeSignature(sig)
+	if err != nil {
+		return false, err
+	}
+	if s.Cmp(sm2HalfOrder()) > 0 {
+		return false, nil
+	}
+	e, err := SM2Digest(pub, id, msg)
+	if err != nil {
+		return false, err
+	}
+	return verifySM2Digest(pub, e[:], r, s), nil
+}
+
+// sm2HalfOrder returns floor(n/2) for the order n of sm2p256v1.
+func sm2HalfOrder() *big.Int {
+	return new(big.Int).Rsh(P256SM2().Params().N, 1)
+}
@@ -0,0 +1,315 @@
+package sm3
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func bigFromHex(t testing.TB, s string) *big.Int {
+	t.Helper()
+	v, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		t.Fatalf("bad hex integer %q", s)
+	}
+	return v
+}
+
+// sm2SignVector is the signature example on sm2p256v1 from GM/T 0003.5,
+// signing "message digest" with the default user ID.
+var sm2SignVector = struct {
+	d, x, y, k, r, s, msg string
+}{
+	d:   "3945208F7B2144B13F36E38AC6D39F95889393692860B51A42FB81EF4DF7C5B8",
+	x:   "09F9DF311E5421A150DD7D161E4BC5C672179FAD1833FC076BB08FF356F35020",
+	y:   "CCEA490CE26775A52DC6EA718CC1AA600AED05FBF35E084A6632F6072DA9AD13",
+	k:   "59276E27D506861A16680F3AD9C02DCCEF3CC1FA3CDBE4CE6D54B80DEAC1BC21",
+	r:   "F5A03B0648D2C4630EEAC513E1BB81A15944DA3827D5B74143AC7EACEEE720B3",
+	s:   "B1B6AA29DF212FD8763182BC0D421CA1BB9038FD1F7F42D4840B69C485BBC1AA",
+	msg: "message digest",
+}
+
+func sm2VectorKey(t testing.TB) *SM2PrivateKey {
+	t.Helper()
+	v := sm2SignVector
+	priv := sm2KeyFromScalar(bigFromHex(t, v.d))
+	if priv.X.Cmp(bigFromHex(t, v.x)) != 0 || priv.Y.Cmp(bigFromHex(t, v.y)) != 0 {
+		t.Fatal("public key of the example does not match its private key")
+	}
+	return priv
+}
+
+func sm2VectorSignature(t testing.TB) []byte {
+	t.Helper()
+	sig, err := EncodeSignature(bigFromHex(t, sm2SignVector.r), bigFromHex(t, sm2SignVector.s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sig
+}
+
+func TestVerifySM2Vector(t *testing.T) {
+	priv := sm2VectorKey(t)
+	sig := sm2VectorSignature(t)
+	msg := []byte(sm2SignVector.msg)
+
+	ok, err := VerifySM2(&priv.SM2PublicKey, DefaultSM2UID, msg, sig)
+	if err != nil || !ok {
+		t.Fatalf("VerifySM2 = %v, %v; want true, nil", ok, err)
+	}
+
+	flipped := bytes.Clone(msg)
+	flipped[0] ^= 0x01
+	if ok, err := VerifySM2(&priv.SM2PublicKey, DefaultSM2UID, flipped, sig); ok || err != nil {
+		t.Errorf("bit-flipped message: VerifySM2 = %v, %v; want false, nil", ok, err)
+	}
+	if ok, _ := VerifySM2(&priv.SM2PublicKey, []byte("someone else"), msg, sig); ok {
+		t.Error("signature verified under a different user ID")
+	}
+}
+
+func TestVerifySM2OutOfRange(t *testing.T) {
+	priv := sm2VectorKey(t)
+	msg := []byte(sm2SignVector.msg)
+	n := P256SM2().Params().N
+	r, s := bigFromHex(t, sm2SignVector.r), bigFromHex(t, sm2SignVector.s)
+
+	for _, tt := range []struct {
+		name string
+		r, s *big.Int
+	}{
+		{"r = 0", big.NewInt(0), s},
+		{"s = 0", r, big.NewInt(0)},
+		{"r = n", n, s},
+		{"s = n", r, n},
+		{"r + n", new(big.Int).Add(r, n), s},
+		{"negative s", new(big.Int).Neg(s), r},
+		{"r + s = n", r, new(big.Int).Sub(n, r)},
+	} {
+		sig, err := EncodeSignature(tt.r, tt.s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok, err := VerifySM2(&priv.SM2PublicKey, DefaultSM2UID, msg, sig); ok || err != nil {
+			t.Errorf("%s: VerifySM2 = %v, %v; want false, nil", tt.name, ok, err)
+		}
+	}
+}
+
+func TestVerifySM2Malformed(t *testing.T) {
+	priv := sm2VectorKey(t)
+	sig := sm2VectorSignature(t)
+	for _, bad := range [][]byte{
+		nil,
+		sig[:len(sig)-1],
+		append(bytes.Clone(sig), 0),
+		{0x30, 0x03, 0x02, 0x01, 0x01},
+	} {
+		ok, err := VerifySM2(&priv.SM2PublicKey, DefaultSM2UID, []byte(sm2SignVector.msg), bad)
+		if ok || !errors.Is(err, ErrMalformedSignature) {
+			t.Errorf("VerifySM2(%x) = %v, %v; want false, ErrMalformedSignature", bad, ok, err)
+		}
+	}
+}
+
+func TestSignatureEncoding(t *testing.T) {
+	r, s := big.NewInt(0x80), big.NewInt(1)
+	sig, err := EncodeSignature(r, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{0x30, 0x07, 0x02, 0x02, 0x00, 0x80, 0x02, 0x01, 0x01}; !bytes.Equal(sig, want) {
+		t.Errorf("EncodeSignature = %x, want %x", sig, want)
+	}
+	r2, s2, err := DecodeSignature(sig)
+	if err != nil || r2.Cmp(r) != 0 || s2.Cmp(s) != 0 {
+		t.Errorf("DecodeSignature = %v, %v, %v", r2, s2, err)
+	}
+}
+
+func TestRFC6979P256Vector(t *testing.T) {
+	// RFC 6979 appendix A.2.5: P-256, SHA-256, message "sample".
+	n := elliptic.P256().Params().N
+	x := bigFromHex(t, "C9AFA9D845BA75166B5C215767B1D6934E50C3DB36E89B127B8A622B120F6721")
+	h1 := sha256.Sum256([]byte("sample"))
+	k := rfc6979(sha256.New, n, x, h1[:])()
+	if want := bigFromHex(t, "A6E3C57DD01ABE90086538398355DD4C3B17AA873382B0F24D6129493D8AAD60"); k.Cmp(want) != 0 {
+		t.Errorf("k = %X, want %X", k, want)
+	}
+}
+
+func TestSignDeterministic(t *testing.T) {
+	priv := sm2VectorKey(t)
+	msg := []byte(sm2SignVector.msg)
+
+	sig1, err := priv.SignDeterministic(DefaultSM2UID, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig2, err := priv.SignDeterministic(DefaultSM2UID, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sig1, sig2) {
+		t.Error("same key and message produced different signatures")
+	}
+	if ok, err := VerifySM2(&priv.SM2PublicKey, DefaultSM2UID, msg, sig1); !ok || err != nil {
+		t.Errorf("VerifySM2 = %v, %v", ok, err)
+	}
+
+	sig3, err := priv.SignDeterministic(DefaultSM2UID, []byte("message digesT"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(sig1, sig3) {
+		t.Error("different messages produced the same signature")
+	}
+}
+
+func TestSignSM2DigestKnownNonce(t *testing.T) {
+	// With the nonce of the GM/T 0003.5 example the signer must reproduce
+	// the published signature.
+	priv := sm2VectorKey(t)
+	e, err := SM2Digest(&priv.SM2PublicKey, DefaultSM2UID, []byte(sm2SignVector.msg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	k := bigFromHex(t, sm2SignVector.k)
+	r, s, err := signSM2Digest(priv, e[:], func() (*big.Int, error) { return k, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Cmp(bigFromHex(t, sm2SignVector.r)) != 0 || s.Cmp(bigFromHex(t, sm2SignVector.s)) != 0 {
+		t.Errorf("signature = (%X, %X), want (%s, %s)", r, s, sm2SignVector.r, sm2SignVector.s)
+	}
+}
+
+func TestSignDigest(t *testing.T) {
+	priv := sm2VectorKey(t)
+	pub := &priv.SM2PublicKey
+	msg := []byte(sm2SignVector.msg)
+	e, err := SM2Digest(pub, DefaultSM2UID, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Feeding the example nonce as the randomness reproduces the published
+	// signature.
+	k := bigFromHex(t, sm2SignVector.k).FillBytes(make([]byte, 32))
+	sig, err := priv.SignDigest(bytes.NewReader(k), e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := sm2VectorSignature(t); !bytes.Equal(sig, want) {
+		t.Errorf("SignDigest with the example nonce = %x, want %x", sig, want)
+	}
+
+	// Signatures from either entry point verify against the message.
+	random, err := priv.SignDigest(rand.Reader, e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deterministic, err := priv.SignDeterministic(DefaultSM2UID, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, sig := range map[string][]byte{"SignDigest": random, "SignDeterministic": deterministic} {
+		if ok, err := VerifySM2(pub, DefaultSM2UID, msg, sig); !ok || err != nil {
+			t.Errorf("VerifySM2(%s) = %v, %v", name, ok, err)
+		}
+	}
+
+	if _, err := priv.SignDigest(bytes.NewReader(nil), e); err == nil {
+		t.Error("SignDigest with an empty random source succeeded")
+	}
+}
+
+func TestVerifySM2Strict(t *testing.T) {
+	priv := sm2VectorKey(t)
+	pub := &priv.SM2PublicKey
+	n := P256SM2().Params().N
+
+	// The GM/T 0003.5 example has s > n/2: it verifies, but not strictly.
+	msg := []byte(sm2SignVector.msg)
+	sig := sm2VectorSignature(t)
+	if ok, err := VerifySM2(pub, DefaultSM2UID, msg, sig); !ok || err != nil {
+		t.Fatalf("VerifySM2(example) = %v, %v", ok, err)
+	}
+	if ok, err := VerifySM2Strict(pub, DefaultSM2UID, msg, sig); ok || err != nil {
+		t.Errorf("VerifySM2Strict(high-s example) = %v, %v; want false, nil", ok, err)
+	}
+
+	// Find a deterministic signature that is low-s already.
+	var r, s *big.Int
+	for i := 0; ; i++ {
+		msg = []byte{byte(i)}
+		sig, err := priv.SignDeterministic(DefaultSM2UID, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r, s, _ = DecodeSignature(sig); s.Cmp(sm2HalfOrder()) <= 0 {
+			if ok, err := VerifySM2Strict(pub, DefaultSM2UID, msg, sig); !ok || err != nil {
+				t.Fatalf("VerifySM2Strict(low-s signature) = %v, %v", ok, err)
+			}
+			break
+		}
+	}
+
+	// The malleated (r, n-s) is high-s, so strict verification rejects it
+	// before the curve arithmetic; for SM2 it is not a valid signature at all.
+	malleated, err := EncodeSignature(r, new(big.Int).Sub(n, s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := VerifySM2Strict(pub, DefaultSM2UID, msg, malleated); ok || err != nil {
+		t.Errorf("VerifySM2Strict(r, n-s) = %v, %v; want false, nil", ok, err)
+	}
+	if ok, _ := VerifySM2(pub, DefaultSM2UID, msg, malleated); ok {
+		t.Error("VerifySM2 accepts (r, n-s)")
+	}
+
+	if _, err := VerifySM2Strict(pub, DefaultSM2UID, msg, []byte{0x30, 0x00}); !errors.Is(err, ErrMalformedSignature) {
+		t.Errorf("malformed signature: err = %v, want ErrMalformedSignature", err)
+	}
+}
+
+func BenchmarkVerifySM2(b *testing.B) {
+	priv := sm2VectorKey(b)
+	msg, sig := []byte(sm2SignVector.msg), sm2VectorSignature(b)
+	for i := 0; i < b.N; i++ {
+		if ok, _ := VerifySM2(&priv.SM2PublicKey, DefaultSM2UID, msg, sig); !ok {
+			b.Fatal("signature rejected")
+		}
+	}
+}
+
+func TestSM2PrivateKeySign(t *testing.T) {
+	priv := sm2VectorKey(t)
+	var signer crypto.Signer = priv
+	msg := []byte("TLS 1.3, server CertificateVerify")
+	id := []byte("TLSv1.3+GM+Cipher+Suite")
+	for _, tt := range []struct {
+		opts crypto.SignerOpts
+		id   []byte
+	}{
+		{crypto.Hash(0), DefaultSM2UID},
+		{&SM2SignerOpts{}, DefaultSM2UID},
+		{&SM2SignerOpts{UID: id}, id},
+	} {
+		sig, err := signer.Sign(rand.Reader, msg, tt.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok, err := VerifySM2(&priv.SM2PublicKey, tt.id, msg, sig); !ok || err != nil {
+			t.Errorf("opts %#v: VerifySM2 under %q = %v, %v", tt.opts, tt.id, ok, err)
+		}
+	}
+	if _, err := signer.Sign(rand.Reader, msg, crypto.SHA256); !errors.Is(err, ErrUnsupportedAlgorithm) {
+		t.Errorf("SHA-256 opts: err = %v, want ErrUnsupportedAlgorithm", err)
+	}
+}
@@ -0,0 +1,38 @@
+package sm3
+
+import "io"
+
+// SM2Signer signs many messages with one SM2 key and identity. ZA depends
+// only on the two, so it is computed once in NewSM2Signer and each
+// signature hashes just the message onto the cached SM3 state.
+type SM2Signer struct {
+	priv *SM2PrivateKey
+	za   digest // state after absorbing ZA
+}
+
+// NewSM2Signer returns an SM2Signer for priv under identity id. It fails
+// only if id is too long for ZA.
+func NewSM2Signer(priv *SM2PrivateKey, id []byte) (*SM2Signer, error) {
+	za, err := ZA(&priv.SM2PublicKey, id)
+	if err != nil {
+		return nil, err
+	}
+	s := &SM2Signer{priv: priv}
+	s.za.Reset()
+	s.za.Write(za[:])
+	return s, nil
+}
+
+// Digest returns e = SM3(ZA || msg), as SM2Digest does for the signer's
+// key and identity.
+func (s *SM2Signer) Digest(msg []byte) [Size]byte {
+	d := s.za
+	d.Write(msg)
+	return d.checkSum()
+}
+
+// Sign returns a DER-encoded signature of msg, drawing the nonce from rand
+// as SignDigest does. It is safe for concurrent use.
+func (s *SM2Signer) Sign(rand io.Reader, msg []byte) ([]byte, error) {
+	return s.priv.SignDigest(rand, s.Digest(msg))
+}
@@ -0,0 +1,47 @@
+package sm3
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestSM2Signer(t *testing.T) {
+	priv := sm2VectorKey(t)
+	pub := &priv.SM2PublicKey
+	id := []byte("ALICE123@YAHOO.COM")
+	signer, err := NewSM2Signer(priv, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonces := treeTestData(1 << 10)
+	for _, msg := range [][]byte{nil, []byte("message digest"), treeTestData(3 * BlockSize)} {
+		e, err := SM2Digest(pub, id, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := signer.Digest(msg); got != e {
+			t.Errorf("Digest(%q) = %x, want %x", msg, got, e)
+		}
+
+		// The same randomness gives the same signature on both paths.
+		cached, err := signer.Sign(bytes.NewReader(nonces), msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stateless, err := priv.SignDigest(bytes.NewReader(nonces), e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(cached, stateless) {
+			t.Errorf("Sign(%q) = %x, want %x", msg, cached, stateless)
+		}
+		if ok, err := VerifySM2(pub, id, msg, cached); !ok || err != nil {
+			t.Errorf("VerifySM2(Sign(%q)) = %v, %v", msg, ok, err)
+		}
+	}
+
+	if _, err := NewSM2Signer(priv, make([]byte, 1<<13)); !errors.Is(err, ErrBadLength) {
+		t.Errorf("NewSM2Signer with an 8 KiB ID: err = %v, want %v", err, ErrBadLength)
+	}
+}
@@ -0,0 +1,172 @@
+package sm3
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"io"
+)
+
+// sm2StreamChunk is the read size of EncryptStream and DecryptStream.
+const sm2StreamChunk = 32 << 10
+
+// sm2PointLen is the length of an uncompressed sm2p256v1 point.
+const sm2PointLen = 1 + 2*32
+
+// EncryptStream encrypts everything read from r to pub with SM2 public key
+// encryption (GB/T 32918.4) and writes the ciphertext to w as it goes,
+// without holding the plaintext in memory. The ephemeral key is drawn from
+// rand.
+//
+// The check value C3 = SM3(x2 || M || y2) is only known once the whole
+// plaintext has been read, so the stream is laid out C1 || C2 || C3, the
+// order of the 2010 draft of the standard, rather than the C1 || C3 || C2
+// of EncryptSM2 and EncodeSM2CipherRaw. C1 is an uncompressed point. Read
+// it back with DecryptStream.
+//
+// As in EncryptSM2, an all-zero mask over the first chunk makes
+// EncryptStream retry with a new ephemeral key before anything is written,
+// and an empty plaintext is rejected with ErrBadLength.
+func (pub *SM2PublicKey) EncryptStream(rand io.Reader, r io.Reader, w io.Writer) error {
+	c := P256SM2()
+	if pub == nil || pub.X == nil || pub.Y == nil || !c.IsOnCurve(pub.X, pub.Y) {
+		return ErrInvalidPublicKey
+	}
+	buf := make([]byte, sm2StreamChunk)
+	n, err := io.ReadFull(r, buf)
+	eof := err == io.EOF || err == io.ErrUnexpectedEOF
+	if err != nil && !eof {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: empty SM2 plaintext", ErrBadLength)
+	}
+
+	var (
+		c1   []byte
+		xy   []byte
+		mask io.Reader
+		t    = make([]byte, sm2StreamChunk)
+	)
+	for i := 0; ; i++ {
+		if i == sm2EncryptAttempts {
+			return fmt.Errorf("%w after %d ephemeral keys", ErrKDFFailure, sm2EncryptAttempts)
+		}
+		k, err := randScalar(c, rand)
+		if err != nil {
+			return fmt.Errorf("sm3: reading SM2 encryption randomness: %w", err)
+		}
+		c1x, c1y := c.ScalarBaseMult(k.Bytes())
+		c1 = MarshalPoint(c1x, c1y, false)
+		x2, y2 := c.ScalarMult(pub.X, pub.Y, k.Bytes())
+		xy = append(x2.FillBytes(make([]byte, 32)), y2.FillBytes(make([]byte, 32))...)
+		mask = NewKDFReader(xy)
+		if _, err := io.ReadFull(mask, t[:n]); err != nil {
+			return err
+		}
+		if !allZero(t[:n]) {
+			break
+		}
+	}
+	if _, err := w.Write(c1); err != nil {
+		return err
+	}
+
+	var d digest
+	d.Reset()
+	d.Write(xy[:32])
+	for {
+		d.Write(buf[:n])
+		subtle.XORBytes(buf[:n], buf[:n], t[:n])
+		if _, err := w.Write(buf[:n]); err != nil {
+			return err
+		}
+		if eof {
+			break
+		}
+		n, err = io.ReadFull(r, buf)
+		eof = err == io.EOF || err == io.ErrUnexpectedEOF
+		if err != nil && !eof {
+			return err
+		}
+		if _, err := io.ReadFull(mask, t[:n]); err != nil {
+			return err
+		}
+	}
+	d.Write(xy[32:])
+	c3 := d.checkSum()
+	_, err = w.Write(c3[:])
+	return err
+}
+
+// DecryptStream decrypts a C1 || C2 || C3 ciphertext written by
+// EncryptStream, reading it from r and writing the plaintext to w as it
+// goes.
+//
+// The plaintext is written before C3 has been checked, since C3 comes
+// last. It must be treated as unauthenticated until DecryptStream returns
+// nil, and discarded if it returns ErrAuth. Truncated or malformed input,
+// or a C1 not on the curve, gives an error wrapping ErrMalformedCiphertext,
+// and an all-zero mask ErrKDFFailure.
+func (priv *SM2PrivateKey) DecryptStream(r io.Reader, w io.Writer) error {
+	var c1 [sm2PointLen]byte
+	if _, err := io.ReadFull(r, c1[:]); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return fmt.Errorf("%w: truncated C1", ErrMalformedCiphertext)
+		}
+		return err
+	}
+	c1x, c1y, err := UnmarshalPoint(c1[:])
+	if err != nil {
+		return fmt.Errorf("%w: C1 is not a point on the curve", ErrMalformedCiphertext)
+	}
+	c := P256SM2()
+	x2, y2 := c.ScalarMult(c1x, c1y, priv.D.Bytes())
+	xy := append(x2.FillBytes(make([]byte, 32)), y2.FillBytes(make([]byte, 32))...)
+	mask := NewKDFReader(xy)
+
+	var d digest
+	d.Reset()
+	d.Write(xy[:32])
+	// The last Size bytes read so far may be C3, so they are held back.
+	buf := make([]byte, Size+sm2StreamChunk)
+	t := make([]byte, sm2StreamChunk)
+	held, total := 0, 0
+	var nonzero byte
+	for {
+		n, err := io.ReadFull(r, buf[held:])
+		held += n
+		eof := err == io.EOF || err == io.ErrUnexpectedEOF
+		if err != nil && !eof {
+			return err
+		}
+		if m := held - Size; m > 0 {
+			if _, err := io.ReadFull(mask, t[:m]); err != nil {
+				return err
+			}
+			for _, b := range t[:m] {
+				nonzero |= b
+			}
+			subtle.XORBytes(buf[:m], buf[:m], t[:m])
+			d.Write(buf[:m])
+			if _, err := w.Write(buf[:m]); err != nil {
+				return err
+			}
+			total += m
+			held = copy(buf, buf[m:held])
+		}
+		if eof {
+			break
+		}
+	}
+	if held < Size || total == 0 {
+		return fmt.Errorf("%w: truncated ciphertext", ErrMalformedCiphertext)
+	}
+	if nonzero == 0 {
+		return ErrKDFFailure
+	}
+	d.Write(xy[32:])
+	if u := d.checkSum(); subtle.ConstantTimeCompare(u[:], buf[:Size]) != 1 {
+		return ErrAuth
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package sm3
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"testing"
+)
+
+func TestSM2EncryptStream(t *testing.T) {
+	priv, err := GenerateSM2Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sizes := []int{1, Size, sm2StreamChunk, sm2StreamChunk + Size + 1}
+	if !testing.Short() {
+		sizes = append(sizes, 3<<20+17)
+	}
+	for _, size := range sizes {
+		msg := treeTestData(size)
+		var ct bytes.Buffer
+		if err := priv.EncryptStream(rand.Reader, bytes.NewReader(msg), &ct); err != nil {
+			t.Fatalf("%d bytes: EncryptStream = %v", size, err)
+		}
+		if ct.Len() != sm2PointLen+size+Size {
+			t.Fatalf("%d bytes: ciphertext of %d bytes, want %d", size, ct.Len(), sm2PointLen+size+Size)
+		}
+		var pt bytes.Buffer
+		if err := priv.DecryptStream(bytes.NewReader(ct.Bytes()), &pt); err != nil {
+			t.Fatalf("%d bytes: DecryptStream = %v", size, err)
+		}
+		if !bytes.Equal(pt.Bytes(), msg) {
+			t.Fatalf("%d bytes: round trip differs", size)
+		}
+	}
+}
+
+func TestSM2EncryptStreamMatchesEncryptSM2(t *testing.T) {
+	// Reordered to C1 || C3 || C2, a stream ciphertext is an ordinary SM2
+	// ciphertext that DecryptSM2 accepts.
+	priv, err := GenerateSM2Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("encryption standard")
+	var ct bytes.Buffer
+	if err := priv.EncryptStream(rand.Reader, bytes.NewReader(msg), &ct); err != nil {
+		t.Fatal(err)
+	}
+	raw := ct.Bytes()
+	c1, c2, c3 := raw[:sm2PointLen], raw[sm2PointLen:len(raw)-Size], raw[len(raw)-Size:]
+	c1x, c1y, _, _, err := DecodeSM2CipherRaw(bytes.Join([][]byte{c1, c3, c2}, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := EncodeSM2Cipher(c1x, c1y, c3, c2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := DecryptSM2(priv, der); err != nil || !bytes.Equal(got, msg) {
+		t.Errorf("DecryptSM2 = %q, %v, want %q", got, err, msg)
+	}
+}
+
+func TestSM2DecryptStreamRejects(t *testing.T) {
+	priv, err := GenerateSM2Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := treeTestData(sm2StreamChunk + 100)
+	var ct bytes.Buffer
+	if err := priv.EncryptStream(rand.Reader, bytes.NewReader(msg), &ct); err != nil {
+		t.Fatal(err)
+	}
+	good := ct.Bytes()
+	flip := func(i int) []byte {
+		b := bytes.Clone(good)
+		b[i] ^= 1
+		return b
+	}
+
+	for _, tt := range []struct {
+		name string
+		ct   []byte
+		want error
+	}{
+		{"corrupted C3", flip(len(good) - 1), ErrAuth},
+		{"corrupted C2", flip(sm2PointLen + 5), ErrAuth},
+		{"truncated", good[:len(good)-1], ErrAuth},
+		{"no C2", good[:sm2PointLen+Size], ErrMalformedCiphertext},
+		{"short", good[:sm2PointLen+Size-1], ErrMalformedCiphertext},
+		{"no C1", good[:10], ErrMalformedCiphertext},
+		{"C1 off the curve", flip(sm2PointLen - 1), ErrMalformedCiphertext},
+	} {
+		if err := priv.DecryptStream(bytes.NewReader(tt.ct), new(bytes.Buffer)); !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	if err := priv.EncryptStream(rand.Reader, bytes.NewReader(nil), new(bytes.Buffer)); !errors.Is(err, ErrBadLength) {
+		t.Errorf("empty plaintext: err = %v, want %v", err, ErrBadLength)
+	}
+}
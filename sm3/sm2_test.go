@@ -0,0 +1,159 @@
+package sm3
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestGenerateSM2KeyOnCurve(t *testing.T) {
+	c := P256SM2()
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 8; i++ {
+		priv, err := GenerateSM2Key(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !c.IsOnCurve(priv.X, priv.Y) {
+			t.Fatalf("public key %d is not on the curve", i)
+		}
+		// Check y² = x³ + ax + b with a = p-3 directly, independently of
+		// the CurveParams implementation.
+		p := c.Params().P
+		a := new(big.Int).Sub(p, big.NewInt(3))
+		lhs := new(big.Int).Mul(priv.Y, priv.Y)
+		lhs.Mod(lhs, p)
+		rhs := new(big.Int).Exp(priv.X, big.NewInt(3), p)
+		rhs.Add(rhs, new(big.Int).Mul(a, priv.X))
+		rhs.Add(rhs, c.Params().B)
+		rhs.Mod(rhs, p)
+		if lhs.Cmp(rhs) != 0 {
+			t.Fatalf("public key %d does not satisfy the curve equation", i)
+		}
+		if priv.D.Sign() <= 0 || priv.D.Cmp(c.Params().N) >= 0 {
+			t.Fatalf("private scalar %d out of range", i)
+		}
+	}
+}
+
+func TestGenerateSM2KeyDeterministic(t *testing.T) {
+	k1, err := GenerateSM2Key(rand.New(rand.NewSource(42)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	k2, err := GenerateSM2Key(rand.New(rand.NewSource(42)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k1.D.Cmp(k2.D) != 0 || k1.X.Cmp(k2.X) != 0 || k1.Y.Cmp(k2.Y) != 0 {
+		t.Error("same rand stream produced different keys")
+	}
+}
+
+func TestGenerateSM2KeyRejectsOutOfRange(t *testing.T) {
+	n := P256SM2().Params().N
+	nMinus1 := new(big.Int).Sub(n, big.NewInt(1))
+	var stream []byte
+	stream = append(stream, make([]byte, 32)...) // zero
+	stream = append(stream, n.Bytes()...)        // n
+	stream = append(stream, nMinus1.Bytes()...)  // n-1
+	stream = append(stream, bytes.Repeat([]byte{0xff}, 32)...)
+	want := make([]byte, 32)
+	want[31] = 7
+	stream = append(stream, want...)
+
+	priv, err := GenerateSM2Key(bytes.NewReader(stream))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if priv.D.Cmp(big.NewInt(7)) != 0 {
+		t.Errorf("D = %v, want 7", priv.D)
+	}
+
+	if _, err := GenerateSM2Key(bytes.NewReader(make([]byte, 40))); err == nil {
+		t.Error("expected an error from an exhausted rand reader")
+	}
+}
+
+func TestSM2ConstantRandFails(t *testing.T) {
+	// An endless all-zero reader never yields a scalar in [1, n-2]; every
+	// user of randScalar must fail rather than spin.
+	zeros := zeroReader{}
+	if _, err := GenerateSM2Key(zeros); !errors.Is(err, ErrNoValidScalar) {
+		t.Errorf("GenerateSM2Key: err = %v, want %v", err, ErrNoValidScalar)
+	}
+	priv, err := GenerateSM2Key(rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := priv.SignDigest(zeros, Sum([]byte("msg"))); !errors.Is(err, ErrNoValidScalar) {
+		t.Errorf("SignDigest: err = %v, want %v", err, ErrNoValidScalar)
+	}
+	if _, err := priv.Sign(zeros, []byte("msg"), &SM2SignerOpts{}); !errors.Is(err, ErrNoValidScalar) {
+		t.Errorf("Sign: err = %v, want %v", err, ErrNoValidScalar)
+	}
+	if _, err := EncryptSM2(zeros, &priv.SM2PublicKey, []byte("msg")); !errors.Is(err, ErrNoValidScalar) {
+		t.Errorf("EncryptSM2: err = %v, want %v", err, ErrNoValidScalar)
+	}
+	if err := priv.EncryptStream(zeros, bytes.NewReader([]byte("msg")), io.Discard); !errors.Is(err, ErrNoValidScalar) {
+		t.Errorf("EncryptStream: err = %v, want %v", err, ErrNoValidScalar)
+	}
+}
+
+func TestSM2CurveParams(t *testing.T) {
+	if SM2Curve != P256SM2() {
+		t.Fatal("SM2Curve is not the P256SM2 curve")
+	}
+	params := SM2Curve.Params()
+	if params.Name != "sm2p256v1" || params.BitSize != 256 {
+		t.Errorf("name, bit size = %q, %d", params.Name, params.BitSize)
+	}
+
+	// p = 2^256 - 2^224 - 2^96 + 2^64 - 1, and it is prime.
+	p := new(big.Int).Lsh(big.NewInt(1), 256)
+	p.Sub(p, new(big.Int).Lsh(big.NewInt(1), 224))
+	p.Sub(p, new(big.Int).Lsh(big.NewInt(1), 96))
+	p.Add(p, new(big.Int).Lsh(big.NewInt(1), 64))
+	p.Sub(p, big.NewInt(1))
+	if params.P.Cmp(p) != 0 {
+		t.Errorf("p = %X, want %X", params.P, p)
+	}
+	if !params.P.ProbablyPrime(32) {
+		t.Error("p is not prime")
+	}
+	if a := bigFromHex(t, "FFFFFFFEFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF00000000FFFFFFFFFFFFFFFC"); SM2CurveA().Cmp(a) != 0 {
+		t.Errorf("a = %X, want %X", SM2CurveA(), a)
+	}
+
+	// The base point satisfies y² = x³ + ax + b, checked directly.
+	lhs := new(big.Int).Exp(params.Gy, big.NewInt(2), p)
+	rhs := new(big.Int).Exp(params.Gx, big.NewInt(3), p)
+	rhs.Add(rhs, new(big.Int).Mul(SM2CurveA(), params.Gx))
+	rhs.Add(rhs, params.B)
+	rhs.Mod(rhs, p)
+	if lhs.Cmp(rhs) != 0 {
+		t.Error("base point is not on the curve")
+	}
+
+	// n is prime and [n]G is the point at infinity, so G has order n. By
+	// the Hasse bound |#E - (p+1)| <= 2√p, n is the whole group: cofactor 1.
+	n := params.N
+	if !n.ProbablyPrime(32) {
+		t.Error("n is not prime")
+	}
+	if x, y := SM2Curve.ScalarBaseMult(n.Bytes()); x.Sign() != 0 || y.Sign() != 0 {
+		t.Error("[n]G is not the point at infinity")
+	}
+	nMinus1 := new(big.Int).Sub(n, big.NewInt(1))
+	if x, y := SM2Curve.ScalarBaseMult(nMinus1.Bytes()); x.Cmp(params.Gx) != 0 || y.Cmp(new(big.Int).Sub(p, params.Gy)) != 0 {
+		t.Error("[n-1]G is not -G")
+	}
+	diff := new(big.Int).Sub(new(big.Int).Add(p, big.NewInt(1)), n)
+	bound := new(big.Int).Lsh(new(big.Int).Sqrt(p), 1)
+	if diff.Abs(diff).Cmp(new(big.Int).Add(bound, big.NewInt(1))) > 0 {
+		t.Error("n is outside the Hasse bound for a cofactor of 1")
+	}
+}
@@ -0,0 +1,271 @@
+package sm3
+
+import (
+	"math/big"
+	"sync"
+)
+
+// SM2Verifier checks many signatures from one SM2 key and identity. It
+// caches the SM3 state after ZA, as SM2Signer does, and tables of the
+// multiples of the public key and the base point used by the verification
+// equation, so that [s]G + [t]PA takes 128 point additions and no
+// doublings instead of two full scalar multiplications.
+//
+// Building the public-key table costs about as much as a few verifications
+// and it holds about 150 KB, so an SM2Verifier pays off for a key that
+// checks more than a handful of signatures. Its decisions are those of
+// VerifySM2. It is safe for concurrent use.
+type SM2Verifier struct {
+	za    digest // state after absorbing ZA
+	table *sm2Table
+}
+
+// NewSM2Verifier returns an SM2Verifier for pub under identity id. It
+// fails if pub is not a point on sm2p256v1, with ErrInvalidPublicKey, or
+// if id is too long for ZA.
+func NewSM2Verifier(pub *SM2PublicKey, id []byte) (*SM2Verifier, error) {
+	if pub == nil || pub.X == nil || pub.Y == nil || !P256SM2().IsOnCurve(pub.X, pub.Y) {
+		return nil, ErrInvalidPublicKey
+	}
+	za, err := ZA(pub, id)
+	if err != nil {
+		return nil, err
+	}
+	v := &SM2Verifier{table: newSM2Table(pub.X, pub.Y)}
+	v.za.Reset()
+	v.za.Write(za[:])
+	return v, nil
+}
+
+// Verify reports whether sig is a valid DER-encoded signature of msg, as
+// VerifySM2 does for the verifier's key and identity. The error is non-nil
+// only if sig is not well-formed DER.
+func (v *SM2Verifier) Verify(msg, sig []byte) (bool, error) {
+	r, s, err := DecodeSignature(sig)
+	if err != nil {
+		return false, err
+	}
+	d := v.za
+	d.Write(msg)
+	e := d.checkSum()
+
+	n := P256SM2().Params().N
+	if r.Sign() <= 0 || s.Sign() <= 0 || r.Cmp(n) >= 0 || s.Cmp(n) >= 0 {
+		return false, nil
+	}
+	t := new(big.Int).Add(r, s)
+	t.Mod(t, n)
+	if t.Sign() == 0 {
+		return false, nil
+	}
+
+	// R = (e + x1) mod n, for (x1, y1) = [s]G + [t]PA
+	x1 := sm2CombinedMult(sm2BaseTable(), v.table, s, t)
+	x1.Add(x1, new(big.Int).SetBytes(e[:]))
+	x1.Mod(x1, n)
+	return x1.Cmp(r) == 0, nil
+}
+
+// sm2Window is the width in bits of the digits sm2Table is indexed by.
+const sm2Window = 4
+
+// An sm2Table holds, in affine coordinates, j * 2^(4i) * P for every
+// 4-bit digit j from 1 to 15 and window i from 0 to 63, so that [k]P is
+// the sum of one entry per nonzero digit of k.
+type sm2Table [256 / sm2Window][1<<sm2Window - 1]struct{ x, y *big.Int }
+
+var (
+	sm2BaseTableOnce sync.Once
+	sm2BaseTableVal  *sm2Table
+)
+
+// sm2BaseTable returns the sm2Table of the base point G, built on first
+// use and shared by every SM2Verifier.
+func sm2BaseTable() *sm2Table {
+	sm2BaseTableOnce.Do(func() {
+		params := P256SM2().Params()
+		sm2BaseTableVal = newSM2Table(params.Gx, params.Gy)
+	})
+	return sm2BaseTableVal
+}
+
+// newSM2Table builds the table of the curve point (x, y), which must not be
+// the point at infinity. None of its entries is: each is a multiple of P
+// by less than 2^256/16 * 15, which is below the group order.
+func newSM2Table(x, y *big.Int) *sm2Table {
+	t := new(sm2Table)
+	var acc sm2Jacobian
+	bx, by := new(big.Int).Set(x), new(big.Int).Set(y)
+	for i := range t {
+		acc.set(bx, by)
+		t[i][0].x, t[i][0].y = bx, by
+		for j := 1; j < len(t[i]); j++ {
+			acc.addAffine(bx, by)
+			t[i][j].x, t[i][j].y = acc.affine()
+		}
+		// 16 * 2^(4i) * P, the base of the next window.
+		acc.addAffine(bx, by)
+		bx, by = acc.affine()
+	}
+	return t
+}
+
+// sm2CombinedMult returns the x coordinate of [s]G + [t]P, where g and p
+// are the tables of G and P and s and t are in [0, n). The point at
+// infinity has x coordinate 0, as for elliptic.CurveParams.
+func sm2CombinedMult(g, p *sm2Table, s, t *big.Int) *big.Int {
+	var sb, tb [32]byte
+	s.FillBytes(sb[:])
+	t.FillBytes(tb[:])
+	var acc sm2Jacobian
+	acc.setInfinity()
+	for i := range g {
+		// Window i is nibble i counting from the least significant.
+		b := 31 - i/2
+		shift := uint(i%2) * sm2Window
+		if j := sb[b] >> shift & 0xf; j != 0 {
+			acc.addAffine(g[i][j-1].x, g[i][j-1].y)
+		}
+		if j := tb[b] >> shift & 0xf; j != 0 {
+			acc.addAffine(p[i][j-1].x, p[i][j-1].y)
+		}
+	}
+	if acc.z.Sign() == 0 {
+		return new(big.Int)
+	}
+	x, _ := acc.affine()
+	return x
+}
+
+// sm2Jacobian is a point on sm2p256v1 in Jacobian coordinates, (x/z², y/z³),
+// with z = 0 for the point at infinity.
+type sm2Jacobian struct {
+	x, y, z big.Int
+}
+
+func (q *sm2Jacobian) set(x, y *big.Int) {
+	q.x.Set(x)
+	q.y.Set(y)
+	q.z.SetInt64(1)
+}
+
+func (q *sm2Jacobian) setInfinity() {
+	q.x.SetInt64(1)
+	q.y.SetInt64(1)
+	q.z.SetInt64(0)
+}
+
+// affine returns q in affine coordinates. q must not be the point at
+// infinity.
+func (q *sm2Jacobian) affine() (x, y *big.Int) {
+	p := P256SM2().Params().P
+	zinv := new(big.Int).ModInverse(&q.z, p)
+	zinv2 := new(big.Int).Mul(zinv, zinv)
+	zinv2.Mod(zinv2, p)
+	x = new(big.Int).Mul(&q.x, zinv2)
+	x.Mod(x, p)
+	zinv2.Mul(zinv2, zinv)
+	y = zinv2.Mul(zinv2, &q.y)
+	y.Mod(y, p)
+	return x, y
+}
+
+// addAffine sets q to q + (x2, y2), using the madd-2007-bl formulas and
+// falling back to doubling when the two points are equal.
+func (q *sm2Jacobian) addAffine(x2, y2 *big.Int) {
+	if q.z.Sign() == 0 {
+		q.set(x2, y2)
+		return
+	}
+	p := P256SM2().Params().P
+	z1z1 := new(big.Int).Mul(&q.z, &q.z)
+	z1z1.Mod(z1z1, p)
+	u2 := new(big.Int).Mul(x2, z1z1)
+	u2.Mod(u2, p)
+	s2 := new(big.Int).Mul(y2, &q.z)
+	s2.Mul(s2, z1z1)
+	s2.Mod(s2, p)
+
+	h := u2.Sub(u2, &q.x) // u2 is not needed again
+	h.Mod(h, p)
+	r := s2.Sub(s2, &q.y)
+	r.Lsh(r, 1)
+	r.Mod(r, p)
+	if h.Sign() == 0 {
+		if r.Sign() == 0 {
+			q.double()
+		} else {
+			q.setInfinity()
+		}
+		return
+	}
+
+	hh := new(big.Int).Mul(h, h)
+	hh.Mod(hh, p)
+	i := new(big.Int).Lsh(hh, 2)
+	j := new(big.Int).Mul(h, i)
+	j.Mod(j, p)
+	v := i.Mul(&q.x, i)
+	v.Mod(v, p)
+
+	// z3 = (z1 + h)² - z1z1 - hh
+	q.z.Add(&q.z, h)
+	q.z.Mul(&q.z, &q.z)
+	q.z.Sub(&q.z, z1z1)
+	q.z.Sub(&q.z, hh)
+	q.z.Mod(&q.z, p)
+
+	// x3 = r² - j - 2v
+	x3 := new(big.Int).Mul(r, r)
+	x3.Sub(x3, j)
+	x3.Sub(x3, v)
+	x3.Sub(x3, v)
+	x3.Mod(x3, p)
+
+	// y3 = r (v - x3) - 2 y1 j
+	y3 := v.Sub(v, x3)
+	y3.Mul(y3, r)
+	j.Mul(j, &q.y)
+	y3.Sub(y3, j.Lsh(j, 1))
+	y3.Mod(y3, p)
+
+	q.x.Set(x3)
+	q.y.Set(y3)
+}
+
+// double sets q to 2q, using the dbl-2001-b formulas for a = -3.
+func (q *sm2Jacobian) double() {
+	p := P256SM2().Params().P
+	delta := new(big.Int).Mul(&q.z, &q.z)
+	delta.Mod(delta, p)
+	gamma := new(big.Int).Mul(&q.y, &q.y)
+	gamma.Mod(gamma, p)
+	beta := new(big.Int).Mul(&q.x, gamma)
+	beta.Mod(beta, p)
+	alpha := new(big.Int).Sub(&q.x, delta)
+	t := new(big.Int).Add(&q.x, delta)
+	alpha.Mul(alpha, t)
+	alpha.Mul(alpha, big.NewInt(3))
+	alpha.Mod(alpha, p)
+
+	// z3 = (y1 + z1)² - gamma - delta
+	q.z.Add(&q.y, &q.z)
+	q.z.Mul(&q.z, &q.z)
+	q.z.Sub(&q.z, gamma)
+	q.z.Sub(&q.z, delta)
+	q.z.Mod(&q.z, p)
+
+	// x3 = alpha² - 8 beta
+	beta.Lsh(beta, 2) // 4 beta from here on
+	q.x.Mul(alpha, alpha)
+	q.x.Sub(&q.x, t.Lsh(beta, 1))
+	q.x.Mod(&q.x, p)
+
+	// y3 = alpha (4 beta - x3) - 8 gamma²
+	beta.Sub(beta, &q.x)
+	q.y.Mul(alpha, beta)
+	gamma.Mul(gamma, gamma)
+	gamma.Lsh(gamma, 3)
+	q.y.Sub(&q.y, gamma)
+	q.y.Mod(&q.y, p)
+}
@@ -0,0 +1,146 @@
+package sm3
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+// TestSM2VerifierAgrees checks that SM2Verifier accepts and rejects
+// exactly the signatures VerifySM2 does, over valid signatures, tampered
+// ones, edge-case scalars and malformed encodings.
+func TestSM2VerifierAgrees(t *testing.T) {
+	priv := sm2VectorKey(t)
+	pub := &priv.SM2PublicKey
+	n := P256SM2().Params().N
+	v, err := NewSM2Verifier(pub, DefaultSM2UID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type item struct {
+		msg, sig []byte
+	}
+	var items []item
+	sign := func(msg []byte) []byte {
+		e, err := SM2Digest(pub, DefaultSM2UID, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := priv.SignDigest(rand.Reader, e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sig
+	}
+	enc := func(r, s *big.Int) []byte {
+		sig, err := EncodeSignature(r, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sig
+	}
+	items = append(items, item{[]byte(sm2SignVector.msg), sm2VectorSignature(t)})
+	for i := 0; i < 20; i++ {
+		msg := treeTestData(i * 13)
+		sig := sign(msg)
+		items = append(items, item{msg, sig})
+		items = append(items, item{append(bytes.Clone(msg), 1), sig})
+		r, s, _ := DecodeSignature(sig)
+		items = append(items,
+			item{msg, enc(new(big.Int).Add(r, big.NewInt(1)), s)},
+			item{msg, enc(r, new(big.Int).Sub(s, big.NewInt(1)))},
+			item{msg, enc(r, new(big.Int).Sub(n, r))}, // t = 0
+		)
+	}
+	one := big.NewInt(1)
+	nMinus1 := new(big.Int).Sub(n, one)
+	for _, rs := range [][2]*big.Int{
+		{one, one}, {nMinus1, nMinus1}, {one, nMinus1}, {n, one}, {one, n}, {new(big.Int), one},
+	} {
+		items = append(items, item{[]byte("edge"), enc(rs[0], rs[1])})
+	}
+	items = append(items, item{[]byte("x"), []byte{0x30, 0x00}}, item{[]byte("x"), nil})
+
+	accepted := 0
+	for i, it := range items {
+		want, wantErr := VerifySM2(pub, DefaultSM2UID, it.msg, it.sig)
+		got, gotErr := v.Verify(it.msg, it.sig)
+		if got != want || (gotErr == nil) != (wantErr == nil) {
+			t.Errorf("item %d: Verify = %v, %v; VerifySM2 = %v, %v", i, got, gotErr, want, wantErr)
+		}
+		if want {
+			accepted++
+		}
+	}
+	if accepted != 21 {
+		t.Errorf("%d signatures accepted, want 21", accepted)
+	}
+
+	if _, err := NewSM2Verifier(&SM2PublicKey{X: big.NewInt(1), Y: big.NewInt(1)}, nil); !errors.Is(err, ErrInvalidPublicKey) {
+		t.Errorf("off-curve key: err = %v, want ErrInvalidPublicKey", err)
+	}
+	if _, err := NewSM2Verifier(pub, make([]byte, 1<<13)); !errors.Is(err, ErrBadLength) {
+		t.Errorf("long ID: err = %v, want ErrBadLength", err)
+	}
+}
+
+// TestSM2CombinedMult checks the table arithmetic against
+// elliptic.CurveParams, including sums that hit the doubling and
+// point-at-infinity cases of the addition formulas.
+func TestSM2CombinedMult(t *testing.T) {
+	c := P256SM2()
+	n := c.Params().N
+	g := sm2BaseTable()
+	check := func(p *sm2Table, px, py, s, u *big.Int) {
+		t.Helper()
+		x1, y1 := c.ScalarBaseMult(s.Bytes())
+		x2, y2 := c.ScalarMult(px, py, u.Bytes())
+		want, _ := c.Add(x1, y1, x2, y2)
+		if got := sm2CombinedMult(g, p, s, u); got.Cmp(want) != 0 {
+			t.Errorf("s=%x, t=%x: x = %x, want %x", s, u, got, want)
+		}
+	}
+
+	priv := sm2VectorKey(t)
+	p := newSM2Table(priv.X, priv.Y)
+	for i := 0; i < 20; i++ {
+		s, _ := rand.Int(rand.Reader, n)
+		u, _ := rand.Int(rand.Reader, n)
+		check(p, priv.X, priv.Y, s, u)
+	}
+	for _, k := range []int64{0, 1, 2, 15, 16, 17, 255, 256} {
+		check(p, priv.X, priv.Y, big.NewInt(k), big.NewInt(k+1))
+	}
+
+	// With P = G, equal digits add a point to itself, and s = n - t sums
+	// to the point at infinity.
+	params := c.Params()
+	for _, s := range []*big.Int{big.NewInt(1), big.NewInt(0x123456789), bigFromHex(t, sm2SignVector.k)} {
+		check(g, params.Gx, params.Gy, s, s)
+		check(g, params.Gx, params.Gy, s, new(big.Int).Sub(n, s))
+	}
+}
+
+func BenchmarkSM2Verifier(b *testing.B) {
+	priv := sm2VectorKey(b)
+	msg, sig := []byte(sm2SignVector.msg), sm2VectorSignature(b)
+	v, err := NewSM2Verifier(&priv.SM2PublicKey, DefaultSM2UID)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Run("Verify", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if ok, _ := v.Verify(msg, sig); !ok {
+				b.Fatal("signature rejected")
+			}
+		}
+	})
+	b.Run("NewSM2Verifier", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			NewSM2Verifier(&priv.SM2PublicKey, DefaultSM2UID)
+		}
+	})
+}
@@ -0,0 +1,220 @@
+// Package sm3 implements the SM3 cryptographic hash algorithm as defined in
+// GB/T 32905-2016 (GM/T 0004-2012), together with the constructions built on
+// top of it that are used by the GM (ShangMi) cipher suites.
+package sm3
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+)
+
+// The size of an SM3 checksum in bytes.
+const Size = 32
+
+// The blocksize of SM3 in bytes.
+const BlockSize = 64
+
+const (
+	init0 = 0x7380166f
+	init1 = 0x4914b2b9
+	init2 = 0x172442d7
+	init3 = 0xda8a0600
+	init4 = 0xa96f30bc
+	init5 = 0x163138aa
+	init6 = 0xe38dee4d
+	init7 = 0xb0fb0e4e
+)
+
+// digest represents the partial evaluation of an SM3 checksum.
+type digest struct {
+	h   [8]uint32
+	x   [BlockSize]byte
+	nx  int
+	len uint64 // total bytes written
+
+	blocks uint64 // blocks compressed since Reset, for SumWithStats
+}
+
+func (d *digest) Reset() {
+	d.h[0] = init0
+	d.h[1] = init1
+	d.h[2] = init2
+	d.h[3] = init3
+	d.h[4] = init4
+	d.h[5] = init5
+	d.h[6] = init6
+	d.h[7] = init7
+	d.nx = 0
+	d.len = 0
+	d.blocks = 0
+}
+
+// New returns a new hash.Hash computing the SM3 checksum.
+func New() hash.Hash {
+	d := new(digest)
+	d.Reset()
+	return d
+}
+
+// Hash returns New, for constructions that take a hash constructor, such
+// as hkdf.New, pbkdf2.Key and hmac.New from golang.org/x/crypto and the
+// standard library:
+//
+//	key := pbkdf2.Key(password, salt, iterations, 32, sm3.Hash())
+//	kdf := hkdf.New(sm3.Hash(), secret, salt, info)
+func Hash() func() hash.Hash { return New }
+
+func (d *digest) Size() int { return Size }
+
+func (d *digest) BlockSize() int { return BlockSize }
+
+func (d *digest) Write(p []byte) (nn int, err error) {
+	nn = len(p)
+	// len counts bytes in a uint64 so totals past 4 GiB stay exact even
+	// where int is 32 bits.
+	d.len += uint64(nn)
+	if d.nx > 0 {
+		n := copy(d.x[d.nx:], p)
+		d.nx += n
+		if d.nx == BlockSize {
+			block(d, d.x[:])
+			d.nx = 0
+		}
+		p = p[n:]
+	}
+	if len(p) >= BlockSize {
+		n := len(p) &^ (BlockSize - 1)
+		block(d, p[:n])
+		p = p[n:]
+	}
+	if len(p) > 0 {
+		d.nx = copy(d.x[:], p)
+	}
+	return
+}
+
+// WriteString is like Write but takes a string, avoiding the []byte
+// conversion. It is picked up by io.WriteString.
+func (d *digest) WriteString(s string) (nn int, err error) {
+	nn = len(s)
+	d.len += uint64(nn)
+	for len(s) > 0 {
+		n := copy(d.x[d.nx:], s)
+		d.nx += n
+		if d.nx == BlockSize {
+			block(d, d.x[:])
+			d.nx = 0
+		}
+		s = s[n:]
+	}
+	return
+}
+
+func (d *digest) Sum(in []byte) []byte {
+	// Make a copy of d so that caller can keep writing and summing.
+	d0 := *d
+	hash := d0.checkSum()
+	return append(in, hash[:]...)
+}
+
+// checkSum pads and finishes d, leaving it unusable for further writes.
+// The buffered tail and its padding are assembled on the stack and
+// compressed directly, one or two blocks, without going through Write.
+func (d *digest) checkSum() [Size]byte {
+	var tail [2 * BlockSize]byte
+	copy(tail[:], d.x[:d.nx])
+	block(d, appendPadding(tail[:d.nx], d.len<<3))
+	d.nx = 0
+
+	var digest [Size]byte
+	for i, s := range d.h {
+		binary.BigEndian.PutUint32(digest[i*4:], s)
+	}
+	return digest
+}
+
+// appendPadding appends to buf the SM3 padding for a message of bitLen
+// bits, a whole number of bytes: a 1 bit, zero bits up to 56 mod 64 bytes,
+// and bitLen as a big-endian uint64. That is between 9 and BlockSize+8
+// bytes. It is the only implementation of the padding rule; every
+// finalizer goes through it.
+func appendPadding(buf []byte, bitLen uint64) []byte {
+	rem := int((bitLen >> 3) % BlockSize) // reduce before int, which is 32 bits on some targets
+	zeros := 55 - rem
+	if rem >= 56 {
+		zeros += BlockSize
+	}
+	buf = append(buf, 0x80)
+	for i := 0; i < zeros; i++ {
+		buf = append(buf, 0)
+	}
+	return binary.BigEndian.AppendUint64(buf, bitLen)
+}
+
+// Sum returns the SM3 checksum of the data.
+func Sum(data []byte) [Size]byte {
+	var d digest
+	d.Reset()
+	d.Write(data)
+	return d.checkSum()
+}
+
+// Sum256 is an SM3 checksum held as a value, as returned by Sum and
+// Digest.Checksum. Helpers that treat checksums as values, such as Equal,
+// XORDigests and IsEmpty, take and return Sum256; a [Size]byte converts to
+// it implicitly.
+type Sum256 [Size]byte
+
+// Bytes returns s as a new slice, the caller's to modify.
+func (s Sum256) Bytes() []byte { return s[:] }
+
+// Hex returns s as 64 lowercase hex digits.
+func (s Sum256) Hex() string { return hex.EncodeToString(s[:]) }
+
+// EmptyDigest is the SM3 checksum of the empty input, Sum(nil):
+// 1ab21d8355cfa17f8e61194831e81a8f22bec8c728fefb747ed035eb5082aa2b.
+var EmptyDigest = Sum256(Sum(nil))
+
+// IsEmpty reports whether d is EmptyDigest, the checksum of no data.
+func IsEmpty(d Sum256) bool { return d == EmptyDigest }
+
+// SumString returns the SM3 checksum of s without converting it to a
+// byte slice.
+func SumString(s string) [Size]byte {
+	var d digest
+	d.Reset()
+	d.WriteString(s)
+	return d.checkSum()
+}
+
+// SumHex returns the SM3 checksum of data as 64 lowercase hex digits.
+func SumHex(data []byte) string {
+	sum := Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// SumVectored returns the SM3 checksum of the concatenation of bufs,
+// hashing each buffer in turn without copying them together. No framing is
+// added, so the result is Sum of the concatenation, and moving bytes
+// between buffers does not change it.
+func SumVectored(bufs [][]byte) [Size]byte {
+	var d digest
+	d.Reset()
+	for _, b := range bufs {
+		d.Write(b)
+	}
+	return d.checkSum()
+}
+
+// SumWithStats returns the SM3 checksum of the data together with the
+// number of 64-byte blocks compressed to produce it, including the one or
+// two blocks holding the padding and length. The count is taken from the
+// compression calls themselves, not derived from len(data).
+func SumWithStats(data []byte) (sum [Size]byte, blocks int) {
+	var d digest
+	d.Reset()
+	d.Write(data)
+	sum = d.checkSum()
+	return sum, int(d.blocks)
+}
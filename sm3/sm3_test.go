@@ -0,0 +1,359 @@
+package sm3
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+type sm3Test struct {
+	out string
+	in  string
+}
+
+var golden = []sm3Test{
+	{"1ab21d8355cfa17f8e61194831e81a8f22bec8c728fefb747ed035eb5082aa2b", ""},
+	{"66c7f0f462eeedd9d1f2d46bdc10e4e24167c4875cf2f7a2297da02b8f4ba8e0", "abc"},
+	{"debe9ff92275b8a138604889c18e5a4d6fdb70e5387e5765293dcba39c0c5732", strings.Repeat("abcd", 16)},
+}
+
+func TestGolden(t *testing.T) {
+	for _, g := range golden {
+		s := Sum([]byte(g.in))
+		if got := hex.EncodeToString(s[:]); got != g.out {
+			t.Errorf("Sum(%q) = %s want %s", g.in, got, g.out)
+		}
+		if got := SumHex([]byte(g.in)); got != g.out {
+			t.Errorf("SumHex(%q) = %s want %s", g.in, got, g.out)
+		}
+		c := New()
+		for j := 0; j < 3; j++ {
+			if j < 2 {
+				c.Write([]byte(g.in))
+			} else {
+				c.Write([]byte(g.in[0 : len(g.in)/2]))
+				c.Sum(nil)
+				c.Write([]byte(g.in[len(g.in)/2:]))
+			}
+			if got := hex.EncodeToString(c.Sum(nil)); got != g.out {
+				t.Errorf("sm3[%d](%q) = %s want %s", j, g.in, got, g.out)
+			}
+			c.Reset()
+		}
+	}
+}
+
+func TestEmptyDigest(t *testing.T) {
+	if EmptyDigest != Sum([]byte{}) {
+		t.Errorf("EmptyDigest = %x, want Sum([]byte{}) = %x", EmptyDigest, Sum([]byte{}))
+	}
+	if got := hex.EncodeToString(EmptyDigest[:]); got != golden[0].out {
+		t.Errorf("EmptyDigest = %s, want %s", got, golden[0].out)
+	}
+	if !IsEmpty(Sum(nil)) || !IsEmpty(SumString("")) {
+		t.Error("IsEmpty(Sum(nil)) = false")
+	}
+	if IsEmpty(Sum([]byte{0})) || IsEmpty([Size]byte{}) {
+		t.Error("IsEmpty is true for a digest of data")
+	}
+}
+
+func TestHashFactory(t *testing.T) {
+	h := Hash()()
+	h.Write([]byte("abc"))
+	if got, want := h.Sum(nil), Sum([]byte("abc")); !bytes.Equal(got, want[:]) {
+		t.Errorf("Hash()() Sum = %x, want %x", got, want)
+	}
+}
+
+func TestSize(t *testing.T) {
+	c := New()
+	if got := c.Size(); got != Size {
+		t.Errorf("Size = %d; want %d", got, Size)
+	}
+	if got := c.BlockSize(); got != BlockSize {
+		t.Errorf("BlockSize = %d; want %d", got, BlockSize)
+	}
+}
+
+func TestStreamingMatchesOneShot(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 100)
+	want := Sum(data)
+	for _, chunk := range []int{1, 7, 55, 56, 63, 64, 65, 128, 1000} {
+		h := New()
+		for i := 0; i < len(data); i += chunk {
+			h.Write(data[i:min(i+chunk, len(data))])
+		}
+		if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+			t.Errorf("chunk %d: got %x, want %x", chunk, got, want)
+		}
+	}
+}
+
+func TestWriteAlignment(t *testing.T) {
+	data := make([]byte, 16*BlockSize+5)
+	for i := range data {
+		data[i] = byte(i * 31)
+	}
+	want := Sum(data)
+	// Block-aligned writes take the bulk path with nothing buffered;
+	// a leading odd write forces every later one through d.x first.
+	for _, first := range []int{0, BlockSize, 4 * BlockSize, 1, BlockSize - 1, BlockSize + 3} {
+		h := New()
+		h.Write(data[:first])
+		h.Write(data[first:])
+		if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+			t.Errorf("first write %d bytes: got %x, want %x", first, got, want)
+		}
+	}
+}
+
+// TestRandomChunking checks streaming against one-shot hashing for random
+// inputs split at random points, covering every alignment of the buffered
+// partial block. Set the seed printed on failure to reproduce a case.
+func TestRandomChunking(t *testing.T) {
+	seed := time.Now().UnixNano()
+	rng := rand.New(rand.NewSource(seed))
+	for i := 0; i < 500; i++ {
+		data := make([]byte, rng.Intn(5*BlockSize))
+		rng.Read(data)
+		want := Sum(data)
+
+		h := New()
+		var cuts []int
+		for rest := data; len(rest) > 0; {
+			n := rng.Intn(min(len(rest), 2*BlockSize) + 1)
+			h.Write(rest[:n])
+			cuts = append(cuts, n)
+			rest = rest[n:]
+		}
+		if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+			t.Fatalf("seed %d, case %d: %d bytes written in chunks %v: got %x, want %x", seed, i, len(data), cuts, got, want)
+		}
+	}
+}
+
+func TestSumString(t *testing.T) {
+	for _, s := range []string{"", "abc", strings.Repeat("a", 55), strings.Repeat("b", 64), strings.Repeat("abcdefg", 100)} {
+		if got, want := SumString(s), Sum([]byte(s)); got != want {
+			t.Errorf("SumString(%d bytes) = %x, want %x", len(s), got, want)
+		}
+		h := New()
+		h.(interface{ WriteString(string) (int, error) }).WriteString(s[:len(s)/3])
+		h.Write([]byte(s[len(s)/3:]))
+		if got, want := h.Sum(nil), Sum([]byte(s)); !bytes.Equal(got, want[:]) {
+			t.Errorf("WriteString+Write(%d bytes) = %x, want %x", len(s), got, want)
+		}
+	}
+}
+
+func TestSumStringAllocs(t *testing.T) {
+	s := strings.Repeat("x", 1000)
+	if n := testing.AllocsPerRun(10, func() { SumString(s) }); n > 0 {
+		t.Errorf("SumString allocates %v times, want 0", n)
+	}
+}
+
+// digest implements io.StringWriter, so io.WriteString hashes a string
+// without converting it to a byte slice.
+var _ io.StringWriter = (*digest)(nil)
+
+func TestIOWriteString(t *testing.T) {
+	s := strings.Repeat("abcdefg", 100)
+	want := Sum([]byte(s))
+	h := New()
+	sum := make([]byte, 0, Size)
+	if n := testing.AllocsPerRun(10, func() {
+		h.Reset()
+		io.WriteString(h, s)
+		sum = h.Sum(sum[:0])
+	}); n > 0 {
+		t.Errorf("io.WriteString allocates %v times, want 0", n)
+	}
+	if !bytes.Equal(sum, want[:]) {
+		t.Errorf("io.WriteString digest = %x, want %x", sum, want)
+	}
+}
+
+func TestSumVectored(t *testing.T) {
+	for _, bufs := range [][][]byte{
+		nil,
+		{nil},
+		{[]byte("header: x\r\n"), nil, []byte("\r\n"), bytes.Repeat([]byte("body"), 40)},
+		{buf[:BlockSize], {}, buf[BlockSize : 2*BlockSize+1], {}, {}},
+	} {
+		if got, want := SumVectored(bufs), Sum(bytes.Join(bufs, nil)); got != want {
+			t.Errorf("SumVectored(%d buffers) = %x, want %x", len(bufs), got, want)
+		}
+	}
+}
+
+func TestSumWithStats(t *testing.T) {
+	for _, tt := range []struct{ n, blocks int }{
+		{0, 1}, {55, 1}, {56, 2}, {63, 2}, {64, 2}, {119, 2}, {120, 3}, {1000, 16}, {4096, 65},
+	} {
+		data := bytes.Repeat([]byte{'a'}, tt.n)
+		digest, blocks := SumWithStats(data)
+		if blocks != tt.blocks {
+			t.Errorf("len %d: blocks = %d, want %d", tt.n, blocks, tt.blocks)
+		}
+		if digest != Sum(data) {
+			t.Errorf("len %d: digest differs from Sum", tt.n)
+		}
+	}
+}
+
+func TestSumLeavesStateUntouched(t *testing.T) {
+	h := New().(*digest)
+	var written []byte
+	for _, n := range []int{0, 3, 55, 56, 64, 65, 200} {
+		chunk := bytes.Repeat([]byte{byte(n)}, n)
+		h.Write(chunk)
+		written = append(written, chunk...)
+		before := *h
+		first := h.Sum(nil)
+		if *h != before {
+			t.Fatalf("after %d more bytes: Sum changed the hasher state", n)
+		}
+		if second := h.Sum([]byte("prefix"))[len("prefix"):]; !bytes.Equal(first, second) {
+			t.Errorf("after %d more bytes: repeated Sum = %x, want %x", n, second, first)
+		}
+		// Only the bytes written between Sums change the result.
+		if want := Sum(written); !bytes.Equal(first, want[:]) {
+			t.Errorf("after %d more bytes: Sum = %x, want Sum of the bytes written %x", n, first, want)
+		}
+	}
+}
+
+func TestFinalizeBoundaries(t *testing.T) {
+	reference := sumWith(block)
+	data := treeTestData(2*BlockSize + 1)
+	var out [Size]byte
+	for n := 0; n <= len(data); n++ {
+		want := reference(data[:n])
+		h := New()
+		h.Write(data[:n])
+		if got := h.Sum(out[:0]); !bytes.Equal(got, want[:]) {
+			t.Errorf("%d bytes: Sum = %x, want %x", n, got, want)
+		}
+		if got := Sum(data[:n]); got != want {
+			t.Errorf("%d bytes: one-shot Sum = %x, want %x", n, got, want)
+		}
+		if allocs := testing.AllocsPerRun(10, func() { Sum(data[:n]); h.Sum(out[:0]) }); allocs != 0 {
+			t.Errorf("%d bytes: finalizing allocates %v times", n, allocs)
+		}
+	}
+}
+
+func TestAppendPadding(t *testing.T) {
+	data := make([]byte, 3*BlockSize)
+	for n := 0; n <= len(data); n++ {
+		pad := appendPadding(nil, uint64(n)<<3)
+		if want := padMessage(data[:n])[n:]; !bytes.Equal(pad, want) {
+			t.Errorf("n = %d: padding %x, want %x", n, pad, want)
+		}
+		if len(pad) < 9 || len(pad) > BlockSize+8 || (n+len(pad))%BlockSize != 0 {
+			t.Errorf("n = %d: %d bytes of padding", n, len(pad))
+		}
+	}
+	// The padding is appended, not written over buf.
+	if got := appendPadding([]byte("xy"), 16); !bytes.HasPrefix(got, []byte("xy\x80")) || len(got) != 2+62 {
+		t.Errorf("appendPadding(\"xy\", 16) = %x", got)
+	}
+}
+
+var bench = New()
+var benchSHA256 = sha256.New()
+var buf = make([]byte, 8192)
+
+func benchmarkSize(h hash.Hash, b *testing.B, size int) {
+	sum := make([]byte, h.Size())
+	b.SetBytes(int64(size))
+	for i := 0; i < b.N; i++ {
+		h.Reset()
+		h.Write(buf[:size])
+		h.Sum(sum[:0])
+	}
+}
+
+func BenchmarkHash8Bytes(b *testing.B) {
+	benchmarkSize(bench, b, 8)
+}
+
+func BenchmarkHash1K(b *testing.B) {
+	benchmarkSize(bench, b, 1024)
+}
+
+func BenchmarkHash8K(b *testing.B) {
+	benchmarkSize(bench, b, 8192)
+}
+
+var buf1M = make([]byte, 1<<20)
+
+// BenchmarkWrite1MAligned and BenchmarkWrite1MUnaligned measure bulk
+// writes with nothing buffered and with a partial block pending.
+func BenchmarkWrite1MAligned(b *testing.B) {
+	b.SetBytes(int64(len(buf1M)))
+	for i := 0; i < b.N; i++ {
+		bench.Reset()
+		bench.Write(buf1M)
+	}
+}
+
+func BenchmarkWrite1MUnaligned(b *testing.B) {
+	b.SetBytes(int64(len(buf1M)))
+	for i := 0; i < b.N; i++ {
+		bench.Reset()
+		bench.Write(buf1M[:1])
+		bench.Write(buf1M[1:])
+	}
+}
+
+// The SHA256 benchmarks run over the same buffers as the SM3 ones, as a
+// point of comparison on the host running them.
+
+func BenchmarkSHA256Hash8Bytes(b *testing.B) {
+	benchmarkSize(benchSHA256, b, 8)
+}
+
+func BenchmarkSHA256Hash1K(b *testing.B) {
+	benchmarkSize(benchSHA256, b, 1024)
+}
+
+func BenchmarkSHA256Hash8K(b *testing.B) {
+	benchmarkSize(benchSHA256, b, 8192)
+}
+
+func BenchmarkSumString(b *testing.B) {
+	s := string(buf[:1024])
+	b.SetBytes(int64(len(s)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		SumString(s)
+	}
+}
+
+func BenchmarkSum16(b *testing.B) {
+	b.ReportAllocs()
+	b.SetBytes(16)
+	for i := 0; i < b.N; i++ {
+		Sum(buf[:16])
+	}
+}
+
+func BenchmarkHashSum16(b *testing.B) {
+	var out [Size]byte
+	b.ReportAllocs()
+	b.SetBytes(16)
+	for i := 0; i < b.N; i++ {
+		bench.Reset()
+		bench.Write(buf[:16])
+		bench.Sum(out[:0])
+	}
+}
@@ -0,0 +1,108 @@
+package sm3
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+//go:generate go run gen_unrolled.go
+
+const (
+	t0 = 0x79cc4519 // T_j for 0 <= j <= 15
+	t1 = 0x7a879d8a // T_j for 16 <= j <= 63
+)
+
+// tj holds T_j <<< (j mod 32) for each round, so the rounds load a
+// constant instead of rotating one.
+var tj = func() (t [64]uint32) {
+	for j := range t {
+		if j < 16 {
+			t[j] = bits.RotateLeft32(t0, j)
+		} else {
+			t[j] = bits.RotateLeft32(t1, j%32)
+		}
+	}
+	return t
+}()
+
+func p0(x uint32) uint32 {
+	return x ^ bits.RotateLeft32(x, 9) ^ bits.RotateLeft32(x, 17)
+}
+
+func p1(x uint32) uint32 {
+	return x ^ bits.RotateLeft32(x, 15) ^ bits.RotateLeft32(x, 23)
+}
+
+// loadWordsGeneric is the portable loadWords, and the reference the fast
+// loader is tested against.
+func loadWordsGeneric(w *[16]uint32, p *[BlockSize]byte) {
+	for i := range w {
+		w[i] = binary.BigEndian.Uint32(p[i*4:])
+	}
+}
+
+// blockLoop runs the SM3 compression function over every full block in p,
+// updating the chaining value in dig, with the rounds as two loops. It is
+// block unless the sm3unrolled build tag selects blockUnrolled.
+//
+// SM3 is defined over big-endian 32-bit words: message words are loaded
+// big-endian by loadWords, and checkSum writes the bit length and the digest
+// big-endian, on every host. Little-endian loads would be faster on most
+// machines and silently wrong; TestBigEndianWords guards against that.
+func blockLoop(dig *digest, p []byte) {
+	var w [68]uint32
+	h0, h1, h2, h3, h4, h5, h6, h7 := dig.h[0], dig.h[1], dig.h[2], dig.h[3], dig.h[4], dig.h[5], dig.h[6], dig.h[7]
+	for len(p) >= BlockSize {
+		// Message expansion.
+		// One conversion checks the block's length, so the word loads
+		// need no bounds checks of their own.
+		loadWords((*[16]uint32)(w[:16]), (*[BlockSize]byte)(p))
+		for i := 16; i < 68; i++ {
+			w[i] = p1(w[i-16]^w[i-9]^bits.RotateLeft32(w[i-3], 15)) ^ bits.RotateLeft32(w[i-13], 7) ^ w[i-6]
+		}
+
+		a, b, c, d, e, f, g, h := h0, h1, h2, h3, h4, h5, h6, h7
+		for j := 0; j < 16; j++ {
+			a12 := bits.RotateLeft32(a, 12)
+			ss1 := bits.RotateLeft32(a12+e+tj[j], 7)
+			ss2 := ss1 ^ a12
+			tt1 := (a ^ b ^ c) + d + ss2 + (w[j] ^ w[j+4])
+			tt2 := (e ^ f ^ g) + h + ss1 + w[j]
+			d = c
+			c = bits.RotateLeft32(b, 9)
+			b = a
+			a = tt1
+			h = g
+			g = bits.RotateLeft32(f, 19)
+			f = e
+			e = p0(tt2)
+		}
+		for j := 16; j < 64; j++ {
+			a12 := bits.RotateLeft32(a, 12)
+			ss1 := bits.RotateLeft32(a12+e+tj[j], 7)
+			ss2 := ss1 ^ a12
+			tt1 := ((a & b) | (a & c) | (b & c)) + d + ss2 + (w[j] ^ w[j+4])
+			tt2 := ((e & f) | (^e & g)) + h + ss1 + w[j]
+			d = c
+			c = bits.RotateLeft32(b, 9)
+			b = a
+			a = tt1
+			h = g
+			g = bits.RotateLeft32(f, 19)
+			f = e
+			e = p0(tt2)
+		}
+
+		h0 ^= a
+		h1 ^= b
+		h2 ^= c
+		h3 ^= d
+		h4 ^= e
+		h5 ^= f
+		h6 ^= g
+		h7 ^= h
+
+		p = p[BlockSize:]
+	}
+	dig.h[0], dig.h[1], dig.h[2], dig.h[3], dig.h[4], dig.h[5], dig.h[6], dig.h[7] = h0, h1, h2, h3, h4, h5, h6, h7
+}
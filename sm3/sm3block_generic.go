@@ -0,0 +1,6 @@
+//go:build !(386 || amd64 || arm64 || ppc64le) || purego
+
+package sm3
+
+// loadWords loads the 16 big-endian message words of the block p.
+func loadWords(w *[16]uint32, p *[BlockSize]byte) { loadWordsGeneric(w, p) }
@@ -0,0 +1,18 @@
+//go:build (386 || amd64 || arm64 || ppc64le) && !purego
+
+package sm3
+
+import (
+	"math/bits"
+	"unsafe"
+)
+
+// loadWords loads the 16 big-endian message words of the block p. On these
+// little-endian targets, which all allow unaligned loads, it reads native
+// words and swaps their bytes.
+func loadWords(w *[16]uint32, p *[BlockSize]byte) {
+	src := (*[16]uint32)(unsafe.Pointer(p))
+	for i := range w {
+		w[i] = bits.ReverseBytes32(src[i])
+	}
+}
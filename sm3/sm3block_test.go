@@ -0,0 +1,134 @@
+package sm3
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/bits"
+	"testing"
+)
+
+// rotl is the shift-and-or rotation of the SM3 specification, used as a
+// reference for bits.RotateLeft32.
+func rotl(x uint32, n int) uint32 {
+	n %= 32
+	return x<<n | x>>(32-n)
+}
+
+func TestRotationAmounts(t *testing.T) {
+	xs := []uint32{0, 1, 0x80000000, 0xffffffff, 0x12345678, t0, t1}
+	for _, n := range []int{7, 9, 12, 15, 17, 19, 23} {
+		for _, x := range xs {
+			if got, want := bits.RotateLeft32(x, n), rotl(x, n); got != want {
+				t.Errorf("RotateLeft32(%#x, %d) = %#x, want %#x", x, n, got, want)
+			}
+		}
+	}
+	for _, x := range xs {
+		if got, want := p0(x), x^rotl(x, 9)^rotl(x, 17); got != want {
+			t.Errorf("p0(%#x) = %#x, want %#x", x, got, want)
+		}
+		if got, want := p1(x), x^rotl(x, 15)^rotl(x, 23); got != want {
+			t.Errorf("p1(%#x) = %#x, want %#x", x, got, want)
+		}
+	}
+	// The precomputed round constants are T_j rotated by j mod 32, for j
+	// up to 63.
+	for j := 0; j < 64; j++ {
+		want := rotl(t1, j)
+		if j < 16 {
+			want = rotl(t0, j)
+		}
+		if tj[j] != want {
+			t.Errorf("tj[%d] = %#x, want %#x", j, tj[j], want)
+		}
+	}
+}
+
+// TestIntermediateStates checks the chaining value after every block of
+// two padded messages, so a change to block that altered intermediate
+// state would be caught even if a final digest happened to survive. The
+// golden states come from an independent implementation of the
+// specification whose final digests were checked against OpenSSL.
+func TestIntermediateStates(t *testing.T) {
+	msg2 := make([]byte, 150)
+	for i := range msg2 {
+		msg2[i] = byte(i * 7)
+	}
+	for _, tt := range []struct {
+		msg    []byte
+		states [][8]uint32
+	}{
+		{[]byte(golden[2].in), [][8]uint32{
+			{0x5950de81, 0x468664eb, 0x42fd4c86, 0x1e7ca00a, 0xc0a5910b, 0xae9a55ea, 0x1adb8d17, 0x763ca222},
+			{0xdebe9ff9, 0x2275b8a1, 0x38604889, 0xc18e5a4d, 0x6fdb70e5, 0x387e5765, 0x293dcba3, 0x9c0c5732},
+		}},
+		{msg2, [][8]uint32{
+			{0x0ff8b53f, 0x25820e0e, 0x6ace9edf, 0x7052f822, 0xbbf60294, 0x593813f4, 0x8eee52f4, 0xb5971707},
+			{0x7bb0e8ad, 0x3e4b8ae0, 0x57733a57, 0x4d8a1a6a, 0xfe6f5ff3, 0x907d43e5, 0xe549d5ca, 0x78f4546e},
+			{0x416ebb61, 0x8c75ea1c, 0x8adf4e35, 0x56c438be, 0xa6bd6f06, 0x0e13174c, 0x40504842, 0xcd1f46d3},
+		}},
+	} {
+		padded := padMessage(tt.msg)
+		if len(padded) != len(tt.states)*BlockSize {
+			t.Fatalf("%d-byte message pads to %d blocks, have %d states", len(tt.msg), len(padded)/BlockSize, len(tt.states))
+		}
+		var d digest
+		d.Reset()
+		for i, want := range tt.states {
+			block(&d, padded[i*BlockSize:(i+1)*BlockSize])
+			if d.h != want {
+				t.Errorf("%d-byte message, after block %d: h = %08x, want %08x", len(tt.msg), i, d.h, want)
+			}
+		}
+	}
+}
+
+// TestBlockImplementations checks that the loop and unrolled compression
+// functions agree with each other and the standard vectors, whichever of
+// them block is.
+func TestBlockImplementations(t *testing.T) {
+	loop, unrolled := sumWith(blockLoop), sumWith(blockUnrolled)
+	for _, g := range golden {
+		want, _ := hex.DecodeString(g.out)
+		for name, sum := range map[string]func([]byte) [Size]byte{"blockLoop": loop, "blockUnrolled": unrolled} {
+			if got := sum([]byte(g.in)); !bytes.Equal(got[:], want) {
+				t.Errorf("%s: %q: %x, want %x", name, g.in, got, want)
+			}
+		}
+	}
+	data := treeTestData(5 * BlockSize)
+	for n := 0; n <= len(data); n += 7 {
+		if a, b := loop(data[:n]), unrolled(data[:n]); a != b {
+			t.Fatalf("%d bytes: blockLoop %x, blockUnrolled %x", n, a, b)
+		}
+	}
+}
+
+// BenchmarkBlockImpl compares the compression functions that block can be
+// built from, to choose the default:
+//
+//	go test -run NONE -bench BlockImpl -count 10 | benchstat -col /impl -
+func BenchmarkBlockImpl(b *testing.B) {
+	for _, impl := range []struct {
+		name string
+		fn   func(*digest, []byte)
+	}{{"loop", blockLoop}, {"unrolled", blockUnrolled}} {
+		b.Run("impl="+impl.name, func(b *testing.B) {
+			var d digest
+			d.Reset()
+			b.SetBytes(int64(len(buf)))
+			for i := 0; i < b.N; i++ {
+				impl.fn(&d, buf)
+			}
+		})
+	}
+}
+
+func BenchmarkBlock(b *testing.B) {
+	var d digest
+	d.Reset()
+	b.SetBytes(BlockSize)
+	for i := 0; i < b.N; i++ {
+		block(&d, buf[:BlockSize])
+	}
+}
@@ -0,0 +1,674 @@
+// Code generated by gen_unrolled.go; DO NOT EDIT.
+
+package sm3
+
+import "math/bits"
+
+// blockUnrolled is block with the 64 rounds written out. Instead of
+// shifting the eight working variables after each round, the rounds rotate
+// which variable plays which role, so no values move between rounds.
+func blockUnrolled(dig *digest, p []byte) {
+	var w [68]uint32
+	h0, h1, h2, h3, h4, h5, h6, h7 := dig.h[0], dig.h[1], dig.h[2], dig.h[3], dig.h[4], dig.h[5], dig.h[6], dig.h[7]
+	for len(p) >= BlockSize {
+		loadWords((*[16]uint32)(w[:16]), (*[BlockSize]byte)(p))
+		for i := 16; i < 68; i++ {
+			w[i] = p1(w[i-16]^w[i-9]^bits.RotateLeft32(w[i-3], 15)) ^ bits.RotateLeft32(w[i-13], 7) ^ w[i-6]
+		}
+
+		a, b, c, d, e, f, g, h := h0, h1, h2, h3, h4, h5, h6, h7
+		var a12, ss1, tt1, tt2 uint32
+
+		// Round 0.
+		a12 = bits.RotateLeft32(a, 12)
+		ss1 = bits.RotateLeft32(a12+e+0x79cc4519, 7)
+		tt1 = (a ^ b ^ c) + d + (ss1 ^ a12) + (w[0] ^ w[4])
+		tt2 = (e ^ f ^ g) + h + ss1 + w[0]
+		b = bits.RotateLeft32(b, 9)
+		f = bits.RotateLeft32(f, 19)
+		d = tt1
+		h = p0(tt2)
+
+		// Round 1.
+		a12 = bits.RotateLeft32(d, 12)
+		ss1 = bits.RotateLeft32(a12+h+0xf3988a32, 7)
+		tt1 = (d ^ a ^ b) + c + (ss1 ^ a12) + (w[1] ^ w[5])
+		tt2 = (h ^ e ^ f) + g + ss1 + w[1]
+		a = bits.RotateLeft32(a, 9)
+		e = bits.RotateLeft32(e, 19)
+		c = tt1
+		g = p0(tt2)
+
+		// Round 2.
+		a12 = bits.RotateLeft32(c, 12)
+		ss1 = bits.RotateLeft32(a12+g+0xe7311465, 7)
+		tt1 = (c ^ d ^ a) + b + (ss1 ^ a12) + (w[2] ^ w[6])
+		tt2 = (g ^ h ^ e) + f + ss1 + w[2]
+		d = bits.RotateLeft32(d, 9)
+		h = bits.RotateLeft32(h, 19)
+		b = tt1
+		f = p0(tt2)
+
+		// Round 3.
+		a12 = bits.RotateLeft32(b, 12)
+		ss1 = bits.RotateLeft32(a12+f+0xce6228cb, 7)
+		tt1 = (b ^ c ^ d) + a + (ss1 ^ a12) + (w[3] ^ w[7])
+		tt2 = (f ^ g ^ h) + e + ss1 + w[3]
+		c = bits.RotateLeft32(c, 9)
+		g = bits.RotateLeft32(g, 19)
+		a = tt1
+		e = p0(tt2)
+
+		// Round 4.
+		a12 = bits.RotateLeft32(a, 12)
+		ss1 = bits.RotateLeft32(a12+e+0x9cc45197, 7)
+		tt1 = (a ^ b ^ c) + d + (ss1 ^ a12) + (w[4] ^ w[8])
+		tt2 = (e ^ f ^ g) + h + ss1 + w[4]
+		b = bits.RotateLeft32(b, 9)
+		f = bits.RotateLeft32(f, 19)
+		d = tt1
+		h = p0(tt2)
+
+		// Round 5.
+		a12 = bits.RotateLeft32(d, 12)
+		ss1 = bits.RotateLeft32(a12+h+0x3988a32f, 7)
+		tt1 = (d ^ a ^ b) + c + (ss1 ^ a12) + (w[5] ^ w[9])
+		tt2 = (h ^ e ^ f) + g + ss1 + w[5]
+		a = bits.RotateLeft32(a, 9)
+		e = bits.RotateLeft32(e, 19)
+		c = tt1
+		g = p0(tt2)
+
+		// Round 6.
+		a12 = bits.RotateLeft32(c, 12)
+		ss1 = bits.RotateLeft32(a12+g+0x7311465e, 7)
+		tt1 = (c ^ d ^ a) + b + (ss1 ^ a12) + (w[6] ^ w[10])
+		tt2 = (g ^ h ^ e) + f + ss1 + w[6]
+		d = bits.RotateLeft32(d, 9)
+		h = bits.RotateLeft32(h, 19)
+		b = tt1
+		f = p0(tt2)
+
+		// Round 7.
+		a12 = bits.RotateLeft32(b, 12)
+		ss1 = bits.RotateLeft32(a12+f+0xe6228cbc, 7)
+		tt1 = (b ^ c ^ d) + a + (ss1 ^ a12) + (w[7] ^ w[11])
+		tt2 = (f ^ g ^ h) + e + ss1 + w[7]
+		c = bits.RotateLeft32(c, 9)
+		g = bits.RotateLeft32(g, 19)
+		a = tt1
+		e = p0(tt2)
+
+		// Round 8.
+		a12 = bits.RotateLeft32(a, 12)
+		ss1 = bits.RotateLeft32(a12+e+0xcc451979, 7)
+		tt1 = (a ^ b ^ c) + d + (ss1 ^ a12) + (w[8] ^ w[12])
+		tt2 = (e ^ f ^ g) + h + ss1 + w[8]
+		b = bits.RotateLeft32(b, 9)
+		f = bits.RotateLeft32(f, 19)
+		d = tt1
+		h = p0(tt2)
+
+		// Round 9.
+		a12 = bits.RotateLeft32(d, 12)
+		ss1 = bits.RotateLeft32(a12+h+0x988a32f3, 7)
+		tt1 = (d ^ a ^ b) + c + (ss1 ^ a12) + (w[9] ^ w[13])
+		tt2 = (h ^ e ^ f) + g + ss1 + w[9]
+		a = bits.RotateLeft32(a, 9)
+		e = bits.RotateLeft32(e, 19)
+		c = tt1
+		g = p0(tt2)
+
+		// Round 10.
+		a12 = bits.RotateLeft32(c, 12)
+		ss1 = bits.RotateLeft32(a12+g+0x311465e7, 7)
+		tt1 = (c ^ d ^ a) + b + (ss1 ^ a12) + (w[10] ^ w[14])
+		tt2 = (g ^ h ^ e) + f + ss1 + w[10]
+		d = bits.RotateLeft32(d, 9)
+		h = bits.RotateLeft32(h, 19)
+		b = tt1
+		f = p0(tt2)
+
+		// Round 11.
+		a12 = bits.RotateLeft32(b, 12)
+		ss1 = bits.RotateLeft32(a12+f+0x6228cbce, 7)
+		tt1 = (b ^ c ^ d) + a + (ss1 ^ a12) + (w[11] ^ w[15])
+		tt2 = (f ^ g ^ h) + e + ss1 + w[11]
+		c = bits.RotateLeft32(c, 9)
+		g = bits.RotateLeft32(g, 19)
+		a = tt1
+		e = p0(tt2)
+
+		// Round 12.
+		a12 = bits.RotateLeft32(a, 12)
+		ss1 = bits.RotateLeft32(a12+e+0xc451979c, 7)
+		tt1 = (a ^ b ^ c) + d + (ss1 ^ a12) + (w[12] ^ w[16])
+		tt2 = (e ^ f ^ g) + h + ss1 + w[12]
+		b = bits.RotateLeft32(b, 9)
+		f = bits.RotateLeft32(f, 19)
+		d = tt1
+		h = p0(tt2)
+
+		// Round 13.
+		a12 = bits.RotateLeft32(d, 12)
+		ss1 = bits.RotateLeft32(a12+h+0x88a32f39, 7)
+		tt1 = (d ^ a ^ b) + c + (ss1 ^ a12) + (w[13] ^ w[17])
+		tt2 = (h ^ e ^ f) + g + ss1 + w[13]
+		a = bits.RotateLeft32(a, 9)
+		e = bits.RotateLeft32(e, 19)
+		c = tt1
+		g = p0(tt2)
+
+		// Round 14.
+		a12 = bits.RotateLeft32(c, 12)
+		ss1 = bits.RotateLeft32(a12+g+0x11465e73, 7)
+		tt1 = (c ^ d ^ a) + b + (ss1 ^ a12) + (w[14] ^ w[18])
+		tt2 = (g ^ h ^ e) + f + ss1 + w[14]
+		d = bits.RotateLeft32(d, 9)
+		h = bits.RotateLeft32(h, 19)
+		b = tt1
+		f = p0(tt2)
+
+		// Round 15.
+		a12 = bits.RotateLeft32(b, 12)
+		ss1 = bits.RotateLeft32(a12+f+0x228cbce6, 7)
+		tt1 = (b ^ c ^ d) + a + (ss1 ^ a12) + (w[15] ^ w[19])
+		tt2 = (f ^ g ^ h) + e + ss1 + w[15]
+		c = bits.RotateLeft32(c, 9)
+		g = bits.RotateLeft32(g, 19)
+		a = tt1
+		e = p0(tt2)
+
+		// Round 16.
+		a12 = bits.RotateLeft32(a, 12)
+		ss1 = bits.RotateLeft32(a12+e+0x9d8a7a87, 7)
+		tt1 = ((a & b) | (a & c) | (b & c)) + d + (ss1 ^ a12) + (w[16] ^ w[20])
+		tt2 = ((e & f) | (^e & g)) + h + ss1 + w[16]
+		b = bits.RotateLeft32(b, 9)
+		f = bits.RotateLeft32(f, 19)
+		d = tt1
+		h = p0(tt2)
+
+		// Round 17.
+		a12 = bits.RotateLeft32(d, 12)
+		ss1 = bits.RotateLeft32(a12+h+0x3b14f50f, 7)
+		tt1 = ((d & a) | (d & b) | (a & b)) + c + (ss1 ^ a12) + (w[17] ^ w[21])
+		tt2 = ((h & e) | (^h & f)) + g + ss1 + w[17]
+		a = bits.RotateLeft32(a, 9)
+		e = bits.RotateLeft32(e, 19)
+		c = tt1
+		g = p0(tt2)
+
+		// Round 18.
+		a12 = bits.RotateLeft32(c, 12)
+		ss1 = bits.RotateLeft32(a12+g+0x7629ea1e, 7)
+		tt1 = ((c & d) | (c & a) | (d & a)) + b + (ss1 ^ a12) + (w[18] ^ w[22])
+		tt2 = ((g & h) | (^g & e)) + f + ss1 + w[18]
+		d = bits.RotateLeft32(d, 9)
+		h = bits.RotateLeft32(h, 19)
+		b = tt1
+		f = p0(tt2)
+
+		// Round 19.
+		a12 = bits.RotateLeft32(b, 12)
+		ss1 = bits.RotateLeft32(a12+f+0xec53d43c, 7)
+		tt1 = ((b & c) | (b & d) | (c & d)) + a + (ss1 ^ a12) + (w[19] ^ w[23])
+		tt2 = ((f & g) | (^f & h)) + e + ss1 + w[19]
+		c = bits.RotateLeft32(c, 9)
+		g = bits.RotateLeft32(g, 19)
+		a = tt1
+		e = p0(tt2)
+
+		// Round 20.
+		a12 = bits.RotateLeft32(a, 12)
+		ss1 = bits.RotateLeft32(a12+e+0xd8a7a879, 7)
+		tt1 = ((a & b) | (a & c) | (b & c)) + d + (ss1 ^ a12) + (w[20] ^ w[24])
+		tt2 = ((e & f) | (^e & g)) + h + ss1 + w[20]
+		b = bits.RotateLeft32(b, 9)
+		f = bits.RotateLeft32(f, 19)
+		d = tt1
+		h = p0(tt2)
+
+		// Round 21.
+		a12 = bits.RotateLeft32(d, 12)
+		ss1 = bits.RotateLeft32(a12+h+0xb14f50f3, 7)
+		tt1 = ((d & a) | (d & b) | (a & b)) + c + (ss1 ^ a12) + (w[21] ^ w[25])
+		tt2 = ((h & e) | (^h & f)) + g + ss1 + w[21]
+		a = bits.RotateLeft32(a, 9)
+		e = bits.RotateLeft32(e, 19)
+		c = tt1
+		g = p0(tt2)
+
+		// Round 22.
+		a12 = bits.RotateLeft32(c, 12)
+		ss1 = bits.RotateLeft32(a12+g+0x629ea1e7, 7)
+		tt1 = ((c & d) | (c & a) | (d & a)) + b + (ss1 ^ a12) + (w[22] ^ w[26])
+		tt2 = ((g & h) | (^g & e)) + f + ss1 + w[22]
+		d = bits.RotateLeft32(d, 9)
+		h = bits.RotateLeft32(h, 19)
+		b = tt1
+		f = p0(tt2)
+
+		// Round 23.
+		a12 = bits.RotateLeft32(b, 12)
+		ss1 = bits.RotateLeft32(a12+f+0xc53d43ce, 7)
+		tt1 = ((b & c) | (b & d) | (c & d)) + a + (ss1 ^ a12) + (w[23] ^ w[27])
+		tt2 = ((f & g) | (^f & h)) + e + ss1 + w[23]
+		c = bits.RotateLeft32(c, 9)
+		g = bits.RotateLeft32(g, 19)
+		a = tt1
+		e = p0(tt2)
+
+		// Round 24.
+		a12 = bits.RotateLeft32(a, 12)
+		ss1 = bits.RotateLeft32(a12+e+0x8a7a879d, 7)
+		tt1 = ((a & b) | (a & c) | (b & c)) + d + (ss1 ^ a12) + (w[24] ^ w[28])
+		tt2 = ((e & f) | (^e & g)) + h + ss1 + w[24]
+		b = bits.RotateLeft32(b, 9)
+		f = bits.RotateLeft32(f, 19)
+		d = tt1
+		h = p0(tt2)
+
+		// Round 25.
+		a12 = bits.RotateLeft32(d, 12)
+		ss1 = bits.RotateLeft32(a12+h+0x14f50f3b, 7)
+		tt1 = ((d & a) | (d & b) | (a & b)) + c + (ss1 ^ a12) + (w[25] ^ w[29])
+		tt2 = ((h & e) | (^h & f)) + g + ss1 + w[25]
+		a = bits.RotateLeft32(a, 9)
+		e = bits.RotateLeft32(e, 19)
+		c = tt1
+		g = p0(tt2)
+
+		// Round 26.
+		a12 = bits.RotateLeft32(c, 12)
+		ss1 = bits.RotateLeft32(a12+g+0x29ea1e76, 7)
+		tt1 = ((c & d) | (c & a) | (d & a)) + b + (ss1 ^ a12) + (w[26] ^ w[30])
+		tt2 = ((g & h) | (^g & e)) + f + ss1 + w[26]
+		d = bits.RotateLeft32(d, 9)
+		h = bits.RotateLeft32(h, 19)
+		b = tt1
+		f = p0(tt2)
+
+		// Round 27.
+		a12 = bits.RotateLeft32(b, 12)
+		ss1 = bits.RotateLeft32(a12+f+0x53d43cec, 7)
+		tt1 = ((b & c) | (b & d) | (c & d)) + a + (ss1 ^ a12) + (w[27] ^ w[31])
+		tt2 = ((f & g) | (^f & h)) + e + ss1 + w[27]
+		c = bits.RotateLeft32(c, 9)
+		g = bits.RotateLeft32(g, 19)
+		a = tt1
+		e = p0(tt2)
+
+		// Round 28.
+		a12 = bits.RotateLeft32(a, 12)
+		ss1 = bits.RotateLeft32(a12+e+0xa7a879d8, 7)
+		tt1 = ((a & b) | (a & c) | (b & c)) + d + (ss1 ^ a12) + (w[28] ^ w[32])
+		tt2 = ((e & f) | (^e & g)) + h + ss1 + w[28]
+		b = bits.RotateLeft32(b, 9)
+		f = bits.RotateLeft32(f, 19)
+		d = tt1
+		h = p0(tt2)
+
+		// Round 29.
+		a12 = bits.RotateLeft32(d, 12)
+		ss1 = bits.RotateLeft32(a12+h+0x4f50f3b1, 7)
+		tt1 = ((d & a) | (d & b) | (a & b)) + c + (ss1 ^ a12) + (w[29] ^ w[33])
+		tt2 = ((h & e) | (^h & f)) + g + ss1 + w[29]
+		a = bits.RotateLeft32(a, 9)
+		e = bits.RotateLeft32(e, 19)
+		c = tt1
+		g = p0(tt2)
+
+		// Round 30.
+		a12 = bits.RotateLeft32(c, 12)
+		ss1 = bits.RotateLeft32(a12+g+0x9ea1e762, 7)
+		tt1 = ((c & d) | (c & a) | (d & a)) + b + (ss1 ^ a12) + (w[30] ^ w[34])
+		tt2 = ((g & h) | (^g & e)) + f + ss1 + w[30]
+		d = bits.RotateLeft32(d, 9)
+		h = bits.RotateLeft32(h, 19)
+		b = tt1
+		f = p0(tt2)
+
+		// Round 31.
+		a12 = bits.RotateLeft32(b, 12)
+		ss1 = bits.RotateLeft32(a12+f+0x3d43cec5, 7)
+		tt1 = ((b & c) | (b & d) | (c & d)) + a + (ss1 ^ a12) + (w[31] ^ w[35])
+		tt2 = ((f & g) | (^f & h)) + e + ss1 + w[31]
+		c = bits.RotateLeft32(c, 9)
+		g = bits.RotateLeft32(g, 19)
+		a = tt1
+		e = p0(tt2)
+
+		// Round 32.
+		a12 = bits.RotateLeft32(a, 12)
+		ss1 = bits.RotateLeft32(a12+e+0x7a879d8a, 7)
+		tt1 = ((a & b) | (a & c) | (b & c)) + d + (ss1 ^ a12) + (w[32] ^ w[36])
+		tt2 = ((e & f) | (^e & g)) + h + ss1 + w[32]
+		b = bits.RotateLeft32(b, 9)
+		f = bits.RotateLeft32(f, 19)
+		d = tt1
+		h = p0(tt2)
+
+		// Round 33.
+		a12 = bits.RotateLeft32(d, 12)
+		ss1 = bits.RotateLeft32(a12+h+0xf50f3b14, 7)
+		tt1 = ((d & a) | (d & b) | (a & b)) + c + (ss1 ^ a12) + (w[33] ^ w[37])
+		tt2 = ((h & e) | (^h & f)) + g + ss1 + w[33]
+		a = bits.RotateLeft32(a, 9)
+		e = bits.RotateLeft32(e, 19)
+		c = tt1
+		g = p0(tt2)
+
+		// Round 34.
+		a12 = bits.RotateLeft32(c, 12)
+		ss1 = bits.RotateLeft32(a12+g+0xea1e7629, 7)
+		tt1 = ((c & d) | (c & a) | (d & a)) + b + (ss1 ^ a12) + (w[34] ^ w[38])
+		tt2 = ((g & h) | (^g & e)) + f + ss1 + w[34]
+		d = bits.RotateLeft32(d, 9)
+		h = bits.RotateLeft32(h, 19)
+		b = tt1
+		f = p0(tt2)
+
+		// Round 35.
+		a12 = bits.RotateLeft32(b, 12)
+		ss1 = bits.RotateLeft32(a12+f+0xd43cec53, 7)
+		tt1 = ((b & c) | (b & d) | (c & d)) + a + (ss1 ^ a12) + (w[35] ^ w[39])
+		tt2 = ((f & g) | (^f & h)) + e + ss1 + w[35]
+		c = bits.RotateLeft32(c, 9)
+		g = bits.RotateLeft32(g, 19)
+		a = tt1
+		e = p0(tt2)
+
+		// Round 36.
+		a12 = bits.RotateLeft32(a, 12)
+		ss1 = bits.RotateLeft32(a12+e+0xa879d8a7, 7)
+		tt1 = ((a & b) | (a & c) | (b & c)) + d + (ss1 ^ a12) + (w[36] ^ w[40])
+		tt2 = ((e & f) | (^e & g)) + h + ss1 + w[36]
+		b = bits.RotateLeft32(b, 9)
+		f = bits.RotateLeft32(f, 19)
+		d = tt1
+		h = p0(tt2)
+
+		// Round 37.
+		a12 = bits.RotateLeft32(d, 12)
+		ss1 = bits.RotateLeft32(a12+h+0x50f3b14f, 7)
+		tt1 = ((d & a) | (d & b) | (a & b)) + c + (ss1 ^ a12) + (w[37] ^ w[41])
+		tt2 = ((h & e) | (^h & f)) + g + ss1 + w[37]
+		a = bits.RotateLeft32(a, 9)
+		e = bits.RotateLeft32(e, 19)
+		c = tt1
+		g = p0(tt2)
+
+		// Round 38.
+		a12 = bits.RotateLeft32(c, 12)
+		ss1 = bits.RotateLeft32(a12+g+0xa1e7629e, 7)
+		tt1 = ((c & d) | (c & a) | (d & a)) + b + (ss1 ^ a12) + (w[38] ^ w[42])
+		tt2 = ((g & h) | (^g & e)) + f + ss1 + w[38]
+		d = bits.RotateLeft32(d, 9)
+		h = bits.RotateLeft32(h, 19)
+		b = tt1
+		f = p0(tt2)
+
+		// Round 39.
+		a12 = bits.RotateLeft32(b, 12)
+		ss1 = bits.RotateLeft32(a12+f+0x43cec53d, 7)
+		tt1 = ((b & c) | (b & d) | (c & d)) + a + (ss1 ^ a12) + (w[39] ^ w[43])
+		tt2 = ((f & g) | (^f & h)) + e + ss1 + w[39]
+		c = bits.RotateLeft32(c, 9)
+		g = bits.RotateLeft32(g, 19)
+		a = tt1
+		e = p0(tt2)
+
+		// Round 40.
+		a12 = bits.RotateLeft32(a, 12)
+		ss1 = bits.RotateLeft32(a12+e+0x879d8a7a, 7)
+		tt1 = ((a & b) | (a & c) | (b & c)) + d + (ss1 ^ a12) + (w[40] ^ w[44])
+		tt2 = ((e & f) | (^e & g)) + h + ss1 + w[40]
+		b = bits.RotateLeft32(b, 9)
+		f = bits.RotateLeft32(f, 19)
+		d = tt1
+		h = p0(tt2)
+
+		// Round 41.
+		a12 = bits.RotateLeft32(d, 12)
+		ss1 = bits.RotateLeft32(a12+h+0x0f3b14f5, 7)
+		tt1 = ((d & a) | (d & b) | (a & b)) + c + (ss1 ^ a12) + (w[41] ^ w[45])
+		tt2 = ((h & e) | (^h & f)) + g + ss1 + w[41]
+		a = bits.RotateLeft32(a, 9)
+		e = bits.RotateLeft32(e, 19)
+		c = tt1
+		g = p0(tt2)
+
+		// Round 42.
+		a12 = bits.RotateLeft32(c, 12)
+		ss1 = bits.RotateLeft32(a12+g+0x1e7629ea, 7)
+		tt1 = ((c & d) | (c & a) | (d & a)) + b + (ss1 ^ a12) + (w[42] ^ w[46])
+		tt2 = ((g & h) | (^g & e)) + f + ss1 + w[42]
+		d = bits.RotateLeft32(d, 9)
+		h = bits.RotateLeft32(h, 19)
+		b = tt1
+		f = p0(tt2)
+
+		// Round 43.
+		a12 = bits.RotateLeft32(b, 12)
+		ss1 = bits.RotateLeft32(a12+f+0x3cec53d4, 7)
+		tt1 = ((b & c) | (b & d) | (c & d)) + a + (ss1 ^ a12) + (w[43] ^ w[47])
+		tt2 = ((f & g) | (^f & h)) + e + ss1 + w[43]
+		c = bits.RotateLeft32(c, 9)
+		g = bits.RotateLeft32(g, 19)
+		a = tt1
+		e = p0(tt2)
+
+		// Round 44.
+		a12 = bits.RotateLeft32(a, 12)
+		ss1 = bits.RotateLeft32(a12+e+0x79d8a7a8, 7)
+		tt1 = ((a & b) | (a & c) | (b & c)) + d + (ss1 ^ a12) + (w[44] ^ w[48])
+		tt2 = ((e & f) | (^e & g)) + h + ss1 + w[44]
+		b = bits.RotateLeft32(b, 9)
+		f = bits.RotateLeft32(f, 19)
+		d = tt1
+		h = p0(tt2)
+
+		// Round 45.
+		a12 = bits.RotateLeft32(d, 12)
+		ss1 = bits.RotateLeft32(a12+h+0xf3b14f50, 7)
+		tt1 = ((d & a) | (d & b) | (a & b)) + c + (ss1 ^ a12) + (w[45] ^ w[49])
+		tt2 = ((h & e) | (^h & f)) + g + ss1 + w[45]
+		a = bits.RotateLeft32(a, 9)
+		e = bits.RotateLeft32(e, 19)
+		c = tt1
+		g = p0(tt2)
+
+		// Round 46.
+		a12 = bits.RotateLeft32(c, 12)
+		ss1 = bits.RotateLeft32(a12+g+0xe7629ea1, 7)
+		tt1 = ((c & d) | (c & a) | (d & a)) + b + (ss1 ^ a12) + (w[46] ^ w[50])
+		tt2 = ((g & h) | (^g & e)) + f + ss1 + w[46]
+		d = bits.RotateLeft32(d, 9)
+		h = bits.RotateLeft32(h, 19)
+		b = tt1
+		f = p0(tt2)
+
+		// Round 47.
+		a12 = bits.RotateLeft32(b, 12)
+		ss1 = bits.RotateLeft32(a12+f+0xcec53d43, 7)
+		tt1 = ((b & c) | (b & d) | (c & d)) + a + (ss1 ^ a12) + (w[47] ^ w[51])
+		tt2 = ((f & g) | (^f & h)) + e + ss1 + w[47]
+		c = bits.RotateLeft32(c, 9)
+		g = bits.RotateLeft32(g, 19)
+		a = tt1
+		e = p0(tt2)
+
+		// Round 48.
+		a12 = bits.RotateLeft32(a, 12)
+		ss1 = bits.RotateLeft32(a12+e+0x9d8a7a87, 7)
+		tt1 = ((a & b) | (a & c) | (b & c)) + d + (ss1 ^ a12) + (w[48] ^ w[52])
+		tt2 = ((e & f) | (^e & g)) + h + ss1 + w[48]
+		b = bits.RotateLeft32(b, 9)
+		f = bits.RotateLeft32(f, 19)
+		d = tt1
+		h = p0(tt2)
+
+		// Round 49.
+		a12 = bits.RotateLeft32(d, 12)
+		ss1 = bits.RotateLeft32(a12+h+0x3b14f50f, 7)
+		tt1 = ((d & a) | (d & b) | (a & b)) + c + (ss1 ^ a12) + (w[49] ^ w[53])
+		tt2 = ((h & e) | (^h & f)) + g + ss1 + w[49]
+		a = bits.RotateLeft32(a, 9)
+		e = bits.RotateLeft32(e, 19)
+		c = tt1
+		g = p0(tt2)
+
+		// Round 50.
+		a12 = bits.RotateLeft32(c, 12)
+		ss1 = bits.RotateLeft32(a12+g+0x7629ea1e, 7)
+		tt1 = ((c & d) | (c & a) | (d & a)) + b + (ss1 ^ a12) + (w[50] ^ w[54])
+		tt2 = ((g & h) | (^g & e)) + f + ss1 + w[50]
+		d = bits.RotateLeft32(d, 9)
+		h = bits.RotateLeft32(h, 19)
+		b = tt1
+		f = p0(tt2)
+
+		// Round 51.
+		a12 = bits.RotateLeft32(b, 12)
+		ss1 = bits.RotateLeft32(a12+f+0xec53d43c, 7)
+		tt1 = ((b & c) | (b & d) | (c & d)) + a + (ss1 ^ a12) + (w[51] ^ w[55])
+		tt2 = ((f & g) | (^f & h)) + e + ss1 + w[51]
+		c = bits.RotateLeft32(c, 9)
+		g = bits.RotateLeft32(g, 19)
+		a = tt1
+		e = p0(tt2)
+
+		// Round 52.
+		a12 = bits.RotateLeft32(a, 12)
+		ss1 = bits.RotateLeft32(a12+e+0xd8a7a879, 7)
+		tt1 = ((a & b) | (a & c) | (b & c)) + d + (ss1 ^ a12) + (w[52] ^ w[56])
+		tt2 = ((e & f) | (^e & g)) + h + ss1 + w[52]
+		b = bits.RotateLeft32(b, 9)
+		f = bits.RotateLeft32(f, 19)
+		d = tt1
+		h = p0(tt2)
+
+		// Round 53.
+		a12 = bits.RotateLeft32(d, 12)
+		ss1 = bits.RotateLeft32(a12+h+0xb14f50f3, 7)
+		tt1 = ((d & a) | (d & b) | (a & b)) + c + (ss1 ^ a12) + (w[53] ^ w[57])
+		tt2 = ((h & e) | (^h & f)) + g + ss1 + w[53]
+		a = bits.RotateLeft32(a, 9)
+		e = bits.RotateLeft32(e, 19)
+		c = tt1
+		g = p0(tt2)
+
+		// Round 54.
+		a12 = bits.RotateLeft32(c, 12)
+		ss1 = bits.RotateLeft32(a12+g+0x629ea1e7, 7)
+		tt1 = ((c & d) | (c & a) | (d & a)) + b + (ss1 ^ a12) + (w[54] ^ w[58])
+		tt2 = ((g & h) | (^g & e)) + f + ss1 + w[54]
+		d = bits.RotateLeft32(d, 9)
+		h = bits.RotateLeft32(h, 19)
+		b = tt1
+		f = p0(tt2)
+
+		// Round 55.
+		a12 = bits.RotateLeft32(b, 12)
+		ss1 = bits.RotateLeft32(a12+f+0xc53d43ce, 7)
+		tt1 = ((b & c) | (b & d) | (c & d)) + a + (ss1 ^ a12) + (w[55] ^ w[59])
+		tt2 = ((f & g) | (^f & h)) + e + ss1 + w[55]
+		c = bits.RotateLeft32(c, 9)
+		g = bits.RotateLeft32(g, 19)
+		a = tt1
+		e = p0(tt2)
+
+		// Round 56.
+		a12 = bits.RotateLeft32(a, 12)
+		ss1 = bits.RotateLeft32(a12+e+0x8a7a879d, 7)
+		tt1 = ((a & b) | (a & c) | (b & c)) + d + (ss1 ^ a12) + (w[56] ^ w[60])
+		tt2 = ((e & f) | (^e & g)) + h + ss1 + w[56]
+		b = bits.RotateLeft32(b, 9)
+		f = bits.RotateLeft32(f, 19)
+		d = tt1
+		h = p0(tt2)
+
+		// Round 57.
+		a12 = bits.RotateLeft32(d, 12)
+		ss1 = bits.RotateLeft32(a12+h+0x14f50f3b, 7)
+		tt1 = ((d & a) | (d & b) | (a & b)) + c + (ss1 ^ a12) + (w[57] ^ w[61])
+		tt2 = ((h & e) | (^h & f)) + g + ss1 + w[57]
+		a = bits.RotateLeft32(a, 9)
+		e = bits.RotateLeft32(e, 19)
+		c = tt1
+		g = p0(tt2)
+
+		// Round 58.
+		a12 = bits.RotateLeft32(c, 12)
+		ss1 = bits.RotateLeft32(a12+g+0x29ea1e76, 7)
+		tt1 = ((c & d) | (c & a) | (d & a)) + b + (ss1 ^ a12) + (w[58] ^ w[62])
+		tt2 = ((g & h) | (^g & e)) + f + ss1 + w[58]
+		d = bits.RotateLeft32(d, 9)
+		h = bits.RotateLeft32(h, 19)
+		b = tt1
+		f = p0(tt2)
+
+		// Round 59.
+		a12 = bits.RotateLeft32(b, 12)
+		ss1 = bits.RotateLeft32(a12+f+0x53d43cec, 7)
+		tt1 = ((b & c) | (b & d) | (c & d)) + a + (ss1 ^ a12) + (w[59] ^ w[63])
+		tt2 = ((f & g) | (^f & h)) + e + ss1 + w[59]
+		c = bits.RotateLeft32(c, 9)
+		g = bits.RotateLeft32(g, 19)
+		a = tt1
+		e = p0(tt2)
+
+		// Round 60.
+		a12 = bits.RotateLeft32(a, 12)
+		ss1 = bits.RotateLeft32(a12+e+0xa7a879d8, 7)
+		tt1 = ((a & b) | (a & c) | (b & c)) + d + (ss1 ^ a12) + (w[60] ^ w[64])
+		tt2 = ((e & f) | (^e & g)) + h + ss1 + w[60]
+		b = bits.RotateLeft32(b, 9)
+		f = bits.RotateLeft32(f, 19)
+		d = tt1
+		h = p0(tt2)
+
+		// Round 61.
+		a12 = bits.RotateLeft32(d, 12)
+		ss1 = bits.RotateLeft32(a12+h+0x4f50f3b1, 7)
+		tt1 = ((d & a) | (d & b) | (a & b)) + c + (ss1 ^ a12) + (w[61] ^ w[65])
+		tt2 = ((h & e) | (^h & f)) + g + ss1 + w[61]
+		a = bits.RotateLeft32(a, 9)
+		e = bits.RotateLeft32(e, 19)
+		c = tt1
+		g = p0(tt2)
+
+		// Round 62.
+		a12 = bits.RotateLeft32(c, 12)
+		ss1 = bits.RotateLeft32(a12+g+0x9ea1e762, 7)
+		tt1 = ((c & d) | (c & a) | (d & a)) + b + (ss1 ^ a12) + (w[62] ^ w[66])
+		tt2 = ((g & h) | (^g & e)) + f + ss1 + w[62]
+		d = bits.RotateLeft32(d, 9)
+		h = bits.RotateLeft32(h, 19)
+		b = tt1
+		f = p0(tt2)
+
+		// Round 63.
+		a12 = bits.RotateLeft32(b, 12)
+		ss1 = bits.RotateLeft32(a12+f+0x3d43cec5, 7)
+		tt1 = ((b & c) | (b & d) | (c & d)) + a + (ss1 ^ a12) + (w[63] ^ w[67])
+		tt2 = ((f & g) | (^f & h)) + e + ss1 + w[63]
+		c = bits.RotateLeft32(c, 9)
+		g = bits.RotateLeft32(g, 19)
+		a = tt1
+		e = p0(tt2)
+
+		h0 ^= a
+		h1 ^= b
+		h2 ^= c
+		h3 ^= d
+		h4 ^= e
+		h5 ^= f
+		h6 ^= g
+		h7 ^= h
+
+		p = p[BlockSize:]
+	}
+	dig.h[0], dig.h[1], dig.h[2], dig.h[3], dig.h[4], dig.h[5], dig.h[6], dig.h[7] = h0, h1, h2, h3, h4, h5, h6, h7
+}
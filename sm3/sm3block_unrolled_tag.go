@@ -0,0 +1,12 @@
+//go:build sm3unrolled
+
+package sm3
+
+// block runs the SM3 compression function over every full block in p,
+// updating the chaining value in dig and counting the blocks in
+// dig.blocks. The sm3unrolled build tag selects
+// blockUnrolled; see sm3block_default.go.
+func block(dig *digest, p []byte) {
+	dig.blocks += uint64(len(p) / BlockSize)
+	blockUnrolled(dig, p)
+}
@@ -0,0 +1,47 @@
+package sm3
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// NewStream returns an endless deterministic byte stream derived from
+// seed: the concatenation of SM3(seed || ctr) for ctr = 0, 1, 2, ..., with
+// ctr a big-endian uint64. Reads of any size are served from the current
+// block, so how the stream is read does not change the bytes. The state
+// after seed is kept, so each block costs one hash of the counter.
+//
+// It is meant for reproducible test data and masking. It is not a vetted
+// stream cipher or key derivation function; use NewKDFReader or a real
+// cipher where secrecy matters. seed is not retained.
+func NewStream(seed []byte) io.Reader {
+	s := &stream{off: Size}
+	s.seeded.Reset()
+	s.seeded.Write(seed)
+	return s
+}
+
+type stream struct {
+	seeded digest // state after absorbing the seed
+	ctr    uint64
+	buf    [Size]byte
+	off    int // unread output starts at buf[off:]
+}
+
+func (s *stream) Read(p []byte) (n int, err error) {
+	for n < len(p) {
+		if s.off == Size {
+			var ct [8]byte
+			binary.BigEndian.PutUint64(ct[:], s.ctr)
+			d := s.seeded
+			d.Write(ct[:])
+			s.buf = d.checkSum()
+			s.off = 0
+			s.ctr++
+		}
+		m := copy(p[n:], s.buf[s.off:])
+		s.off += m
+		n += m
+	}
+	return n, nil
+}
@@ -0,0 +1,50 @@
+package sm3
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+)
+
+func TestStream(t *testing.T) {
+	seed := []byte("stream seed")
+	const n = 5*Size + 3
+	whole := make([]byte, n)
+	if _, err := io.ReadFull(NewStream(seed), whole); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first blocks are SM3(seed || ctr).
+	for ctr := 0; ctr < 5; ctr++ {
+		block := Sum(binary.BigEndian.AppendUint64(bytes.Clone(seed), uint64(ctr)))
+		if !bytes.Equal(whole[ctr*Size:(ctr+1)*Size], block[:]) {
+			t.Errorf("block %d = %x, want %x", ctr, whole[ctr*Size:(ctr+1)*Size], block)
+		}
+	}
+
+	// Reading in small pieces from another reader gives the same bytes.
+	r := NewStream(seed)
+	var pieces []byte
+	for _, size := range []int{1, 2, 31, 33, 0, 7, 64} {
+		p := make([]byte, min(size, n-len(pieces)))
+		if _, err := r.Read(p); err != nil {
+			t.Fatal(err)
+		}
+		pieces = append(pieces, p...)
+	}
+	for len(pieces) < n {
+		var b [1]byte
+		r.Read(b[:])
+		pieces = append(pieces, b[0])
+	}
+	if !bytes.Equal(pieces, whole) {
+		t.Errorf("piecewise reads = %x, want %x", pieces, whole)
+	}
+
+	other := make([]byte, Size)
+	NewStream([]byte("stream seeD")).Read(other)
+	if bytes.Equal(other, whole[:Size]) {
+		t.Error("different seeds gave the same stream")
+	}
+}
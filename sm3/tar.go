@@ -0,0 +1,34 @@
+package sm3
+
+import (
+	"archive/tar"
+	"io"
+)
+
+// SumTar reads the tar archive from r and returns the SM3 checksum of the
+// content of each regular file, keyed by its name in the archive.
+// Directories, links and other special entries are skipped. If a name
+// occurs more than once, the last entry wins, as it would on extraction.
+// A truncated or malformed archive yields the error from archive/tar.
+func SumTar(r io.Reader) (map[string][Size]byte, error) {
+	sums := make(map[string][Size]byte)
+	tr := tar.NewReader(r)
+	var d digest
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return sums, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		d.Reset()
+		if _, err := io.Copy(&d, tr); err != nil {
+			return nil, err
+		}
+		sums[hdr.Name] = d.checkSum()
+	}
+}
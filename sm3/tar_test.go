@@ -0,0 +1,62 @@
+package sm3
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func buildTar(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	tw := tar.NewWriter(&b)
+	for _, hdr := range []*tar.Header{
+		{Name: "dir/", Typeflag: tar.TypeDir, Mode: 0o755},
+		{Name: "dir/link", Typeflag: tar.TypeSymlink, Linkname: "a.txt"},
+	} {
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, data := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(data))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func TestSumTar(t *testing.T) {
+	files := map[string][]byte{
+		"a.txt":     []byte("hello\n"),
+		"dir/b.bin": treeTestData(3000),
+		"empty":     nil,
+	}
+	sums, err := SumTar(bytes.NewReader(buildTar(t, files)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sums) != len(files) {
+		t.Errorf("got %d entries, want %d: %v", len(sums), len(files), sums)
+	}
+	for name, data := range files {
+		if got, ok := sums[name]; !ok || got != Sum(data) {
+			t.Errorf("%s: digest %x, want %x", name, got, Sum(data))
+		}
+	}
+}
+
+func TestSumTarTruncated(t *testing.T) {
+	archive := buildTar(t, map[string][]byte{"big": treeTestData(5000)})
+	if _, err := SumTar(bytes.NewReader(archive[:2000])); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("truncated archive: err = %v, want io.ErrUnexpectedEOF", err)
+	}
+}
@@ -0,0 +1,31 @@
+package sm3
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// tempNameCounter separates unseeded TempName calls made within the same
+// clock tick.
+var tempNameCounter atomic.Uint64
+
+// TempName returns prefix followed by the first 16 hex digits of the SM3
+// checksum of seed, for temporary file names. The same seed always gives
+// the same name, which keeps build outputs reproducible.
+//
+// If seed is nil it is replaced by the current time, the process ID and a
+// per-process counter, so that concurrent and successive calls get
+// distinct names. Such names are unique, not secret; open the file with
+// O_EXCL (as os.CreateTemp does) where an attacker may pre-create paths.
+func TempName(prefix string, seed []byte) string {
+	if seed == nil {
+		seed = binary.BigEndian.AppendUint64(seed, uint64(time.Now().UnixNano()))
+		seed = binary.BigEndian.AppendUint64(seed, uint64(os.Getpid()))
+		seed = binary.BigEndian.AppendUint64(seed, tempNameCounter.Add(1))
+	}
+	sum := Sum(seed)
+	return prefix + hex.EncodeToString(sum[:8])
+}
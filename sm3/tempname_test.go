@@ -0,0 +1,36 @@
+package sm3
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTempName(t *testing.T) {
+	name := TempName("build-", []byte("seed"))
+	if want := "build-" + SumHex([]byte("seed"))[:16]; name != want {
+		t.Errorf("TempName = %q, want %q", name, want)
+	}
+	if again := TempName("build-", []byte("seed")); again != name {
+		t.Errorf("same seed gave %q and %q", name, again)
+	}
+	if other := TempName("build-", []byte("seed2")); other == name {
+		t.Errorf("different seeds both gave %q", name)
+	}
+	if got := TempName("", []byte{}); got != SumHex(nil)[:16] {
+		t.Errorf("empty seed: TempName = %q, want %q", got, SumHex(nil)[:16])
+	}
+}
+
+func TestTempNameUnseeded(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		name := TempName("tmp", nil)
+		if !strings.HasPrefix(name, "tmp") || len(name) != len("tmp")+16 {
+			t.Fatalf("TempName = %q, want tmp and 16 hex digits", name)
+		}
+		if seen[name] {
+			t.Fatalf("TempName repeated %q", name)
+		}
+		seen[name] = true
+	}
+}
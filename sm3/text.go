@@ -0,0 +1,29 @@
+package sm3
+
+import "bytes"
+
+// SumNormalizedText returns the SM3 checksum of data after normalizing it
+// as text, so that files differing only in line endings or trailing
+// whitespace get the same digest. The normalization is exactly:
+//
+//   - data is split into lines at each "\n";
+//   - from the end of every line, trailing spaces, tabs, "\r", "\v" and
+//     "\f" are removed, which also turns each "\r\n" into "\n";
+//   - the lines are joined again with "\n".
+//
+// Nothing else changes: leading and inner whitespace, blank lines, a lone
+// "\r" inside a line and whether the text ends in a newline all still
+// affect the digest, and the bytes are not interpreted as any encoding.
+func SumNormalizedText(data []byte) [Size]byte {
+	var d digest
+	d.Reset()
+	for {
+		line, rest, more := bytes.Cut(data, []byte("\n"))
+		d.Write(bytes.TrimRight(line, " \t\r\v\f"))
+		if !more {
+			return d.checkSum()
+		}
+		d.Write([]byte("\n"))
+		data = rest
+	}
+}
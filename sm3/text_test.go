@@ -0,0 +1,33 @@
+package sm3
+
+import "testing"
+
+func TestSumNormalizedText(t *testing.T) {
+	want := Sum([]byte("first line\n  indented\n\nlast\n"))
+	for _, variant := range []string{
+		"first line\n  indented\n\nlast\n",
+		"first line\r\n  indented\r\n\r\nlast\r\n",
+		"first line  \n  indented\t\n \nlast \r\n",
+		"first line\r\n  indented \t\r\n\t\r\nlast\v\f\n",
+	} {
+		if got := SumNormalizedText([]byte(variant)); got != want {
+			t.Errorf("SumNormalizedText(%q) = %x, want %x", variant, got, want)
+		}
+	}
+	for _, changed := range []string{
+		"first line\n  indented\n\nlast",     // no final newline
+		"first line\n indented\n\nlast\n",    // leading whitespace
+		"first line\n  indented\nlast\n",     // blank line removed
+		"first  line\n  indented\n\nlast\n",  // inner whitespace
+		"first line\n  indented\n\nlast\n\n", // extra blank line
+		"first line\r  indented\n\nlast\n",   // lone CR is not a line break
+		"First line\n  indented\n\nlast\n",   // content
+	} {
+		if SumNormalizedText([]byte(changed)) == want {
+			t.Errorf("SumNormalizedText(%q) matches the original", changed)
+		}
+	}
+	if SumNormalizedText(nil) != Sum(nil) || SumNormalizedText([]byte(" \t")) != Sum(nil) {
+		t.Error("empty or whitespace-only text is not the digest of the empty string")
+	}
+}
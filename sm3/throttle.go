@@ -0,0 +1,54 @@
+package sm3
+
+import (
+	"context"
+	"io"
+
+	"golang.org/x/time/rate"
+)
+
+// throttled is an io.Writer that feeds an SM3 hash no faster than its
+// limiter allows.
+type throttled struct {
+	d       digest
+	limiter *rate.Limiter
+	burst   int
+}
+
+// NewThrottledHasher returns a writer that hashes everything written to it
+// with SM3 while holding the write rate to limit bytes per second, and a
+// function returning the digest of the bytes written so far. Writes block
+// as needed; up to a tenth of a second's worth of bytes may pass without
+// waiting. A limit of rate.Inf does not throttle. The writer is not safe
+// for concurrent use.
+func NewThrottledHasher(limit rate.Limit) (io.Writer, func() [Size]byte) {
+	burst := 1
+	if limit == rate.Inf {
+		burst = 0
+	} else if b := int(limit / 10); b > 1 {
+		burst = b
+	}
+	t := &throttled{limiter: rate.NewLimiter(limit, burst), burst: burst}
+	t.d.Reset()
+	return t, func() [Size]byte {
+		d0 := t.d
+		return d0.checkSum()
+	}
+}
+
+func (t *throttled) Write(p []byte) (int, error) {
+	if t.burst == 0 {
+		return t.d.Write(p)
+	}
+	written := 0
+	for len(p) > 0 {
+		n := min(len(p), t.burst)
+		if err := t.limiter.WaitN(context.Background(), n); err != nil {
+			return written, err
+		}
+		t.d.Write(p[:n])
+		written += n
+		p = p[n:]
+	}
+	return written, nil
+}
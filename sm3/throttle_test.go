@@ -0,0 +1,47 @@
+package sm3
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestThrottledHasherDigest(t *testing.T) {
+	data := bytes.Repeat([]byte("throttle"), 1000)
+	for _, limit := range []rate.Limit{rate.Inf, 1 << 30} {
+		w, sum := NewThrottledHasher(limit)
+		for i := 0; i < len(data); i += 777 {
+			if _, err := w.Write(data[i:min(i+777, len(data))]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got, want := sum(), Sum(data); got != want {
+			t.Errorf("limit %v: digest = %x, want %x", limit, got, want)
+		}
+	}
+}
+
+func TestThrottledHasherRate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("timing test skipped in short mode")
+	}
+	const limit = 200 << 10 // bytes per second
+	data := make([]byte, 100<<10)
+	w, sum := NewThrottledHasher(limit)
+	start := time.Now()
+	if n, err := w.Write(data); n != len(data) || err != nil {
+		t.Fatalf("Write = %d, %v", n, err)
+	}
+	elapsed := time.Since(start)
+
+	// After the initial burst of limit/10 bytes the rest is paced at limit,
+	// so the write takes about 0.4s. Allow generous slack either way.
+	if elapsed < 300*time.Millisecond || elapsed > 5*time.Second {
+		t.Errorf("writing %d bytes at %d B/s took %v, want about 400ms", len(data), limit, elapsed)
+	}
+	if got, want := sum(), Sum(data); got != want {
+		t.Errorf("digest = %x, want %x", got, want)
+	}
+}
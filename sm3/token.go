@@ -0,0 +1,37 @@
+package sm3
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"sync"
+)
+
+// tokenKey is a per-process random HMAC key used by ValidateToken.
+var tokenKey = sync.OnceValue(func() []byte {
+	k := make([]byte, Size)
+	if _, err := rand.Read(k); err != nil {
+		panic("sm3: reading token comparison key: " + err.Error())
+	}
+	return k
+})
+
+// ValidateToken reports whether presented equals the expected token secret,
+// such as an API key or session cookie, in time that depends only on their
+// lengths and not on their contents or on where they first differ.
+//
+// Both values are first reduced with HMAC-SM3 under a random per-process
+// key and the two 32-byte tags are compared with
+// subtle.ConstantTimeCompare. So a length mismatch takes the same path as
+// a content mismatch, and an attacker who can time many guesses learns
+// nothing about the position of the first wrong byte, since the tags of
+// nearby guesses are unrelated. The length of presented still affects the
+// time taken, so tokens should have a fixed length; the length of secret
+// is not hidden either.
+func ValidateToken(secret, presented []byte) bool {
+	key := tokenKey()
+	a, b := NewHMACReusable(key), NewHMACReusable(key)
+	a.Write(secret)
+	b.Write(presented)
+	ta, tb := a.tag(), b.tag()
+	return subtle.ConstantTimeCompare(ta[:], tb[:]) == 1
+}
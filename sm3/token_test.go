@@ -0,0 +1,50 @@
+package sm3
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestValidateToken(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	if !ValidateToken(secret, append([]byte(nil), secret...)) {
+		t.Error("matching token rejected")
+	}
+	for _, presented := range [][]byte{
+		nil,
+		secret[:len(secret)-1],
+		append(append([]byte(nil), secret...), 'x'),
+		bytes.Repeat([]byte{'0'}, len(secret)),
+		append([]byte("X"), secret[1:]...),
+		append(append([]byte(nil), secret[:len(secret)-1]...), 'X'),
+	} {
+		if ValidateToken(secret, presented) {
+			t.Errorf("token %q accepted", presented)
+		}
+	}
+	if !ValidateToken(nil, []byte{}) {
+		t.Error("empty token does not match empty secret")
+	}
+}
+
+// TestValidateTokenTiming notes, without asserting anything, the time
+// taken by a matching token and by one that differs in its last byte. The
+// two should be close, as a plain bytes.Equal would not keep them, but
+// benchmark noise is too large to fail on.
+func TestValidateTokenTiming(t *testing.T) {
+	if testing.Short() {
+		t.Skip("timing measurement skipped in short mode")
+	}
+	secret := bytes.Repeat([]byte{'s'}, 4096)
+	mismatch := append(append([]byte(nil), secret[:len(secret)-1]...), 'x')
+	run := func(presented []byte) float64 {
+		r := testing.Benchmark(func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ValidateToken(secret, presented)
+			}
+		})
+		return float64(r.NsPerOp())
+	}
+	m, x := run(secret), run(mismatch)
+	t.Logf("match %.0f ns/op, mismatch %.0f ns/op, ratio %.2f", m, x, m/x)
+}
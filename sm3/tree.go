@@ -0,0 +1,157 @@
+package sm3
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// TreeLeafSize is the size of the leaves hashed by SumSegments. Every leaf
+// but the last is exactly this long.
+const TreeLeafSize = 64 << 10
+
+// CombineDigests returns the SM3 digest of the framed list of digests
+//
+//	0x01 || uint64(len(digests)) || digests[0] || digests[1] || ...
+//
+// with the count in big-endian. The leading byte separates it from a plain
+// SM3 of data, and the count from combinations of a different length.
+func CombineDigests(digests ...[Size]byte) [Size]byte {
+	var hdr [9]byte
+	hdr[0] = 0x01
+	binary.BigEndian.PutUint64(hdr[1:], uint64(len(digests)))
+	h := New()
+	h.Write(hdr[:])
+	for i := range digests {
+		h.Write(digests[i][:])
+	}
+	var out [Size]byte
+	h.Sum(out[:0])
+	return out
+}
+
+// SumSegments returns a tree hash of the first size bytes of r: the content
+// is cut into TreeLeafSize leaves, each leaf is hashed with SM3, and the
+// leaf digests are joined with CombineDigests. The result is not the SM3
+// digest of the content.
+//
+// The leaves are split into segments contiguous ranges that are hashed in
+// parallel. Since the leaves do not depend on segments, neither does the
+// result.
+func SumSegments(r io.ReaderAt, size int64, segments int) ([Size]byte, error) {
+	if size < 0 || segments < 1 {
+		return [Size]byte{}, fmt.Errorf("%w: size %d, segments %d", ErrBadLength, size, segments)
+	}
+	leaves := make([][Size]byte, (size+TreeLeafSize-1)/TreeLeafSize)
+	if segments > len(leaves) {
+		segments = len(leaves)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, segments)
+	for s := 0; s < segments; s++ {
+		first, last := s*len(leaves)/segments, (s+1)*len(leaves)/segments
+		wg.Add(1)
+		go func(s, first, last int) {
+			defer wg.Done()
+			errs[s] = hashLeaves(r, size, leaves, first, last)
+		}(s, first, last)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return [Size]byte{}, err
+		}
+	}
+	return CombineDigests(leaves...), nil
+}
+
+// hashLeaves fills leaves[first:last] with the digests of the corresponding
+// parts of the first size bytes of r.
+func hashLeaves(r io.ReaderAt, size int64, leaves [][Size]byte, first, last int) error {
+	h := New()
+	buf := make([]byte, 32<<10)
+	for i := first; i < last; i++ {
+		off := int64(i) * TreeLeafSize
+		n := min(TreeLeafSize, size-off)
+		h.Reset()
+		copied, err := io.CopyBuffer(h, io.NewSectionReader(r, off, n), buf)
+		if err != nil {
+			return err
+		}
+		if copied != n {
+			return io.ErrUnexpectedEOF
+		}
+		h.Sum(leaves[i][:0])
+	}
+	return nil
+}
+
+// SumSeekable returns the tree hash of SumSegments over the whole content
+// of rs, with its length found by seeking to the end. The leaves are
+// hashed by up to workers goroutines. If rs is also an io.ReaderAt, as an
+// *os.File is, they read their ranges concurrently; otherwise each worker
+// seeks rs to its range under a lock, so reads are serialized while
+// hashing still runs in parallel.
+//
+// If workers <= 1 or rs cannot seek to its end, the content from the
+// current offset is read in a single sequential pass, which yields the same
+// tree hash. Like SumSegments, the result is not the plain SM3 digest of
+// the content.
+func SumSeekable(rs io.ReadSeeker, workers int) ([Size]byte, error) {
+	size, err := rs.Seek(0, io.SeekEnd)
+	if err != nil {
+		return sumTreeReader(rs)
+	}
+	if workers <= 1 {
+		if _, err := rs.Seek(0, io.SeekStart); err != nil {
+			return [Size]byte{}, err
+		}
+		return sumTreeReader(rs)
+	}
+	ra, ok := rs.(io.ReaderAt)
+	if !ok {
+		ra = &seekReaderAt{rs: rs}
+	}
+	return SumSegments(ra, size, workers)
+}
+
+// sumTreeReader returns the tree hash of everything read from r until EOF,
+// hashing the leaves in order as they are read.
+func sumTreeReader(r io.Reader) ([Size]byte, error) {
+	var leaves [][Size]byte
+	buf := make([]byte, TreeLeafSize)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			leaves = append(leaves, Sum(buf[:n]))
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return CombineDigests(leaves...), nil
+		}
+		if err != nil {
+			return [Size]byte{}, err
+		}
+	}
+}
+
+// seekReaderAt implements io.ReaderAt over an io.ReadSeeker by seeking
+// before every read, serializing concurrent callers.
+type seekReaderAt struct {
+	mu sync.Mutex
+	rs io.ReadSeeker
+}
+
+func (s *seekReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.rs.Seek(off, io.SeekStart); err != nil {
+		return 0, err
+	}
+	n, err := io.ReadFull(s.rs, p)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return n, err
+}
@@ -0,0 +1,103 @@
+package sm3
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func treeTestData(n int) []byte {
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = byte(i*31 + i>>8)
+	}
+	return data
+}
+
+// referenceTreeHash computes the tree hash of data sequentially.
+func referenceTreeHash(data []byte) [Size]byte {
+	var leaves [][Size]byte
+	for len(data) > 0 {
+		n := min(TreeLeafSize, len(data))
+		leaves = append(leaves, Sum(data[:n]))
+		data = data[n:]
+	}
+	return CombineDigests(leaves...)
+}
+
+func TestSumSegments(t *testing.T) {
+	for _, size := range []int{0, 1, TreeLeafSize - 1, TreeLeafSize, TreeLeafSize + 1, 5*TreeLeafSize + 123} {
+		data := treeTestData(size)
+		want := referenceTreeHash(data)
+		for _, segments := range []int{1, 2, 3, 8, 100} {
+			got, err := SumSegments(bytes.NewReader(data), int64(size), segments)
+			if err != nil {
+				t.Fatalf("size %d, segments %d: %v", size, segments, err)
+			}
+			if got != want {
+				t.Errorf("size %d, segments %d: root %x, want %x", size, segments, got, want)
+			}
+		}
+	}
+}
+
+func TestSumSegmentsNotPlainSM3(t *testing.T) {
+	data := treeTestData(10)
+	got, err := SumSegments(bytes.NewReader(data), int64(len(data)), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == Sum(data) {
+		t.Error("tree hash of a single leaf equals the plain SM3 digest")
+	}
+}
+
+func TestSumSegmentsErrors(t *testing.T) {
+	data := treeTestData(100)
+	if _, err := SumSegments(bytes.NewReader(data), 200, 2); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("short reader: err = %v, want io.ErrUnexpectedEOF", err)
+	}
+	if _, err := SumSegments(bytes.NewReader(data), 100, 0); !errors.Is(err, ErrBadLength) {
+		t.Errorf("zero segments: err = %v, want ErrBadLength", err)
+	}
+}
+
+func TestCombineDigestsFraming(t *testing.T) {
+	a, b := Sum([]byte("a")), Sum([]byte("b"))
+	if CombineDigests(a, b) == CombineDigests(b, a) {
+		t.Error("CombineDigests is order-independent")
+	}
+	if CombineDigests(a) == CombineDigests(a, a) {
+		t.Error("CombineDigests does not depend on the count")
+	}
+}
+
+// onlyReadSeeker hides every method of its reader but Read and Seek, so
+// SumSeekable cannot use ReadAt.
+type onlyReadSeeker struct{ io.ReadSeeker }
+
+// unseekable fails every Seek, as a pipe does.
+type unseekable struct{ io.Reader }
+
+func (unseekable) Seek(int64, int) (int64, error) { return 0, errors.New("illegal seek") }
+
+func TestSumSeekable(t *testing.T) {
+	for _, size := range []int{0, 1, TreeLeafSize, TreeLeafSize + 1, 5*TreeLeafSize + 123} {
+		data := treeTestData(size)
+		want := referenceTreeHash(data)
+		for _, workers := range []int{0, 1, 2, 8} {
+			r := bytes.NewReader(data)
+			r.Seek(7, io.SeekStart) // SumSeekable hashes from the start regardless
+			if got, err := SumSeekable(r, workers); err != nil || got != want {
+				t.Errorf("size %d, %d workers: root %x, %v; want %x", size, workers, got, err, want)
+			}
+			if got, err := SumSeekable(onlyReadSeeker{bytes.NewReader(data)}, workers); err != nil || got != want {
+				t.Errorf("size %d, %d workers, no ReadAt: root %x, %v; want %x", size, workers, got, err, want)
+			}
+		}
+		if got, err := SumSeekable(unseekable{bytes.NewReader(data)}, 4); err != nil || got != want {
+			t.Errorf("size %d, unseekable: root %x, %v; want %x", size, got, err, want)
+		}
+	}
+}
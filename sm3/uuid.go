@@ -0,0 +1,25 @@
+package sm3
+
+// DeterministicID returns a name-based UUID for name within namespace,
+// built like an RFC 4122 version 5 UUID but with SM3 in place of SHA-1:
+// the first 16 bytes of SM3(namespace || name), with the version and
+// variant bits overwritten. The same namespace and name always give the
+// same ID.
+//
+// RFC 4122 ties version 5 to SHA-1, so these IDs are labelled version 8,
+// which RFC 9562 reserves for custom layouts, rather than claiming to be
+// version 5 UUIDs they would not match. The variant is the RFC one (binary
+// 10), leaving 122 bits from the hash.
+func DeterministicID(namespace [16]byte, name []byte) [16]byte {
+	d := New()
+	d.Write(namespace[:])
+	d.Write(name)
+	var sum [Size]byte
+	d.Sum(sum[:0])
+
+	var id [16]byte
+	copy(id[:], sum[:16])
+	id[6] = id[6]&0x0f | 0x80 // version 8
+	id[8] = id[8]&0x3f | 0x80 // RFC 4122 variant
+	return id
+}
@@ -0,0 +1,40 @@
+package sm3
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDeterministicID(t *testing.T) {
+	// The DNS namespace of RFC 4122 appendix C.
+	dns := [16]byte{0x6b, 0xa7, 0xb8, 0x10, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8}
+	url := dns
+	url[3]++
+	name := []byte("www.example.com")
+
+	id := DeterministicID(dns, name)
+	if again := DeterministicID(dns, name); again != id {
+		t.Errorf("DeterministicID is not deterministic: %x, %x", id, again)
+	}
+	if v := id[6] >> 4; v != 8 {
+		t.Errorf("version = %d, want 8", v)
+	}
+	if id[8]>>6 != 0b10 {
+		t.Errorf("variant bits = %02b, want 10", id[8]>>6)
+	}
+
+	// Outside the version and variant bits, the ID is the hash prefix.
+	sum := Sum(append(dns[:], name...))
+	id[6], sum[6] = id[6]&0x0f, sum[6]&0x0f
+	id[8], sum[8] = id[8]&0x3f, sum[8]&0x3f
+	if !bytes.Equal(id[:], sum[:16]) {
+		t.Errorf("ID = %x, want hash prefix %x", id, sum[:16])
+	}
+
+	if DeterministicID(url, name) == DeterministicID(dns, name) {
+		t.Error("changing the namespace did not change the ID")
+	}
+	if DeterministicID(dns, []byte("www.example.org")) == DeterministicID(dns, name) {
+		t.Error("changing the name did not change the ID")
+	}
+}
@@ -0,0 +1,109 @@
+package sm3
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+// vector is one entry of testdata/vectors.json. Byte strings are hex; which
+// fields are set depends on Type.
+type vector struct {
+	Name   string   `json:"name"`
+	Type   string   `json:"type"`
+	Msg    string   `json:"msg"`
+	Repeat int      `json:"repeat"` // sm3: Msg repeated this many times
+	Key    string   `json:"key"`
+	Z      string   `json:"z"`
+	IKM    string   `json:"ikm"`
+	Salt   *string  `json:"salt"` // hkdf-sm3: absent for a nil salt
+	Info   string   `json:"info"`
+	Len    int      `json:"len"`
+	Leaves []string `json:"leaves"`
+	Out    string   `json:"out"`
+}
+
+// vectorFuncs computes the output of each vector type.
+var vectorFuncs = map[string]func(t *testing.T, v *vector) []byte{
+	"sm3": func(t *testing.T, v *vector) []byte {
+		msg := unhex(t, v.Msg)
+		if v.Repeat > 0 {
+			msg = bytes.Repeat(msg, v.Repeat)
+		}
+		sum := Sum(msg)
+		return sum[:]
+	},
+	"hmac-sm3": func(t *testing.T, v *vector) []byte {
+		m := NewHMAC(unhex(t, v.Key))
+		m.Write(unhex(t, v.Msg))
+		return m.Sum(nil)
+	},
+	"kdf": func(t *testing.T, v *vector) []byte {
+		return KDF(unhex(t, v.Z), v.Len)
+	},
+	"hkdf-sm3": func(t *testing.T, v *vector) []byte {
+		var salt []byte
+		if v.Salt != nil {
+			salt = unhex(t, *v.Salt)
+		}
+		out, err := HKDF(unhex(t, v.IKM), salt, unhex(t, v.Info), v.Len)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out
+	},
+	"merkle-root": func(t *testing.T, v *vector) []byte {
+		leaves := make([][Size]byte, len(v.Leaves))
+		for i, l := range v.Leaves {
+			leaves[i] = MerkleLeafHash(unhex(t, l))
+		}
+		root := MerkleRoot(leaves)
+		return root[:]
+	},
+}
+
+func unhex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("bad hex %q: %v", s, err)
+	}
+	return b
+}
+
+// TestVectors runs every known-answer vector in testdata/vectors.json
+// through the function for its type. To add a construction, add its
+// vectors to the file and an entry to vectorFuncs.
+func TestVectors(t *testing.T) {
+	data, err := os.ReadFile("testdata/vectors.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file struct {
+		Vectors []vector `json:"vectors"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		t.Fatalf("testdata/vectors.json: %v", err)
+	}
+	seen := make(map[string]int)
+	for i := range file.Vectors {
+		v := &file.Vectors[i]
+		seen[v.Type]++
+		t.Run(v.Name, func(t *testing.T) {
+			f, ok := vectorFuncs[v.Type]
+			if !ok {
+				t.Fatalf("vector %q has unknown type %q", v.Name, v.Type)
+			}
+			if got := hex.EncodeToString(f(t, v)); got != v.Out {
+				t.Errorf("vector %q: got %s, want %s", v.Name, got, v.Out)
+			}
+		})
+	}
+	for typ := range vectorFuncs {
+		if seen[typ] == 0 {
+			t.Errorf("no vectors of type %q", typ)
+		}
+	}
+}
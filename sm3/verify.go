@@ -0,0 +1,19 @@
+package sm3
+
+import "io"
+
+// VerifyReader reads r to EOF and reports whether the SM3 checksum of its
+// contents is expected, comparing in constant time. It suits download
+// bodies: passing an http.Response's Body drains it, so once the caller
+// closes it the connection can be reused, and nothing is buffered beyond
+// one read chunk.
+//
+// A read error, including io.ErrUnexpectedEOF for a body cut short of its
+// Content-Length, is returned with a false result.
+func VerifyReader(r io.Reader, expected [Size]byte) (bool, error) {
+	sum, err := SumReader(r)
+	if err != nil {
+		return false, err
+	}
+	return Equal(sum, expected), nil
+}
@@ -0,0 +1,51 @@
+package sm3
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestVerifyReaderDownload(t *testing.T) {
+	content := treeTestData(100000)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/truncated" {
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)+10))
+		}
+		w.Write(content)
+	}))
+	defer srv.Close()
+
+	get := func(path string) io.ReadCloser {
+		t.Helper()
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.Body
+	}
+
+	want := Sum(content)
+	wrong := want
+	wrong[0] ^= 1
+	for _, tt := range []struct {
+		expected [Size]byte
+		ok       bool
+	}{{want, true}, {wrong, false}} {
+		body := get("/")
+		ok, err := VerifyReader(body, tt.expected)
+		body.Close()
+		if ok != tt.ok || err != nil {
+			t.Errorf("VerifyReader(%x) = %v, %v, want %v, nil", tt.expected[:4], ok, err, tt.ok)
+		}
+	}
+
+	body := get("/truncated")
+	defer body.Close()
+	if ok, err := VerifyReader(body, want); ok || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("truncated body: VerifyReader = %v, %v, want false, %v", ok, err, io.ErrUnexpectedEOF)
+	}
+}
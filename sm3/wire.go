@@ -0,0 +1,25 @@
+package sm3
+
+import "io"
+
+// WriteDigest writes the Size raw bytes of d to w, for protocols that frame
+// a fixed-length SM3 digest on the wire.
+func WriteDigest(w io.Writer, d [Size]byte) (int, error) {
+	return w.Write(d[:])
+}
+
+// ReadDigest reads exactly Size bytes from r as a digest. It returns io.EOF
+// if r is empty and io.ErrUnexpectedEOF if it ends part way through.
+func ReadDigest(r io.Reader) ([Size]byte, error) {
+	var d [Size]byte
+	if _, err := io.ReadFull(r, d[:]); err != nil {
+		return [Size]byte{}, err
+	}
+	return d, nil
+}
+
+// SumTo writes the SM3 checksum of data to w, returning the number of
+// bytes written and any error from w.
+func SumTo(w io.Writer, data []byte) (int, error) {
+	return WriteDigest(w, Sum(data))
+}
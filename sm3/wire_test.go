@@ -0,0 +1,63 @@
+package sm3
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestReadWriteDigest(t *testing.T) {
+	var buf bytes.Buffer
+	a, b := Sum([]byte("a")), Sum([]byte("b"))
+	for _, d := range [][Size]byte{a, b} {
+		if n, err := WriteDigest(&buf, d); n != Size || err != nil {
+			t.Fatalf("WriteDigest = %d, %v", n, err)
+		}
+	}
+	for _, want := range [][Size]byte{a, b} {
+		got, err := ReadDigest(&buf)
+		if err != nil || got != want {
+			t.Errorf("ReadDigest = %x, %v, want %x", got, err, want)
+		}
+	}
+	if _, err := ReadDigest(&buf); err != io.EOF {
+		t.Errorf("empty stream: err = %v, want %v", err, io.EOF)
+	}
+	if _, err := ReadDigest(bytes.NewReader(a[:Size-1])); err != io.ErrUnexpectedEOF {
+		t.Errorf("short stream: err = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
+
+// failWriter accepts up to n bytes and then fails with err.
+type failWriter struct {
+	n   int
+	err error
+}
+
+func (w *failWriter) Write(p []byte) (int, error) {
+	if len(p) > w.n {
+		n := w.n
+		w.n = 0
+		return n, w.err
+	}
+	w.n -= len(p)
+	return len(p), nil
+}
+
+func TestSumTo(t *testing.T) {
+	var buf bytes.Buffer
+	buf.WriteString("frame:")
+	if n, err := SumTo(&buf, []byte("abc")); n != Size || err != nil {
+		t.Fatalf("SumTo = %d, %v", n, err)
+	}
+	want := Sum([]byte("abc"))
+	if got := buf.Bytes(); !bytes.Equal(got, append([]byte("frame:"), want[:]...)) {
+		t.Errorf("buffer = %x, want frame: followed by %x", got, want)
+	}
+
+	writeErr := errors.New("disk full")
+	if n, err := SumTo(&failWriter{n: 10, err: writeErr}, []byte("abc")); n != 10 || err != writeErr {
+		t.Errorf("failing writer: SumTo = %d, %v; want 10, %v", n, err, writeErr)
+	}
+}
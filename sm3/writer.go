@@ -0,0 +1,33 @@
+package sm3
+
+import "io"
+
+type hashWriteCloser struct {
+	wc io.WriteCloser
+	d  digest
+}
+
+func (w *hashWriteCloser) Write(p []byte) (int, error) {
+	n, err := w.wc.Write(p)
+	w.d.Write(p[:n])
+	return n, err
+}
+
+func (w *hashWriteCloser) Close() error {
+	return w.wc.Close()
+}
+
+// NewWriteCloser returns a WriteCloser that forwards writes to wc and hashes
+// the bytes wc accepted, and a function returning their SM3 digest. Close
+// closes wc and returns its error.
+//
+// The digest function may be called at any time, before or after Close,
+// and reflects the bytes written so far.
+func NewWriteCloser(wc io.WriteCloser) (io.WriteCloser, func() [Size]byte) {
+	w := &hashWriteCloser{wc: wc}
+	w.d.Reset()
+	return w, func() [Size]byte {
+		d0 := w.d
+		return d0.checkSum()
+	}
+}
@@ -0,0 +1,74 @@
+package sm3
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewWriteCloserFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "upload")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, sum := NewWriteCloser(f)
+
+	data := bytes.Repeat([]byte("upload and hash "), 100)
+	if _, err := w.Write(data[:300]); err != nil {
+		t.Fatal(err)
+	}
+	if got := sum(); got != Sum(data[:300]) {
+		t.Errorf("digest before Close = %x, want digest of the first 300 bytes", got)
+	}
+	if _, err := w.Write(data[300:]); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	onDisk, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(onDisk, data) {
+		t.Error("file content differs from the written data")
+	}
+	if got := sum(); got != Sum(onDisk) {
+		t.Errorf("digest after Close = %x, want %x", got, Sum(onDisk))
+	}
+
+	// Closing twice surfaces the file's error.
+	if err := w.Close(); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("second Close: err = %v, want os.ErrClosed", err)
+	}
+}
+
+type shortWriteCloser struct {
+	bytes.Buffer
+	limit int
+}
+
+func (s *shortWriteCloser) Write(p []byte) (int, error) {
+	if len(p) > s.limit {
+		s.Buffer.Write(p[:s.limit])
+		return s.limit, errors.New("short write")
+	}
+	return s.Buffer.Write(p)
+}
+
+func (s *shortWriteCloser) Close() error { return nil }
+
+func TestNewWriteCloserShortWrite(t *testing.T) {
+	sink := &shortWriteCloser{limit: 4}
+	w, sum := NewWriteCloser(sink)
+	if n, err := w.Write([]byte("0123456789")); n != 4 || err == nil {
+		t.Fatalf("Write = %d, %v", n, err)
+	}
+	if got := sum(); got != Sum(sink.Bytes()) {
+		t.Error("digest covers bytes the sink did not accept")
+	}
+}
@@ -0,0 +1,27 @@
+package sm3
+
+// XOR returns the byte-wise XOR of s and other.
+func (s Sum256) XOR(other Sum256) Sum256 {
+	for i := range s {
+		s[i] ^= other[i]
+	}
+	return s
+}
+
+// XORDigests returns a.XOR(b).
+func XORDigests(a, b Sum256) Sum256 { return a.XOR(b) }
+
+// XORAll returns the byte-wise XOR of digests, or all zeros for none.
+//
+// Unlike CombineDigests the result is independent of order and of how the
+// digests are grouped, and any digest present an even number of times
+// cancels out. That suits set sketches that add or remove members in any
+// order, but it binds neither order nor multiplicity, and it is not
+// collision resistant: XORAll is not a hash of the list.
+func XORAll(digests ...Sum256) Sum256 {
+	var out Sum256
+	for _, d := range digests {
+		out = out.XOR(d)
+	}
+	return out
+}
@@ -0,0 +1,31 @@
+package sm3
+
+import "testing"
+
+func TestXORDigests(t *testing.T) {
+	a, b, c := Sum([]byte("a")), Sum([]byte("b")), Sum([]byte("c"))
+	if XORDigests(a, b) != XORDigests(b, a) {
+		t.Error("XORDigests is not commutative")
+	}
+	if XORDigests(a, a) != ([Size]byte{}) {
+		t.Error("a digest XORed with itself is not zero")
+	}
+	if XORDigests(a, [Size]byte{}) != a {
+		t.Error("XOR with zero changed the digest")
+	}
+	for _, perm := range [][]Sum256{{a, b, c}, {c, a, b}, {b, c, a}} {
+		if got, want := XORAll(perm...), XORDigests(XORDigests(a, b), c); got != want {
+			t.Errorf("XORAll in another order = %x, want %x", got, want)
+		}
+	}
+	if XORAll() != ([Size]byte{}) {
+		t.Error("XORAll() is not zero")
+	}
+	if XORAll(a, b, a) != b {
+		t.Error("a repeated digest did not cancel")
+	}
+	sa := Sum256(a)
+	if sa.XOR(b) != XORDigests(a, b) || sa.XOR(b).XOR(b) != sa {
+		t.Error("Sum256.XOR does not match XORDigests or does not undo itself")
+	}
+}
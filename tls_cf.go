@@ -45,16 +45,21 @@ var supportedSignatureAlgorithmsWithCircl []SignatureScheme
 
 // supportedSignatureAlgorithms returns enabled signature schemes. PQ signature
 // schemes are only included when tls.Config#PQSignatureSchemesEnabled is set
-// and FIPS-only mode is not enabled.
+// and FIPS-only mode is not enabled. [UTLS] sm2sig_sm3 is appended when
+// tls.Config#SM2RootCAs is set.
 func (c *Config) supportedSignatureAlgorithms() []SignatureScheme {
 	// If FIPS-only mode is requested, do not add other algos.
 	if needFIPS() {
 		return supportedSignatureAlgorithms()
 	}
+	algs := defaultSupportedSignatureAlgorithms
 	if c != nil && c.PQSignatureSchemesEnabled {
-		return supportedSignatureAlgorithmsWithCircl
+		algs = supportedSignatureAlgorithmsWithCircl
 	}
-	return defaultSupportedSignatureAlgorithms
+	if c != nil && c.SM2RootCAs != nil {
+		algs = append(algs[:len(algs):len(algs)], SM2WithSM3) // [UTLS]
+	}
+	return algs
 }
 
 func init() {
@@ -868,6 +868,8 @@ func TestCloneNonFuncFields(t *testing.T) {
 			f.Set(reflect.ValueOf([]CurveID{CurveP256}))
 		case "PQSignatureSchemesEnabled": // [UTLS] ported from cloudflare/go
 			f.Set(reflect.ValueOf(true))
+		case "SM2RootCAs": // [UTLS]
+			f.Set(reflect.ValueOf([][]byte{{'r'}}))
 		case "Renegotiation":
 			f.Set(reflect.ValueOf(RenegotiateOnceAsClient))
 		case "mutex", "autoSessionTicketKeys", "sessionTicketKeys":
@@ -0,0 +1,196 @@
+package tls
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/refraction-networking/utls/sm3"
+	"golang.org/x/crypto/cryptobyte"
+	cryptobyte_asn1 "golang.org/x/crypto/cryptobyte/asn1"
+)
+
+// SM2WithSM3 is the sm2sig_sm3 signature scheme of RFC 8998. It is only
+// defined for TLS 1.3, and is offered when Config.SM2RootCAs is set.
+const SM2WithSM3 SignatureScheme = 0x0708
+
+// sm2TLSUID is the SM2 signer identity RFC 8998, section 3.2.1, fixes for
+// TLS 1.3 CertificateVerify signatures.
+var sm2TLSUID = []byte("TLSv1.3+GM+Cipher+Suite")
+
+var sm2TLSSignerOpts = &sm3.SM2SignerOpts{UID: sm2TLSUID}
+
+var oidSignatureSM2WithSM3 = asn1.ObjectIdentifier{1, 2, 156, 10197, 1, 501}
+
+// VerifySM2CertificateChain verifies a GM (ShangMi) peer certificate
+// chain, rawCerts as received in the Certificate message, leaf first,
+// against roots, DER-encoded trusted SM2 CA certificates. Every signature
+// must be SM2-with-SM3 and verify under the issuer's SM2 key, and every
+// issuer must be a CA allowed to sign, see sm3.VerifyChainSM2.
+//
+// Validity periods, extended key usage and host names are not checked.
+// The handshake checks those too when Config.SM2RootCAs is set; this
+// function is for callers checking GM chains by hand, for example from
+// VerifyPeerCertificate.
+func VerifySM2CertificateChain(rawCerts, roots [][]byte) error {
+	if len(rawCerts) == 0 {
+		return errors.New("tls: no SM2 certificates to verify")
+	}
+	if err := sm3.VerifyChainSM2(rawCerts, roots); err != nil {
+		return fmt.Errorf("tls: failed to verify SM2 certificate chain: %w", err)
+	}
+	return nil
+}
+
+// isSM2Certificate reports whether the DER certificate der is signed with
+// SM2-with-SM3.
+func isSM2Certificate(der []byte) bool {
+	var (
+		input      = cryptobyte.String(der)
+		cert, algo cryptobyte.String
+		oid        asn1.ObjectIdentifier
+	)
+	return input.ReadASN1(&cert, cryptobyte_asn1.SEQUENCE) &&
+		cert.SkipASN1(cryptobyte_asn1.SEQUENCE) &&
+		cert.ReadASN1(&algo, cryptobyte_asn1.SEQUENCE) &&
+		algo.ReadASN1ObjectIdentifier(&oid) &&
+		oid.Equal(oidSignatureSM2WithSM3)
+}
+
+// placeholderSPKI is a P-256 SubjectPublicKeyInfo that parseSM2Certificate
+// puts in place of an SM2 one for crypto/x509 to parse the rest.
+var placeholderSPKI = sync.OnceValue(func() []byte {
+	p256 := elliptic.P256().Params()
+	spki, err := x509.MarshalPKIXPublicKey(&ecdsa.PublicKey{Curve: elliptic.P256(), X: p256.Gx, Y: p256.Gy})
+	if err != nil {
+		panic("tls: " + err.Error())
+	}
+	return spki
+})
+
+// parseSM2Certificate parses a DER certificate that may carry an SM2
+// public key, which crypto/x509 rejects. The key is swapped for a
+// placeholder while crypto/x509 parses the certificate, and PublicKey is
+// then set to the *sm3.SM2PublicKey, with PublicKeyAlgorithm
+// x509.UnknownPublicKeyAlgorithm. Raw and the other raw fields are those
+// of der. The signature is not checked.
+func parseSM2Certificate(der []byte) (*x509.Certificate, error) {
+	var (
+		input                      = cryptobyte.String(der)
+		cert, rawTBS, tbs          cryptobyte.String
+		outerAlgo, sig             cryptobyte.String
+		version, serial, innerAlgo cryptobyte.String
+		issuer, validity, subject  cryptobyte.String
+		spki                       cryptobyte.String
+		versionTag                 = cryptobyte_asn1.Tag(0).Constructed().ContextSpecific()
+	)
+	if !input.ReadASN1(&cert, cryptobyte_asn1.SEQUENCE) || !input.Empty() ||
+		!cert.ReadASN1Element(&rawTBS, cryptobyte_asn1.SEQUENCE) ||
+		!cert.ReadASN1Element(&outerAlgo, cryptobyte_asn1.SEQUENCE) ||
+		!cert.ReadASN1Element(&sig, cryptobyte_asn1.BIT_STRING) || !cert.Empty() {
+		return nil, errors.New("x509: malformed certificate")
+	}
+	if tbsBody := rawTBS; !tbsBody.ReadASN1(&tbs, cryptobyte_asn1.SEQUENCE) ||
+		(tbs.PeekASN1Tag(versionTag) && !tbs.ReadASN1Element(&version, versionTag)) ||
+		!tbs.ReadASN1Element(&serial, cryptobyte_asn1.INTEGER) ||
+		!tbs.ReadASN1Element(&innerAlgo, cryptobyte_asn1.SEQUENCE) ||
+		!tbs.ReadASN1Element(&issuer, cryptobyte_asn1.SEQUENCE) ||
+		!tbs.ReadASN1Element(&validity, cryptobyte_asn1.SEQUENCE) ||
+		!tbs.ReadASN1Element(&subject, cryptobyte_asn1.SEQUENCE) ||
+		!tbs.ReadASN1Element(&spki, cryptobyte_asn1.SEQUENCE) {
+		return nil, errors.New("x509: malformed tbs certificate")
+	}
+	pub, err := sm3.ParseSM2PublicKeyInfo(spki)
+	if err != nil {
+		return x509.ParseCertificate(der)
+	}
+
+	var b cryptobyte.Builder
+	b.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		b.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+			b.AddBytes(version)
+			b.AddBytes(serial)
+			b.AddBytes(innerAlgo)
+			b.AddBytes(issuer)
+			b.AddBytes(validity)
+			b.AddBytes(subject)
+			b.AddBytes(placeholderSPKI())
+			b.AddBytes(tbs) // issuer and subject unique IDs, extensions
+		})
+		b.AddBytes(outerAlgo)
+		b.AddBytes(sig)
+	})
+	c, err := x509.ParseCertificate(b.BytesOrPanic())
+	if err != nil {
+		return nil, err
+	}
+	c.Raw = der
+	c.RawTBSCertificate = rawTBS
+	c.RawSubjectPublicKeyInfo = spki
+	c.RawIssuer = issuer
+	c.RawSubject = subject
+	c.PublicKeyAlgorithm = x509.UnknownPublicKeyAlgorithm
+	c.PublicKey = pub
+	return c, nil
+}
+
+// verifySM2Chain verifies the GM chain certs, leaf first, against roots
+// as Config.SM2RootCAs describes: signatures, issuer names and the CA
+// constraints of issuers with sm3.VerifyChainSM2, then the validity period of every certificate at
+// now, the leaf's host name against dnsName unless it is empty, and the
+// leaf's extended key usage against usage if it has any, as
+// x509.Certificate.Verify would. It returns the verified chain, ending at
+// the trusted root.
+func verifySM2Chain(certs []*x509.Certificate, roots [][]byte, now time.Time, dnsName string, usage x509.ExtKeyUsage) ([][]*x509.Certificate, error) {
+	raw := make([][]byte, len(certs))
+	for i, cert := range certs {
+		raw[i] = cert.Raw
+	}
+	if err := VerifySM2CertificateChain(raw, roots); err != nil {
+		return nil, err
+	}
+
+	chain := certs
+	last := certs[len(certs)-1]
+	for _, der := range roots {
+		if bytes.Equal(der, last.Raw) {
+			break
+		}
+		if root, err := parseSM2Certificate(der); err == nil && bytes.Equal(root.RawSubject, last.RawIssuer) &&
+			sm3.VerifyCertSM2(last.Raw, der) == nil {
+			chain = append(chain[:len(chain):len(chain)], root)
+			break
+		}
+	}
+
+	for _, cert := range chain {
+		if now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+			return nil, x509.CertificateInvalidError{
+				Cert:   cert,
+				Reason: x509.Expired,
+				Detail: fmt.Sprintf("current time %s is outside [%s, %s]", now.Format(time.RFC3339), cert.NotBefore.Format(time.RFC3339), cert.NotAfter.Format(time.RFC3339)),
+			}
+		}
+	}
+	if dnsName != "" {
+		if err := certs[0].VerifyHostname(dnsName); err != nil {
+			return nil, err
+		}
+	}
+	if ku := certs[0].ExtKeyUsage; len(ku) > 0 {
+		ok := false
+		for _, u := range ku {
+			ok = ok || u == usage || u == x509.ExtKeyUsageAny
+		}
+		if !ok {
+			return nil, x509.CertificateInvalidError{Cert: certs[0], Reason: x509.IncompatibleUsage}
+		}
+	}
+	return [][]*x509.Certificate{chain}, nil
+}
@@ -0,0 +1,207 @@
+package tls
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/refraction-networking/utls/sm3"
+)
+
+// sm2TestChain returns the GM test chain in sm3/testdata, whose leaf is
+// for leaf.example.
+func sm2TestChain(t *testing.T) (root, intermediate, leaf []byte) {
+	data, err := os.ReadFile("sm3/testdata/sm2_chain.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var certs [][]byte
+	for {
+		var block *pem.Block
+		if block, data = pem.Decode(data); block == nil {
+			break
+		}
+		certs = append(certs, block.Bytes)
+	}
+	if len(certs) != 3 {
+		t.Fatalf("got %d certificates, want root, intermediate and leaf", len(certs))
+	}
+	return certs[0], certs[1], certs[2]
+}
+
+// sm2TestCertificate returns the leaf and intermediate of sm2TestChain with
+// the leaf's SM2 private key.
+func sm2TestCertificate(t *testing.T) Certificate {
+	_, intermediate, leaf := sm2TestChain(t)
+	return Certificate{Certificate: [][]byte{leaf, intermediate}, PrivateKey: sm2TestKey(t, "sm3/testdata/sm2_leaf.key")}
+}
+
+// sm2TestLeafIssuedCertificate returns the evil.example certificate that
+// the leaf of sm2TestChain signed, alone, with its SM2 private key.
+func sm2TestLeafIssuedCertificate(t *testing.T) Certificate {
+	data, err := os.ReadFile("sm3/testdata/sm2_leaf_issued.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		t.Fatal("no PEM block in sm2_leaf_issued.pem")
+	}
+	return Certificate{Certificate: [][]byte{block.Bytes}, PrivateKey: sm2TestKey(t, "sm3/testdata/sm2_leaf_issued.key")}
+}
+
+func sm2TestKey(t *testing.T, name string) *sm3.SM2PrivateKey {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		t.Fatalf("no PEM block in %s", name)
+	}
+	key, err := sm3.ParseSM2PrivateKey(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestVerifySM2CertificateChain(t *testing.T) {
+	root, intermediate, leaf := sm2TestChain(t)
+
+	if err := VerifySM2CertificateChain([][]byte{leaf, intermediate}, [][]byte{root}); err != nil {
+		t.Errorf("valid chain: %v", err)
+	}
+
+	altered := bytes.Clone(leaf)
+	altered[bytes.Index(altered, []byte("leaf.example"))] = 'L'
+	err := VerifySM2CertificateChain([][]byte{altered, intermediate}, [][]byte{root})
+	if !errors.Is(err, sm3.ErrInvalidSignature) {
+		t.Errorf("altered leaf: err = %v, want %v", err, sm3.ErrInvalidSignature)
+	}
+
+	if err := VerifySM2CertificateChain(nil, [][]byte{root}); err == nil {
+		t.Error("empty chain verified")
+	}
+}
+
+func TestSM2Handshake(t *testing.T) {
+	root, intermediate, _ := sm2TestChain(t)
+	cert := sm2TestCertificate(t)
+	now := func() time.Time { return time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+	clientConfig := &Config{
+		ServerName: "leaf.example",
+		SM2RootCAs: [][]byte{root},
+		MinVersion: VersionTLS13,
+		Time:       now,
+	}
+	serverConfig := &Config{
+		Certificates: []Certificate{cert},
+		Time:         now,
+	}
+	_, cs, err := testHandshake(t, clientConfig, serverConfig)
+	if err != nil {
+		t.Fatalf("handshake failed: %v", err)
+	}
+	if _, ok := cs.PeerCertificates[0].PublicKey.(*sm3.SM2PublicKey); !ok {
+		t.Errorf("peer public key is %T, want *sm3.SM2PublicKey", cs.PeerCertificates[0].PublicKey)
+	}
+	if len(cs.VerifiedChains) != 1 || len(cs.VerifiedChains[0]) != 3 || !bytes.Equal(cs.VerifiedChains[0][2].Raw, root) {
+		t.Errorf("verified chains do not end at the SM2 root: %v", cs.VerifiedChains)
+	}
+
+	for name, modify := range map[string]func(c *Config){
+		"wrong name":     func(c *Config) { c.ServerName = "other.example" },
+		"untrusted root": func(c *Config) { c.SM2RootCAs = [][]byte{intermediate[:len(intermediate)-1]} },
+		"no SM2 roots":   func(c *Config) { c.SM2RootCAs = nil },
+		"expired": func(c *Config) {
+			c.Time = func() time.Time { return time.Date(2200, 1, 1, 0, 0, 0, 0, time.UTC) }
+		},
+	} {
+		c := clientConfig.Clone()
+		modify(c)
+		if _, _, err := testHandshake(t, c, serverConfig.Clone()); err == nil {
+			t.Errorf("%s: handshake succeeded", name)
+		}
+	}
+
+	// A leaf is not a CA, so a certificate it signed must not verify even
+	// though every signature in the chain does.
+	_, intermediate, leaf := sm2TestChain(t)
+	issued := sm2TestLeafIssuedCertificate(t)
+	issued.Certificate = append(issued.Certificate, leaf, intermediate)
+	c := clientConfig.Clone()
+	c.ServerName = "evil.example"
+	s := serverConfig.Clone()
+	s.Certificates = []Certificate{issued}
+	if _, _, err := testHandshake(t, c, s); err == nil {
+		t.Error("leaf as intermediate: handshake succeeded")
+	}
+	var certs []*x509.Certificate
+	for _, der := range issued.Certificate {
+		cert, err := parseSM2Certificate(der)
+		if err != nil {
+			t.Fatal(err)
+		}
+		certs = append(certs, cert)
+	}
+	if _, err := verifySM2Chain(certs, c.SM2RootCAs, now(), "evil.example", x509.ExtKeyUsageServerAuth); !errors.Is(err, sm3.ErrNotAuthorizedToSign) {
+		t.Errorf("leaf as intermediate: err = %v, want %v", err, sm3.ErrNotAuthorizedToSign)
+	}
+
+	// TLS 1.2 has no SM2 signature scheme.
+	c = clientConfig.Clone()
+	c.MinVersion, c.MaxVersion = VersionTLS12, VersionTLS12
+	if _, _, err := testHandshake(t, c, serverConfig.Clone()); err == nil {
+		t.Error("TLS 1.2: handshake succeeded")
+	}
+}
+
+func TestSM2ClientAuth(t *testing.T) {
+	root, _, _ := sm2TestChain(t)
+	now := func() time.Time { return time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+	clientConfig := testConfig.Clone()
+	clientConfig.Certificates = []Certificate{sm2TestCertificate(t)}
+	clientConfig.MinVersion = VersionTLS13
+	clientConfig.Rand = nil // SM2 nonces are rejection sampled, zeroSource never yields one
+	serverConfig := testConfig.Clone()
+	serverConfig.ClientAuth = RequireAndVerifyClientCert
+	serverConfig.SM2RootCAs = [][]byte{root}
+	serverConfig.Time = now
+
+	ss, _, err := testHandshake(t, clientConfig, serverConfig)
+	if err != nil {
+		t.Fatalf("handshake failed: %v", err)
+	}
+	if len(ss.PeerCertificates) != 2 || ss.PeerCertificates[0].Subject.CommonName != "leaf.example" {
+		t.Errorf("server saw peer certificates %v", ss.PeerCertificates)
+	}
+	if len(ss.VerifiedChains) != 1 {
+		t.Errorf("server verified %d chains, want 1", len(ss.VerifiedChains))
+	}
+
+	// Without SM2RootCAs the server does not offer sm2sig_sm3, so the
+	// client has no acceptable certificate to send. testHandshake cannot
+	// be used, as the TLS 1.3 client only learns of the failure on Read.
+	serverConfig.SM2RootCAs = nil
+	c, s := localPipe(t)
+	go func() {
+		cli := Client(c, clientConfig)
+		if cli.Handshake() == nil {
+			io.Copy(io.Discard, cli)
+		}
+		c.Close()
+	}()
+	err = Server(s, serverConfig).Handshake()
+	s.Close()
+	if err == nil {
+		t.Error("client certificate accepted without SM2RootCAs")
+	}
+}